@@ -0,0 +1,6 @@
+package openapi
+
+import _ "embed"
+
+//go:embed openapi.json
+var Spec []byte
@@ -3,34 +3,139 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"solid/internal/domain"
+	"solid/internal/event"
+	"solid/internal/gql"
 	"solid/internal/handler"
 	"solid/internal/middleware"
+	"solid/internal/outbox"
 	"solid/internal/repository"
+	"solid/internal/router"
+	"solid/internal/rpc"
 	"solid/internal/service"
+	"solid/internal/stream"
+	"solid/internal/webhook"
 
-	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+)
+
+// Unversioned paths such as /books keep working until the sunset date;
+// clients should move to /v1.
+var (
+	unversionedDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	unversionedSunset       = unversionedDeprecatedAt.AddDate(0, 6, 0)
 )
 
 func main() {
-	bookRepository := repository.NewInMemoryBookRepository()
-	bookService := service.NewBookService(bookRepository)
+	bookRepository, closeBookRepository := openBookRepository()
+	eventBus := event.NewBus()
+	bookStore, cacheStats := cachedBookRepository(bookRepository)
+	bookService := service.NewBookService(
+		bookStore,
+		service.WithHistory(bookRepository),
+		service.WithPublisher(eventBus),
+		service.WithChangeLog(bookRepository),
+	)
+	// Suggestions stay empty until the index is built, which takes seconds
+	// for large catalogues; the server does not wait for it.
+	go func() {
+		if err := bookService.RebuildSuggestions(context.Background()); err != nil {
+			log.Printf("failed to build suggestion index: %v", err)
+		}
+	}()
 	bookHandler := handler.NewBookHandler(bookService)
 
-	router := setupRouter(bookHandler)
+	webhookRepository := repository.NewInMemoryWebhookRepository()
+	webhookService := service.NewWebhookService(webhookRepository)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	changeBroker, err := stream.NewBroker(bookRepository, stream.DefaultReplaySize, stream.DefaultClientBuffer)
+	if err != nil {
+		log.Fatalf("failed to start change stream: %v", err)
+	}
+	changeStreamHandler := handler.NewChangeStreamHandler(changeBroker)
+	eventBus.Subscribe(event.AllEvents, changeBroker.Publish)
+
+	idempotencyStore := middleware.NewIdempotencyStore(durationFromEnv("IDEMPOTENCY_TTL", 24*time.Hour))
+
+	graphQLConfig := gql.DefaultConfig()
+	graphQLSchema, err := gql.NewSchema(bookService, graphQLConfig)
+	if err != nil {
+		log.Fatalf("failed to parse graphql schema: %v", err)
+	}
+	graphQLHandler := handler.NewGraphQLHandler(graphQLSchema, graphQLConfig.MaxComplexity)
+
+	appRouter := router.New(
+		router.Handlers{
+			Book:         bookHandler,
+			Webhook:      webhookHandler,
+			ChangeStream: changeStreamHandler,
+			GraphQL:      graphQLHandler,
+			OpenAPI:      handler.NewOpenAPIHandler(),
+		},
+		router.Config{
+			Idempotency: idempotencyStore,
+			Development: os.Getenv("APP_ENV") == "development",
+			Unversioned: middleware.Deprecation{
+				At:     timeFromEnv("API_UNVERSIONED_DEPRECATED_AT", unversionedDeprecatedAt),
+				Sunset: timeFromEnv("API_UNVERSIONED_SUNSET", unversionedSunset),
+			},
+			MaxBodySize: int64(intFromEnv("MAX_BODY_SIZE", middleware.DefaultMaxBodySize)),
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go runPurgeJob(
+		ctx,
+		bookService,
+		durationFromEnv("TRASH_PURGE_INTERVAL", time.Hour),
+		durationFromEnv("TRASH_RETENTION", 30*24*time.Hour),
+	)
+	go runChangeCompaction(
+		ctx,
+		bookRepository,
+		durationFromEnv("CHANGE_LOG_COMPACT_INTERVAL", time.Minute),
+		intFromEnv("CHANGE_LOG_RETENTION", 10000),
+	)
+	// Webhooks are fed from the outbox rather than the event bus, so they
+	// get the outbox's at-least-once delivery of committed changes.
+	go outbox.NewDispatcher(bookRepository, outbox.DefaultConfig(), append(outboxSinks(), webhookService)...).Run(ctx)
+	webhookConfig := webhook.DefaultConfig()
+	webhookConfig.Retention = durationFromEnv("WEBHOOK_DELIVERY_RETENTION", webhookConfig.Retention)
+	go webhook.NewDispatcher(webhookRepository, webhookConfig).Run(ctx)
 
 	srv := &http.Server{
 		Addr:         ":8080",
-		Handler:      router,
+		Handler:      appRouter,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	srv.RegisterOnShutdown(changeBroker.Close)
+
+	grpcServer := rpc.NewServer(rpc.NewBookServer(bookService))
+	grpcAddr := os.Getenv("GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = ":9090"
+	}
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", grpcAddr, err)
+	}
+	go func() {
+		log.Printf("grpc server starting on %s", grpcAddr)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("grpc server error: %v", err)
+		}
+	}()
 
 	go func() {
 		log.Println("server starting on :8080")
@@ -39,32 +144,177 @@ func main() {
 		}
 	}()
 
-	gracefulShutdown(srv)
+	gracefulShutdown(srv, stopGRPC(grpcServer, 30*time.Second), cancel, eventBus.Wait, cacheStats, closeBookRepository)
+}
+
+func runPurgeJob(ctx context.Context, bookService *service.BookService, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := bookService.PurgeTrash(ctx, retention)
+			if err != nil {
+				log.Printf("trash purge failed: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("purged %d deleted books", purged)
+			}
+		}
+	}
 }
 
-func setupRouter(bookHandler *handler.BookHandler) http.Handler {
-	router := mux.NewRouter()
+// runChangeCompaction keeps the change log, and with it every snapshot, at
+// the newest keep changes. Feed readers that fall further behind get 410
+// CHANGES_EXPIRED and resync from the book list.
+func runChangeCompaction(ctx context.Context, changes changeCompactor, interval time.Duration, keep int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	router.HandleFunc("/health", healthCheck).Methods(http.MethodGet)
-	router.HandleFunc("/books", bookHandler.Create).Methods(http.MethodPost)
-	router.HandleFunc("/books", bookHandler.List).Methods(http.MethodGet)
-	router.HandleFunc("/books/{id}", bookHandler.GetByID).Methods(http.MethodGet)
-	router.HandleFunc("/books/{id}", bookHandler.Update).Methods(http.MethodPut)
-	router.HandleFunc("/books/{id}", bookHandler.Delete).Methods(http.MethodDelete)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dropped, err := changes.CompactChanges(ctx, keep)
+			if err != nil {
+				log.Printf("change log compaction failed: %v", err)
+				continue
+			}
+			if dropped > 0 {
+				log.Printf("dropped %d changes from the change log", dropped)
+			}
+		}
+	}
+}
 
-	router.Use(middleware.Recovery)
-	router.Use(middleware.Logger)
+func outboxSinks() []outbox.Sink {
+	sinks := []outbox.Sink{outbox.LogSink{}}
+	if path := os.Getenv("OUTBOX_FILE"); path != "" {
+		sinks = append(sinks, outbox.NewFileSink(path))
+	}
+	if url := os.Getenv("OUTBOX_WEBHOOK_URL"); url != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(url))
+	}
+	return sinks
+}
 
-	return router
+type changeCompactor interface {
+	CompactChanges(ctx context.Context, keep int) (int, error)
 }
 
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"healthy"}`))
+type bookStore interface {
+	repository.TransactionalBookRepository
+	domain.ChangeLog
+	domain.OutboxRepository
+	domain.HistoryRepository
+	changeCompactor
 }
 
-func gracefulShutdown(srv *http.Server) {
+func openBookRepository() (bookStore, func()) {
+	dir := os.Getenv("BOOK_DATA_DIR")
+	if dir == "" {
+		return repository.NewInMemoryBookRepository(), func() {}
+	}
+
+	config := repository.DefaultFileConfig(dir)
+	if raw := os.Getenv("BOOK_WAL_SYNC"); raw != "" {
+		policy, ok := repository.ParseSyncPolicy(raw)
+		if !ok {
+			log.Printf("invalid BOOK_WAL_SYNC %q, using always", raw)
+		}
+		config.Sync = policy
+	}
+	config.SyncInterval = durationFromEnv("BOOK_WAL_SYNC_INTERVAL", config.SyncInterval)
+	config.SnapshotEvery = intFromEnv("BOOK_SNAPSHOT_EVERY", config.SnapshotEvery)
+
+	repo, err := repository.NewFileBookRepository(config)
+	if err != nil {
+		log.Fatalf("failed to open book repository in %s: %v", dir, err)
+	}
+	return repo, func() {
+		if err := repo.Close(); err != nil {
+			log.Printf("failed to close book repository: %v", err)
+		}
+	}
+}
+
+func cachedBookRepository(inner repository.TransactionalBookRepository) (domain.BookRepository, func()) {
+	size := intFromEnv("BOOK_CACHE_SIZE", 0)
+	if size == 0 {
+		return inner, func() {}
+	}
+
+	config := repository.DefaultCacheConfig()
+	config.Size = size
+	config.TTL = durationFromEnv("BOOK_CACHE_TTL", config.TTL)
+	config.NegativeTTL = durationFromEnv("BOOK_CACHE_NEGATIVE_TTL", config.NegativeTTL)
+	cache := repository.NewTransactionalCachedBookRepository(inner, config)
+	return cache, func() {
+		log.Printf("book cache stats: %+v", cache.Stats())
+	}
+}
+
+func intFromEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Printf("invalid %s %q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		log.Printf("invalid %s %q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+func timeFromEnv(key string, fallback time.Time) time.Time {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		log.Printf("invalid %s %q, using %s", key, raw, fallback.Format(time.RFC3339))
+		return fallback
+	}
+	return value
+}
+
+func stopGRPC(server *grpc.Server, timeout time.Duration) func() {
+	return func() {
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(timeout):
+			server.Stop()
+		}
+	}
+}
+
+func gracefulShutdown(srv *http.Server, onShutdown ...func()) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -78,5 +328,9 @@ func gracefulShutdown(srv *http.Server) {
 		log.Fatalf("server forced to shutdown: %v", err)
 	}
 
+	for _, fn := range onShutdown {
+		fn()
+	}
+
 	log.Println("server stopped gracefully")
 }
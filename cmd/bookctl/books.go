@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+
+	"solid/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newListCommand(app *app) *cobra.Command {
+	var pageSize, limit int
+	var filter string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List books in creation order",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := app.client()
+			if err != nil {
+				return err
+			}
+
+			books := make([]*client.Book, 0)
+			for book, err := range c.ListBooks(cmd.Context(), client.ListOptions{PageSize: pageSize, Filter: filter}) {
+				if err != nil {
+					return err
+				}
+				books = append(books, book)
+				if limit > 0 && len(books) == limit {
+					break
+				}
+			}
+			return printBooks(cmd.OutOrStdout(), app.output, books)
+		},
+	}
+	cmd.Flags().IntVar(&pageSize, "page-size", 100, "books fetched per request")
+	cmd.Flags().IntVar(&limit, "limit", 0, "maximum number of books to show (0 for all)")
+	cmd.Flags().StringVar(&filter, "filter", "", "only list books matching an expression, e.g. \"author eq 'Robert Martin'\"")
+	return cmd
+}
+
+func newGetCommand(app *app) *cobra.Command {
+	return &cobra.Command{
+		Use:               "get ID",
+		Short:             "Show a book",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: app.completeBookIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := app.client()
+			if err != nil {
+				return err
+			}
+			book, err := c.GetBook(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printBook(cmd.OutOrStdout(), app.output, book)
+		},
+	}
+}
+
+func newCreateCommand(app *app) *cobra.Command {
+	var input client.CreateBookInput
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a book",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := app.client()
+			if err != nil {
+				return err
+			}
+			book, err := c.CreateBook(cmd.Context(), input)
+			if err != nil {
+				return err
+			}
+			return printBook(cmd.OutOrStdout(), app.output, book)
+		},
+	}
+	cmd.Flags().StringVar(&input.Title, "title", "", "book title")
+	cmd.Flags().StringVar(&input.Author, "author", "", "book author")
+	cmd.Flags().StringVar(&input.ISBN, "isbn", "", "ISBN-10 or ISBN-13")
+	cmd.Flags().BoolVar(&input.Force, "force", false, "create even if a similar book exists")
+	cmd.MarkFlagRequired("title")
+	cmd.MarkFlagRequired("author")
+	cmd.MarkFlagRequired("isbn")
+	return cmd
+}
+
+func newUpdateCommand(app *app) *cobra.Command {
+	var input client.UpdateBookInput
+
+	cmd := &cobra.Command{
+		Use:               "update ID",
+		Short:             "Update a book; omitted fields keep their value",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: app.completeBookIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if input == (client.UpdateBookInput{}) {
+				return fmt.Errorf("nothing to update: set --title, --author or --isbn")
+			}
+			c, err := app.client()
+			if err != nil {
+				return err
+			}
+			book, err := c.UpdateBook(cmd.Context(), args[0], input)
+			if err != nil {
+				return err
+			}
+			return printBook(cmd.OutOrStdout(), app.output, book)
+		},
+	}
+	cmd.Flags().StringVar(&input.Title, "title", "", "new title")
+	cmd.Flags().StringVar(&input.Author, "author", "", "new author")
+	cmd.Flags().StringVar(&input.ISBN, "isbn", "", "new ISBN")
+	return cmd
+}
+
+func newDeleteCommand(app *app) *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete ID...",
+		Short:             "Move books to the trash",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: app.completeBookIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := app.client()
+			if err != nil {
+				return err
+			}
+			for _, id := range args {
+				if err := c.DeleteBook(cmd.Context(), id); err != nil {
+					return fmt.Errorf("delete %s: %w", id, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "deleted %s\n", id)
+			}
+			return nil
+		},
+	}
+}
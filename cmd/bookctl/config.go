@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultServer  = "http://localhost:8080"
+	defaultProfile = "default"
+)
+
+type Config struct {
+	CurrentProfile string              `yaml:"current-profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+type Profile struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token,omitempty"`
+	Actor  string `yaml:"actor,omitempty"`
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv("BOOKCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".bookctl.yaml"
+	}
+	return filepath.Join(dir, "bookctl", "config.yaml")
+}
+
+// loadConfig returns an empty config when the file does not exist yet, so
+// bookctl works against a local server without any setup.
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: make(map[string]*Profile)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]*Profile)
+	}
+	return config, nil
+}
+
+func (c *Config) save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve picks the profile named by the flag, then BOOKCTL_PROFILE, then
+// the config's current profile. An explicitly named profile must exist.
+func (c *Config) resolve(name string) (string, *Profile, error) {
+	explicit := name != ""
+	if name == "" {
+		name = os.Getenv("BOOKCTL_PROFILE")
+		explicit = name != ""
+	}
+	if name == "" {
+		name = c.CurrentProfile
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if explicit {
+			return "", nil, fmt.Errorf("profile %q not found", name)
+		}
+		return name, &Profile{Server: defaultServer}, nil
+	}
+	resolved := *profile
+	if resolved.Server == "" {
+		resolved.Server = defaultServer
+	}
+	return name, &resolved, nil
+}
+
+func newProfileCommand(app *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage server profiles",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(app.configPath)
+			if err != nil {
+				return err
+			}
+			current, _, _ := config.resolve("")
+
+			w := newTabWriter(cmd.OutOrStdout())
+			fmt.Fprintln(w, "CURRENT\tNAME\tSERVER\tACTOR")
+			for _, name := range config.profileNames() {
+				marker := ""
+				if name == current {
+					marker = "*"
+				}
+				profile := config.Profiles[name]
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, profile.Server, profile.Actor)
+			}
+			return w.Flush()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "use NAME",
+		Short:             "Make a profile the default",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: app.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(app.configPath)
+			if err != nil {
+				return err
+			}
+			if _, ok := config.Profiles[args[0]]; !ok {
+				return fmt.Errorf("profile %q not found", args[0])
+			}
+			config.CurrentProfile = args[0]
+			return config.save(app.configPath)
+		},
+	})
+
+	var set Profile
+	setCmd := &cobra.Command{
+		Use:               "set NAME",
+		Short:             "Create or update a profile",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: app.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(app.configPath)
+			if err != nil {
+				return err
+			}
+			profile, ok := config.Profiles[args[0]]
+			if !ok {
+				profile = &Profile{Server: defaultServer}
+				config.Profiles[args[0]] = profile
+			}
+			if cmd.Flags().Changed("server") {
+				profile.Server = set.Server
+			}
+			if cmd.Flags().Changed("token") {
+				profile.Token = set.Token
+			}
+			if cmd.Flags().Changed("actor") {
+				profile.Actor = set.Actor
+			}
+			if config.CurrentProfile == "" {
+				config.CurrentProfile = args[0]
+			}
+			return config.save(app.configPath)
+		},
+	}
+	setCmd.Flags().StringVar(&set.Server, "server", "", "base URL of the book API")
+	setCmd.Flags().StringVar(&set.Token, "token", "", "bearer token sent with every request")
+	setCmd.Flags().StringVar(&set.Actor, "actor", "", "name recorded in the book history")
+	cmd.AddCommand(setCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:               "delete NAME",
+		Short:             "Delete a profile",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: app.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(app.configPath)
+			if err != nil {
+				return err
+			}
+			if _, ok := config.Profiles[args[0]]; !ok {
+				return fmt.Errorf("profile %q not found", args[0])
+			}
+			delete(config.Profiles, args[0])
+			if config.CurrentProfile == args[0] {
+				config.CurrentProfile = ""
+			}
+			return config.save(app.configPath)
+		},
+	})
+
+	return cmd
+}
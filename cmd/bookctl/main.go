@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"solid/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+}
+
+type app struct {
+	configPath string
+	profile    string
+	server     string
+	output     string
+}
+
+func newRootCommand() *cobra.Command {
+	app := &app{}
+
+	cmd := &cobra.Command{
+		Use:           "bookctl",
+		Short:         "Manage the book catalog over the HTTP API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.PersistentFlags().StringVar(&app.configPath, "config", defaultConfigPath(), "path to the config file")
+	cmd.PersistentFlags().StringVarP(&app.profile, "profile", "p", "", "profile to use (default: BOOKCTL_PROFILE or the current profile)")
+	cmd.PersistentFlags().StringVar(&app.server, "server", "", "base URL of the book API, overriding the profile")
+	cmd.PersistentFlags().StringVarP(&app.output, "output", "o", formatTable, "output format: table, json or yaml")
+	cmd.RegisterFlagCompletionFunc("profile", app.completeProfiles)
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{formatTable, formatJSON, formatYAML}, cobra.ShellCompDirectiveNoFileComp,
+	))
+
+	cmd.AddCommand(
+		newListCommand(app),
+		newGetCommand(app),
+		newCreateCommand(app),
+		newUpdateCommand(app),
+		newDeleteCommand(app),
+		newImportCommand(app),
+		newExportCommand(app),
+		newProfileCommand(app),
+	)
+	return cmd
+}
+
+func (a *app) client() (*client.Client, error) {
+	config, err := loadConfig(a.configPath)
+	if err != nil {
+		return nil, err
+	}
+	_, profile, err := config.resolve(a.profile)
+	if err != nil {
+		return nil, err
+	}
+
+	server := profile.Server
+	if a.server != "" {
+		server = a.server
+	}
+	token := profile.Token
+	if env := os.Getenv("BOOKCTL_TOKEN"); env != "" {
+		token = env
+	}
+
+	opts := []client.Option{}
+	if token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	if profile.Actor != "" {
+		opts = append(opts, client.WithActor(profile.Actor))
+	}
+	return client.New(server, opts...), nil
+}
+
+func (a *app) completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config, err := loadConfig(a.configPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return config.profileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBookIDs offers the IDs of existing books, with the title as the
+// description shown by shells that support it.
+func (a *app) completeBookIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	c, err := a.client()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	ids := make([]string, 0)
+	for book, err := range c.ListBooks(cmd.Context(), client.ListOptions{}) {
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		ids = append(ids, book.ID+"\t"+book.Title)
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+func printError(err error) {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		fmt.Fprintf(os.Stderr, "error: %s (%s)\n", apiErr.Message, apiErr.Code)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"solid/internal/router/routertest"
+
+	"gopkg.in/yaml.v3"
+)
+
+func run(t *testing.T, configPath string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	cmd := newRootCommand()
+	cmd.SetArgs(append([]string{"--config", configPath}, args...))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func mustRun(t *testing.T, configPath string, args ...string) string {
+	t.Helper()
+
+	out, err := run(t, configPath, args...)
+	if err != nil {
+		t.Fatalf("bookctl %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+func TestProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	server := routertest.NewServer(t)
+
+	mustRun(t, configPath, "profile", "set", "local", "--server", server.URL, "--actor", "ops")
+	mustRun(t, configPath, "profile", "set", "prod", "--server", "http://books.invalid", "--token", "secret")
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.CurrentProfile != "local" || config.Profiles["prod"].Token != "secret" {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if info, _ := os.Stat(configPath); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected config to be private, got %v", info.Mode().Perm())
+	}
+
+	out := mustRun(t, configPath, "profile", "list")
+	if !strings.Contains(out, "*        local") || !strings.Contains(out, "prod") {
+		t.Errorf("unexpected profile list:\n%s", out)
+	}
+
+	mustRun(t, configPath, "create", "--title", "Clean Code", "--author", "Robert Martin", "--isbn", "0132350882")
+	if _, err := run(t, configPath, "--profile", "missing", "list"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+
+	mustRun(t, configPath, "profile", "use", "prod")
+	out = mustRun(t, configPath, "--profile", "local", "list")
+	if !strings.Contains(out, "Clean Code") {
+		t.Errorf("expected the book from the local profile, got:\n%s", out)
+	}
+}
+
+func TestBookCommands(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	server := routertest.NewServer(t)
+	base := []string{"--server", server.URL}
+
+	out := mustRun(t, configPath, append(base, "-o", "json", "create", "--title", "Clean Code", "--author", "Robert Martin", "--isbn", "0132350882")...)
+	var created bookRecord
+	if err := json.Unmarshal([]byte(out), &created); err != nil || created.ID == "" {
+		t.Fatalf("unexpected create output %q: %v", out, err)
+	}
+
+	out = mustRun(t, configPath, append(base, "-o", "yaml", "update", created.ID, "--title", "Clean Code 2")...)
+	var updated bookRecord
+	if err := yaml.Unmarshal([]byte(out), &updated); err != nil || updated.Title != "Clean Code 2" || updated.Author != "Robert Martin" {
+		t.Fatalf("unexpected update output %q: %v", out, err)
+	}
+
+	out = mustRun(t, configPath, append(base, "get", created.ID)...)
+	if !strings.Contains(out, "ID") || !strings.Contains(out, "Clean Code 2") {
+		t.Errorf("unexpected table output:\n%s", out)
+	}
+
+	mustRun(t, configPath, append(base, "delete", created.ID)...)
+	out, err := run(t, configPath, append(base, "get", created.ID)...)
+	if err == nil {
+		t.Fatalf("expected an error for a deleted book, got:\n%s", out)
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	source := []string{"--server", routertest.NewServer(t).URL}
+	target := []string{"--server", routertest.NewServer(t).URL}
+
+	input := filepath.Join(dir, "books.csv")
+	os.WriteFile(input, []byte("title,author,isbn\nClean Code,Robert Martin,0132350882\nRefactoring,Martin Fowler,0201485672\n"), 0o644)
+	out := mustRun(t, configPath, append(source, "import", input)...)
+	if !strings.Contains(out, "imported 2, skipped 0, failed 0") {
+		t.Fatalf("unexpected import output:\n%s", out)
+	}
+
+	for _, name := range []string{"books.json", "books.yaml", "books.csv"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, "export-"+name)
+			mustRun(t, configPath, append(source, "export", path)...)
+
+			out := mustRun(t, configPath, append(target, "import", "--skip-existing", path)...)
+			if !strings.Contains(out, "failed 0") || (name != "books.json" && !strings.Contains(out, "skipped 2")) {
+				t.Errorf("unexpected import output:\n%s", out)
+			}
+		})
+	}
+
+	out = mustRun(t, configPath, append(target, "-o", "json", "list")...)
+	var books []bookRecord
+	if err := json.Unmarshal([]byte(out), &books); err != nil || len(books) != 2 {
+		t.Fatalf("expected 2 books after importing, got %q: %v", out, err)
+	}
+
+	out, err := run(t, configPath, append(target, "import", input)...)
+	if err == nil || !strings.Contains(out, "failed 2") {
+		t.Errorf("expected failures when books already exist, got %v:\n%s", err, out)
+	}
+}
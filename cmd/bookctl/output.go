@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"solid/pkg/client"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatCSV   = "csv"
+)
+
+// bookRecord is the shape books take in every bookctl format, so an export
+// can be imported again unchanged.
+type bookRecord struct {
+	ID        string     `json:"id,omitempty" yaml:"id,omitempty"`
+	Title     string     `json:"title" yaml:"title"`
+	Author    string     `json:"author" yaml:"author"`
+	ISBN      string     `json:"isbn" yaml:"isbn"`
+	CreatedAt *time.Time `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+}
+
+func toRecord(book *client.Book) bookRecord {
+	return bookRecord{
+		ID:        book.ID,
+		Title:     book.Title,
+		Author:    book.Author,
+		ISBN:      book.ISBN,
+		CreatedAt: &book.CreatedAt,
+		UpdatedAt: &book.UpdatedAt,
+	}
+}
+
+func toRecords(books []*client.Book) []bookRecord {
+	records := make([]bookRecord, 0, len(books))
+	for _, book := range books {
+		records = append(records, toRecord(book))
+	}
+	return records
+}
+
+func newTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+func printBooks(w io.Writer, format string, books []*client.Book) error {
+	switch format {
+	case formatTable:
+		tw := newTabWriter(w)
+		fmt.Fprintln(tw, "ID\tTITLE\tAUTHOR\tISBN\tUPDATED")
+		for _, book := range books {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", book.ID, book.Title, book.Author, book.ISBN, book.UpdatedAt.Format(time.RFC3339))
+		}
+		return tw.Flush()
+	default:
+		return encode(w, format, toRecords(books))
+	}
+}
+
+func printBook(w io.Writer, format string, book *client.Book) error {
+	if format == formatTable {
+		return printBooks(w, format, []*client.Book{book})
+	}
+	return encode(w, format, toRecord(book))
+}
+
+func encode(w io.Writer, format string, value interface{}) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case formatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"solid/internal/domain"
+	"solid/pkg/client"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var csvHeader = []string{"id", "title", "author", "isbn", "created_at", "updated_at"}
+
+func newExportCommand(app *app) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export [FILE]",
+		Short: "Write all books to a JSON, YAML or CSV file (default stdout)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) == 1 {
+				path = args[0]
+			}
+			format, err := fileFormat(format, path)
+			if err != nil {
+				return err
+			}
+
+			c, err := app.client()
+			if err != nil {
+				return err
+			}
+			records := make([]bookRecord, 0)
+			for book, err := range c.ListBooks(cmd.Context(), client.ListOptions{}) {
+				if err != nil {
+					return err
+				}
+				records = append(records, toRecord(book))
+			}
+
+			out := cmd.OutOrStdout()
+			if path != "" && path != "-" {
+				file, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+			if err := writeRecords(out, format, records); err != nil {
+				return err
+			}
+			if path != "" && path != "-" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "exported %d books to %s\n", len(records), path)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "json, yaml or csv (default: from the file extension, else json)")
+	return cmd
+}
+
+func newImportCommand(app *app) *cobra.Command {
+	var format string
+	var force, skipExisting bool
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Create books from a JSON, YAML or CSV file (- for stdin)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := fileFormat(format, args[0])
+			if err != nil {
+				return err
+			}
+
+			in := cmd.InOrStdin()
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				in = file
+			}
+			records, err := readRecords(in, format)
+			if err != nil {
+				return err
+			}
+
+			c, err := app.client()
+			if err != nil {
+				return err
+			}
+			existing := make(map[string]bool)
+			if skipExisting {
+				for book, err := range c.ListBooks(cmd.Context(), client.ListOptions{}) {
+					if err != nil {
+						return err
+					}
+					existing[book.ISBN] = true
+				}
+			}
+
+			imported, skipped, failed := 0, 0, 0
+			for i, record := range records {
+				if existing[domain.NormalizeISBN(record.ISBN)] {
+					skipped++
+					continue
+				}
+				_, err := c.CreateBook(cmd.Context(), client.CreateBookInput{
+					Title:  record.Title,
+					Author: record.Author,
+					ISBN:   record.ISBN,
+					Force:  force,
+				})
+				var apiErr *client.Error
+				switch {
+				case err == nil:
+					imported++
+				case skipExisting && errors.As(err, &apiErr) && apiErr.Code == client.CodeBookAlreadyExists:
+					skipped++
+				default:
+					failed++
+					fmt.Fprintf(cmd.ErrOrStderr(), "record %d (%s): %v\n", i+1, record.Title, err)
+				}
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "imported %d, skipped %d, failed %d\n", imported, skipped, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d books could not be imported", failed, len(records))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "json, yaml or csv (default: from the file extension, else json)")
+	cmd.Flags().BoolVar(&force, "force", false, "create books even if a similar book exists")
+	cmd.Flags().BoolVar(&skipExisting, "skip-existing", false, "skip books whose ISBN already exists instead of failing")
+	return cmd
+}
+
+func fileFormat(format, path string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			format = formatYAML
+		case ".csv":
+			format = formatCSV
+		default:
+			format = formatJSON
+		}
+	}
+	switch format {
+	case formatJSON, formatYAML, formatCSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported file format %q", format)
+}
+
+func writeRecords(w io.Writer, format string, records []bookRecord) error {
+	if format != formatCSV {
+		return encode(w, format, records)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, record := range records {
+		writer.Write([]string{
+			record.ID,
+			record.Title,
+			record.Author,
+			record.ISBN,
+			formatTime(record.CreatedAt),
+			formatTime(record.UpdatedAt),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func readRecords(r io.Reader, format string) ([]bookRecord, error) {
+	var records []bookRecord
+	switch format {
+	case formatJSON:
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case formatYAML:
+		if err := yaml.NewDecoder(r).Decode(&records); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	case formatCSV:
+		return readCSV(r)
+	}
+	return records, nil
+}
+
+func readCSV(r io.Reader) ([]bookRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "author", "isbn"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid CSV: missing %q column", required)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		if i := columns[name]; i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	records := make([]bookRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		records = append(records, bookRecord{
+			Title:  field(row, "title"),
+			Author: field(row, "author"),
+			ISBN:   field(row, "isbn"),
+		})
+	}
+	return records, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
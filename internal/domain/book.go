@@ -15,12 +15,19 @@ const (
 var isbnRegex = regexp.MustCompile(`^(?:\d{10}|\d{13}|[\d-]{13,17})$`)
 
 type Book struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	Author    string    `json:"author"`
-	ISBN      string    `json:"isbn"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Author    string     `json:"author"`
+	ISBN      string     `json:"isbn"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type BookPage struct {
+	Books      []*Book `json:"books"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	HasMore    bool    `json:"has_more"`
 }
 
 func NewBook(title, author, isbn string) (*Book, error) {
@@ -32,12 +39,37 @@ func NewBook(title, author, isbn string) (*Book, error) {
 	return &Book{
 		Title:     strings.TrimSpace(title),
 		Author:    strings.TrimSpace(author),
-		ISBN:      normalizeISBN(isbn),
+		ISBN:      NormalizeISBN(isbn),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
 }
 
+func (b *Book) Update(title, author, isbn string) error {
+	if title == "" {
+		title = b.Title
+	}
+	if author == "" {
+		author = b.Author
+	}
+	if isbn == "" {
+		isbn = b.ISBN
+	}
+	if err := validateBook(title, author, isbn); err != nil {
+		return err
+	}
+
+	b.Title = strings.TrimSpace(title)
+	b.Author = strings.TrimSpace(author)
+	b.ISBN = NormalizeISBN(isbn)
+	b.UpdatedAt = time.Now()
+	return nil
+}
+
+func (b *Book) IsDeleted() bool {
+	return b.DeletedAt != nil
+}
+
 func validateBook(title, author, isbn string) error {
 	if err := validateTitle(title); err != nil {
 		return err
@@ -81,13 +113,13 @@ func validateISBN(isbn string) error {
 	if !isbnRegex.MatchString(isbn) {
 		return ErrInvalidInput.WithMessage("isbn format is invalid")
 	}
-	normalized := normalizeISBN(isbn)
+	normalized := NormalizeISBN(isbn)
 	if len(normalized) != 10 && len(normalized) != 13 {
 		return ErrInvalidInput.WithMessage("isbn must be 10 or 13 digits")
 	}
 	return nil
 }
 
-func normalizeISBN(isbn string) string {
+func NormalizeISBN(isbn string) string {
 	return strings.ReplaceAll(strings.TrimSpace(isbn), "-", "")
 }
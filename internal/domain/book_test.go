@@ -126,3 +126,31 @@ func TestValidateISBN(t *testing.T) {
 		})
 	}
 }
+
+func TestBook_Update(t *testing.T) {
+	t.Run("partial update keeps other fields", func(t *testing.T) {
+		book, _ := NewBook("Clean Code", "Robert Martin", "0132350882")
+
+		err := book.Update(" Clean Coder ", "", "978-0-13-708107-3")
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if book.Title != "Clean Coder" || book.Author != "Robert Martin" || book.ISBN != "9780137081073" {
+			t.Errorf("unexpected book %+v", book)
+		}
+	})
+
+	t.Run("invalid value leaves book untouched", func(t *testing.T) {
+		book, _ := NewBook("Clean Code", "Robert Martin", "0132350882")
+
+		err := book.Update("", "", "123")
+
+		if err == nil {
+			t.Error("expected error but got none")
+		}
+		if book.ISBN != "0132350882" {
+			t.Errorf("expected isbn unchanged, got %s", book.ISBN)
+		}
+	})
+}
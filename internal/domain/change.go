@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	ChangeCreate  = "create"
+	ChangeUpdate  = "update"
+	ChangeDelete  = "delete"
+	ChangeRestore = "restore"
+	ChangePurge   = "purge"
+)
+
+type Change struct {
+	Sequence  uint64    `json:"sequence"`
+	Operation string    `json:"operation"`
+	BookID    string    `json:"book_id"`
+	Book      *Book     `json:"book,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type ChangePage struct {
+	Changes   []*Change `json:"changes"`
+	NextSince uint64    `json:"next_since"`
+	HasMore   bool      `json:"has_more"`
+}
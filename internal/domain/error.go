@@ -10,6 +10,20 @@ var (
 	ErrBookNotFound      = NewDomainError("BOOK_NOT_FOUND", "book not found", http.StatusNotFound)
 	ErrBookAlreadyExists = NewDomainError("BOOK_ALREADY_EXISTS", "book already exists", http.StatusConflict)
 	ErrInvalidInput      = NewDomainError("INVALID_INPUT", "invalid input", http.StatusBadRequest)
+	ErrPossibleDuplicate = NewDomainError("POSSIBLE_DUPLICATE", "possible duplicate book", http.StatusConflict)
+	ErrVersionNotFound   = NewDomainError("VERSION_NOT_FOUND", "version not found", http.StatusNotFound)
+	ErrNotSupported      = NewDomainError("NOT_SUPPORTED", "operation not supported", http.StatusNotImplemented)
+	ErrBatchAborted      = NewDomainError("BATCH_ABORTED", "operation rolled back because another operation in the batch failed", http.StatusFailedDependency)
+	ErrUnknownField      = NewDomainError("UNKNOWN_FIELD", "unknown field", http.StatusBadRequest)
+	ErrUnknownExpansion  = NewDomainError("UNKNOWN_EXPANSION", "unknown expansion", http.StatusBadRequest)
+	ErrInvalidFilter     = NewDomainError("INVALID_FILTER", "invalid filter", http.StatusBadRequest)
+	ErrBookMerged        = NewDomainError("BOOK_MERGED", "book was merged into another book", http.StatusConflict)
+	ErrChangesExpired    = NewDomainError("CHANGES_EXPIRED", "changes are no longer retained", http.StatusGone)
+	ErrQueryTooComplex   = NewDomainError("QUERY_TOO_COMPLEX", "query exceeds the complexity limit", http.StatusBadRequest)
+
+	ErrOutboxMessageNotFound = NewDomainError("OUTBOX_MESSAGE_NOT_FOUND", "outbox message not found", http.StatusNotFound)
+	ErrSubscriptionNotFound  = NewDomainError("SUBSCRIPTION_NOT_FOUND", "webhook subscription not found", http.StatusNotFound)
+	ErrDeliveryNotFound      = NewDomainError("DELIVERY_NOT_FOUND", "webhook delivery not found", http.StatusNotFound)
 )
 
 type DomainError struct {
@@ -17,6 +31,7 @@ type DomainError struct {
 	Message    string
 	StatusCode int
 	Err        error
+	Details    interface{}
 }
 
 func NewDomainError(code, message string, statusCode int) *DomainError {
@@ -40,6 +55,7 @@ func (e *DomainError) WithError(err error) *DomainError {
 		Message:    e.Message,
 		StatusCode: e.StatusCode,
 		Err:        err,
+		Details:    e.Details,
 	}
 }
 
@@ -49,6 +65,17 @@ func (e *DomainError) WithMessage(msg string) *DomainError {
 		Message:    msg,
 		StatusCode: e.StatusCode,
 		Err:        e.Err,
+		Details:    e.Details,
+	}
+}
+
+func (e *DomainError) WithDetails(details interface{}) *DomainError {
+	return &DomainError{
+		Code:       e.Code,
+		Message:    e.Message,
+		StatusCode: e.StatusCode,
+		Err:        e.Err,
+		Details:    details,
 	}
 }
 
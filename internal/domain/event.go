@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	EventBookCreated = "book.created"
+	EventBookUpdated = "book.updated"
+	EventBookDeleted = "book.deleted"
+)
+
+type Event interface {
+	EventName() string
+	OccurredAt() time.Time
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+type BookCreated struct {
+	Book Book      `json:"book"`
+	At   time.Time `json:"at"`
+}
+
+func (e BookCreated) EventName() string     { return EventBookCreated }
+func (e BookCreated) OccurredAt() time.Time { return e.At }
+
+type BookUpdated struct {
+	Book    Book          `json:"book"`
+	Changes []FieldChange `json:"changes"`
+	At      time.Time     `json:"at"`
+}
+
+func (e BookUpdated) EventName() string     { return EventBookUpdated }
+func (e BookUpdated) OccurredAt() time.Time { return e.At }
+
+type BookDeleted struct {
+	BookID string    `json:"book_id"`
+	At     time.Time `json:"at"`
+}
+
+func (e BookDeleted) EventName() string     { return EventBookDeleted }
+func (e BookDeleted) OccurredAt() time.Time { return e.At }
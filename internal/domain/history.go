@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+	ActionMerged   = "merged"
+	ActionReverted = "reverted"
+)
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type AuditEntry struct {
+	BookID    string        `json:"book_id"`
+	Version   int           `json:"version"`
+	Action    string        `json:"action"`
+	Actor     string        `json:"actor"`
+	RequestID string        `json:"request_id,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+	Changes   []FieldChange `json:"changes"`
+	Snapshot  Book          `json:"snapshot"`
+}
+
+func NewAuditEntry(ctx context.Context, action string, before, after *Book) *AuditEntry {
+	return &AuditEntry{
+		BookID:    after.ID,
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+		Timestamp: time.Now(),
+		Changes:   Diff(before, after),
+		Snapshot:  *after,
+	}
+}
+
+func Diff(before, after *Book) []FieldChange {
+	if before == nil {
+		before = &Book{}
+	}
+
+	changes := make([]FieldChange, 0)
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("author", before.Author, after.Author)
+	add("isbn", before.ISBN, after.ISBN)
+	add("deleted_at", formatTime(before.DeletedAt), formatTime(after.DeletedAt))
+	return changes
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
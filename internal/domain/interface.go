@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type BookRepository interface {
 	Create(ctx context.Context, book *Book) error
@@ -9,4 +12,62 @@ type BookRepository interface {
 	FindAll(ctx context.Context) ([]*Book, error)
 	Update(ctx context.Context, book *Book) error
 	Delete(ctx context.Context, id string) error
+	FindDeleted(ctx context.Context) ([]*Book, error)
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+	SaveMerge(ctx context.Context, merge *Merge) error
+	FindMerge(ctx context.Context, sourceID string) (*Merge, error)
+	FindMergesByTarget(ctx context.Context, targetID string) ([]*Merge, error)
+}
+
+// BookFilter selects books, for example a parsed ?filter= expression.
+type BookFilter interface {
+	Match(book *Book) bool
+}
+
+// FilterableRepository is implemented by repositories that can apply a
+// BookFilter themselves instead of returning every book.
+type FilterableRepository interface {
+	FindMatching(ctx context.Context, filter BookFilter) ([]*Book, error)
+}
+
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type ChangeLog interface {
+	Changes(ctx context.Context, since uint64, limit int) ([]*Change, error)
+	// LatestSequence returns the sequence of the newest change, or 0 when
+	// nothing has been written yet.
+	LatestSequence(ctx context.Context) (uint64, error)
+}
+
+type HistoryRepository interface {
+	Append(ctx context.Context, entry *AuditEntry) error
+	FindByBook(ctx context.Context, bookID string) ([]*AuditEntry, error)
+	FindVersion(ctx context.Context, bookID string, version int) (*AuditEntry, error)
+}
+
+type OutboxRepository interface {
+	PendingOutbox(ctx context.Context, now time.Time, limit int) ([]*OutboxMessage, error)
+	MarkDelivered(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, id string, reason string) error
+	DeadOutbox(ctx context.Context) ([]*OutboxMessage, error)
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *Subscription) error
+	FindSubscription(ctx context.Context, id string) (*Subscription, error)
+	FindSubscriptions(ctx context.Context) ([]*Subscription, error)
+	UpdateSubscription(ctx context.Context, sub *Subscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+	SaveDelivery(ctx context.Context, delivery *Delivery) error
+	FindDelivery(ctx context.Context, id string) (*Delivery, error)
+	FindDeliveries(ctx context.Context, subscriptionID string) ([]*Delivery, error)
+	FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+	FindDeadDeliveries(ctx context.Context) ([]*Delivery, error)
+	// PruneDeliveries drops delivered and dead deliveries that finished
+	// before finishedBefore and returns how many it dropped.
+	PruneDeliveries(ctx context.Context, finishedBefore time.Time) (int, error)
 }
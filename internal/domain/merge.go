@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+const (
+	MergeKeepTarget = "target"
+	MergeKeepSource = "source"
+)
+
+var mergeableFields = map[string]bool{"title": true, "author": true, "isbn": true}
+
+type Merge struct {
+	SourceID string            `json:"source_id"`
+	TargetID string            `json:"target_id"`
+	Fields   map[string]string `json:"fields"`
+	MergedAt time.Time         `json:"merged_at"`
+}
+
+func NewMerge(sourceID, targetID string, fields map[string]string) (*Merge, error) {
+	if sourceID == "" {
+		return nil, ErrInvalidInput.WithMessage("source_id cannot be empty")
+	}
+	if sourceID == targetID {
+		return nil, ErrInvalidInput.WithMessage("cannot merge a book into itself")
+	}
+
+	resolved := map[string]string{
+		"title":  MergeKeepTarget,
+		"author": MergeKeepTarget,
+		"isbn":   MergeKeepTarget,
+	}
+	for field, keep := range fields {
+		if !mergeableFields[field] {
+			return nil, ErrInvalidInput.WithMessage("unknown merge field: " + field)
+		}
+		if keep != MergeKeepTarget && keep != MergeKeepSource {
+			return nil, ErrInvalidInput.WithMessage("merge resolution must be source or target")
+		}
+		resolved[field] = keep
+	}
+
+	return &Merge{
+		SourceID: sourceID,
+		TargetID: targetID,
+		Fields:   resolved,
+		MergedAt: time.Now(),
+	}, nil
+}
+
+func (m *Merge) Apply(target, source *Book) {
+	if m.Fields["title"] == MergeKeepSource {
+		target.Title = source.Title
+	}
+	if m.Fields["author"] == MergeKeepSource {
+		target.Author = source.Author
+	}
+	if m.Fields["isbn"] == MergeKeepSource {
+		target.ISBN = source.ISBN
+	}
+	if source.CreatedAt.Before(target.CreatedAt) {
+		target.CreatedAt = source.CreatedAt
+	}
+	target.UpdatedAt = m.MergedAt
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxMessage struct {
+	ID            string          `json:"id"`
+	EventType     string          `json:"event_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	DeadAt        *time.Time      `json:"dead_at,omitempty"`
+}
+
+func NewOutboxMessage(eventType string, book *Book) (*OutboxMessage, error) {
+	payload, err := json.Marshal(book)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &OutboxMessage{
+		ID:            uuid.New().String(),
+		EventType:     eventType,
+		AggregateID:   book.ID,
+		Payload:       payload,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// Event rebuilds the domain event the message was written for. The payload
+// only holds the book after the change, so updates carry no field changes.
+func (m *OutboxMessage) Event() (Event, error) {
+	if m.EventType == EventBookDeleted {
+		return BookDeleted{BookID: m.AggregateID, At: m.CreatedAt}, nil
+	}
+
+	var book Book
+	if err := json.Unmarshal(m.Payload, &book); err != nil {
+		return nil, err
+	}
+	switch m.EventType {
+	case EventBookCreated:
+		return BookCreated{Book: book, At: m.CreatedAt}, nil
+	case EventBookUpdated:
+		return BookUpdated{Book: book, At: m.CreatedAt}, nil
+	}
+	return nil, fmt.Errorf("unknown outbox event type %q", m.EventType)
+}
@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+var webhookEvents = map[string]bool{
+	"*":              true,
+	EventBookCreated: true,
+	EventBookUpdated: true,
+	EventBookDeleted: true,
+}
+
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewSubscription(rawURL string, events []string, secret string) (*Subscription, error) {
+	if err := validateSubscription(rawURL, events); err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	now := time.Now()
+	return &Subscription{
+		ID:        uuid.New().String(),
+		URL:       rawURL,
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (s *Subscription) Update(rawURL string, events []string, active *bool) error {
+	if rawURL == "" {
+		rawURL = s.URL
+	}
+	if len(events) == 0 {
+		events = s.Events
+	}
+	if err := validateSubscription(rawURL, events); err != nil {
+		return err
+	}
+
+	s.URL = rawURL
+	s.Events = events
+	if active != nil {
+		s.Active = *active
+	}
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *Subscription) Matches(eventName string) bool {
+	if !s.Active {
+		return false
+	}
+	for _, e := range s.Events {
+		if e == "*" || e == eventName {
+			return true
+		}
+	}
+	return false
+}
+
+func validateSubscription(rawURL string, events []string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidInput.WithMessage("url must be an absolute http or https url")
+	}
+	if len(events) == 0 {
+		return ErrInvalidInput.WithMessage("events cannot be empty")
+	}
+	for _, e := range events {
+		if !webhookEvents[e] {
+			return ErrInvalidInput.WithMessage("unknown event type: " + e)
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+type DeliveryAttempt struct {
+	At         time.Time     `json:"at"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// Delivery is one event queued for one subscription. Only the attempts from
+// RetryFrom on count towards the retry limit, so a manual redelivery starts
+// with a fresh budget while the earlier attempts stay in the log.
+type Delivery struct {
+	ID             string            `json:"id"`
+	SubscriptionID string            `json:"subscription_id"`
+	EventType      string            `json:"event_type"`
+	Payload        json.RawMessage   `json:"payload"`
+	Status         string            `json:"status"`
+	Attempts       []DeliveryAttempt `json:"attempts"`
+	RetryFrom      int               `json:"retry_from"`
+	NextAttemptAt  time.Time         `json:"next_attempt_at"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+type webhookPayload struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       Event     `json:"data"`
+}
+
+// NewDelivery queues the event of an outbox message for a subscription. The
+// ID is derived from both, so a message the outbox delivers again maps onto
+// the delivery it already created.
+func NewDelivery(subscriptionID string, msg *OutboxMessage) (*Delivery, error) {
+	event, err := msg.Event()
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewSHA1(uuid.NameSpaceOID, []byte(msg.ID+"/"+subscriptionID)).String()
+	payload, err := json.Marshal(webhookPayload{
+		ID:         id,
+		Event:      event.EventName(),
+		OccurredAt: event.OccurredAt(),
+		Data:       event,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Delivery{
+		ID:             id,
+		SubscriptionID: subscriptionID,
+		EventType:      event.EventName(),
+		Payload:        payload,
+		Status:         DeliveryPending,
+		Attempts:       []DeliveryAttempt{},
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}, nil
+}
+
+// Redeliver queues a delivery again, whatever its status, with a fresh
+// retry budget.
+func (d *Delivery) Redeliver(now time.Time) {
+	d.Status = DeliveryPending
+	d.RetryFrom = len(d.Attempts)
+	d.NextAttemptAt = now
+}
+
+// TrimAttempts drops the oldest attempts beyond keep, shifting RetryFrom so
+// it still points at the first attempt of the current retry budget.
+func (d *Delivery) TrimAttempts(keep int) {
+	drop := len(d.Attempts) - keep
+	if keep <= 0 || drop <= 0 {
+		return
+	}
+	d.Attempts = append([]DeliveryAttempt(nil), d.Attempts[drop:]...)
+	d.RetryFrom = max(d.RetryFrom-drop, 0)
+}
+
+// FinishedAt returns when a delivered or dead delivery made its last
+// attempt, and false while the delivery is still pending.
+func (d *Delivery) FinishedAt() (time.Time, bool) {
+	if d.Status == DeliveryPending {
+		return time.Time{}, false
+	}
+	if len(d.Attempts) == 0 {
+		return d.CreatedAt, true
+	}
+	return d.Attempts[len(d.Attempts)-1].At, true
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewSubscription(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		events    []string
+		wantError bool
+	}{
+		{"valid", "https://example.com/hook", []string{EventBookCreated}, false},
+		{"wildcard", "http://localhost:9000", []string{"*"}, false},
+		{"relative url", "/hook", []string{EventBookCreated}, true},
+		{"unsupported scheme", "ftp://example.com", []string{EventBookCreated}, true},
+		{"no events", "https://example.com/hook", nil, true},
+		{"unknown event", "https://example.com/hook", []string{"book.read"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := NewSubscription(tt.url, tt.events, "")
+			if (err != nil) != tt.wantError {
+				t.Fatalf("NewSubscription() error = %v, wantError %v", err, tt.wantError)
+			}
+			if err == nil && len(sub.Secret) != 64 {
+				t.Errorf("expected generated secret, got %q", sub.Secret)
+			}
+		})
+	}
+}
+
+func TestNewDelivery(t *testing.T) {
+	book := &Book{ID: "book-1", Title: "Clean Code"}
+
+	t.Run("same message and subscription map to one delivery", func(t *testing.T) {
+		msg, _ := NewOutboxMessage(EventBookCreated, book)
+
+		first, err := NewDelivery("sub-1", msg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		again, _ := NewDelivery("sub-1", msg)
+		other, _ := NewDelivery("sub-2", msg)
+
+		if first.ID != again.ID || first.ID == other.ID {
+			t.Errorf("expected ids derived from message and subscription, got %s, %s, %s", first.ID, again.ID, other.ID)
+		}
+	})
+
+	t.Run("payload carries the event", func(t *testing.T) {
+		msg, _ := NewOutboxMessage(EventBookDeleted, book)
+
+		delivery, _ := NewDelivery("sub-1", msg)
+
+		var payload struct {
+			ID    string `json:"id"`
+			Event string `json:"event"`
+			Data  struct {
+				BookID string `json:"book_id"`
+			} `json:"data"`
+		}
+		json.Unmarshal(delivery.Payload, &payload)
+		if payload.ID != delivery.ID || payload.Event != EventBookDeleted || payload.Data.BookID != "book-1" {
+			t.Errorf("unexpected payload %s", delivery.Payload)
+		}
+	})
+
+	t.Run("unknown event type", func(t *testing.T) {
+		msg, _ := NewOutboxMessage("book.read", book)
+
+		if _, err := NewDelivery("sub-1", msg); err == nil {
+			t.Error("expected error but got none")
+		}
+	})
+}
+
+func TestDelivery_Redeliver(t *testing.T) {
+	delivery := &Delivery{Status: DeliveryDead, Attempts: []DeliveryAttempt{{Error: "timeout"}, {Error: "timeout"}}}
+
+	delivery.Redeliver(delivery.CreatedAt)
+
+	if delivery.Status != DeliveryPending || delivery.RetryFrom != 2 {
+		t.Errorf("expected pending delivery retrying from attempt 2, got %+v", delivery)
+	}
+}
+
+func TestDelivery_TrimAttempts(t *testing.T) {
+	delivery := &Delivery{Attempts: []DeliveryAttempt{{Error: "a"}, {Error: "b"}, {Error: "c"}, {Error: "d"}}, RetryFrom: 3}
+
+	delivery.TrimAttempts(2)
+
+	if len(delivery.Attempts) != 2 || delivery.Attempts[0].Error != "c" || delivery.RetryFrom != 1 {
+		t.Errorf("expected the newest 2 attempts retrying from 1, got %+v", delivery)
+	}
+
+	delivery.TrimAttempts(1)
+
+	if len(delivery.Attempts) != 1 || delivery.RetryFrom != 0 {
+		t.Errorf("expected RetryFrom to stay in range, got %+v", delivery)
+	}
+}
@@ -0,0 +1,88 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"solid/internal/domain"
+)
+
+const AllEvents = "*"
+
+type Handler func(ctx context.Context, e domain.Event) error
+
+type subscription struct {
+	name    string
+	handler Handler
+	async   bool
+}
+
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+	wg            sync.WaitGroup
+	onError       func(e domain.Event, err error)
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		onError: func(e domain.Event, err error) {
+			log.Printf("event subscriber failed for %s: %v", e.EventName(), err)
+		},
+	}
+}
+
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.subscribe(name, handler, false)
+}
+
+func (b *Bus) SubscribeAsync(name string, handler Handler) {
+	b.subscribe(name, handler, true)
+}
+
+func (b *Bus) subscribe(name string, handler Handler, async bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions = append(b.subscriptions, subscription{name: name, handler: handler, async: async})
+}
+
+func (b *Bus) Publish(ctx context.Context, e domain.Event) {
+	b.mu.RLock()
+	subscriptions := make([]subscription, len(b.subscriptions))
+	copy(subscriptions, b.subscriptions)
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		if sub.name != AllEvents && sub.name != e.EventName() {
+			continue
+		}
+		if sub.async {
+			b.wg.Add(1)
+			go func(handler Handler) {
+				defer b.wg.Done()
+				b.dispatch(context.WithoutCancel(ctx), handler, e)
+			}(sub.handler)
+			continue
+		}
+		b.dispatch(ctx, sub.handler, e)
+	}
+}
+
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
+
+func (b *Bus) dispatch(ctx context.Context, handler Handler, e domain.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.onError(e, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	if err := handler(ctx, e); err != nil {
+		b.onError(e, err)
+	}
+}
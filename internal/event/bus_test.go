@@ -0,0 +1,91 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+)
+
+func TestBus_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("routes by event name", func(t *testing.T) {
+		bus := NewBus()
+		var created, all []string
+		bus.Subscribe(domain.EventBookCreated, func(ctx context.Context, e domain.Event) error {
+			created = append(created, e.EventName())
+			return nil
+		})
+		bus.Subscribe(AllEvents, func(ctx context.Context, e domain.Event) error {
+			all = append(all, e.EventName())
+			return nil
+		})
+
+		bus.Publish(ctx, domain.BookCreated{})
+		bus.Publish(ctx, domain.BookDeleted{BookID: "test-id"})
+
+		if len(created) != 1 {
+			t.Errorf("expected 1 created event, got %v", created)
+		}
+		if len(all) != 2 {
+			t.Errorf("expected 2 events for wildcard subscriber, got %v", all)
+		}
+	})
+
+	t.Run("isolates failing subscribers", func(t *testing.T) {
+		bus := NewBus()
+		var failures []error
+		bus.onError = func(e domain.Event, err error) {
+			failures = append(failures, err)
+		}
+		delivered := false
+		bus.Subscribe(AllEvents, func(ctx context.Context, e domain.Event) error {
+			return errors.New("boom")
+		})
+		bus.Subscribe(AllEvents, func(ctx context.Context, e domain.Event) error {
+			panic("subscriber panic")
+		})
+		bus.Subscribe(AllEvents, func(ctx context.Context, e domain.Event) error {
+			delivered = true
+			return nil
+		})
+
+		bus.Publish(ctx, domain.BookCreated{})
+
+		if !delivered {
+			t.Error("expected healthy subscriber to receive the event")
+		}
+		if len(failures) != 2 {
+			t.Errorf("expected 2 reported failures, got %v", failures)
+		}
+	})
+
+	t.Run("async subscribers outlive the request context", func(t *testing.T) {
+		bus := NewBus()
+		var mu sync.Mutex
+		var received []domain.Event
+		bus.SubscribeAsync(AllEvents, func(ctx context.Context, e domain.Event) error {
+			time.Sleep(10 * time.Millisecond)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			mu.Lock()
+			received = append(received, e)
+			mu.Unlock()
+			return nil
+		})
+
+		reqCtx, cancel := context.WithCancel(ctx)
+		bus.Publish(reqCtx, domain.BookCreated{})
+		cancel()
+		bus.Wait()
+
+		if len(received) != 1 {
+			t.Errorf("expected 1 async delivery, got %d", len(received))
+		}
+	})
+}
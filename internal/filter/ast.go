@@ -0,0 +1,183 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"solid/internal/domain"
+)
+
+// Expr is a node of a parsed filter. It implements domain.BookFilter.
+type Expr interface {
+	// Match reports whether book satisfies the expression. Comparisons with
+	// an unset nullable field are unknown, as in SQL, so neither a
+	// comparison nor its negation matches such a book.
+	Match(book *domain.Book) bool
+	String() string
+	eval(book *domain.Book) truth
+}
+
+type Operator string
+
+const (
+	Eq         Operator = "eq"
+	Ne         Operator = "ne"
+	Gt         Operator = "gt"
+	Ge         Operator = "ge"
+	Lt         Operator = "lt"
+	Le         Operator = "le"
+	Contains   Operator = "contains"
+	StartsWith Operator = "startswith"
+	EndsWith   Operator = "endswith"
+)
+
+var operators = []Operator{Eq, Ne, Gt, Ge, Lt, Le, Contains, StartsWith, EndsWith}
+
+type LogicalOp string
+
+const (
+	And LogicalOp = "and"
+	Or  LogicalOp = "or"
+)
+
+// Logical joins two expressions with and/or.
+type Logical struct {
+	Op          LogicalOp
+	Left, Right Expr
+}
+
+// Not negates an expression.
+type Not struct {
+	X Expr
+}
+
+// Comparison compares a book field with a literal.
+type Comparison struct {
+	Field *Field
+	Op    Operator
+	Value Value
+}
+
+// Value is a type-checked literal: a string for string fields, a time for
+// date fields, or null.
+type Value struct {
+	Type Type
+	Text string
+	Time time.Time
+}
+
+func (v Value) literal() string {
+	switch v.Type {
+	case TypeString:
+		return "'" + strings.ReplaceAll(v.Text, "'", "''") + "'"
+	case TypeTime:
+		return v.Time.Format(time.RFC3339Nano)
+	}
+	return "null"
+}
+
+func (e *Logical) Match(book *domain.Book) bool    { return e.eval(book) == truthTrue }
+func (e *Not) Match(book *domain.Book) bool        { return e.eval(book) == truthTrue }
+func (e *Comparison) Match(book *domain.Book) bool { return e.eval(book) == truthTrue }
+
+func (e *Logical) String() string {
+	return fmt.Sprintf("(%s %s %s)", e.Left, e.Op, e.Right)
+}
+
+func (e *Not) String() string {
+	return fmt.Sprintf("(not %s)", e.X)
+}
+
+func (e *Comparison) String() string {
+	return fmt.Sprintf("%s %s %s", e.Field.Name, e.Op, e.Value.literal())
+}
+
+// truth is a value of SQL's three-valued logic.
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+func (e *Logical) eval(book *domain.Book) truth {
+	left, right := e.Left.eval(book), e.Right.eval(book)
+	if e.Op == And {
+		switch {
+		case left == truthFalse || right == truthFalse:
+			return truthFalse
+		case left == truthTrue && right == truthTrue:
+			return truthTrue
+		}
+		return truthUnknown
+	}
+	switch {
+	case left == truthTrue || right == truthTrue:
+		return truthTrue
+	case left == truthFalse && right == truthFalse:
+		return truthFalse
+	}
+	return truthUnknown
+}
+
+func (e *Not) eval(book *domain.Book) truth {
+	switch x := e.X.eval(book); x {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	default:
+		return x
+	}
+}
+
+func (e *Comparison) eval(book *domain.Book) truth {
+	actual := e.Field.value(book)
+	if e.Value.Type == TypeNull {
+		return truthOf((actual == nil) == (e.Op == Eq))
+	}
+	if actual == nil {
+		return truthUnknown
+	}
+
+	var cmp int
+	switch actual := actual.(type) {
+	case string:
+		switch e.Op {
+		case Contains:
+			return truthOf(strings.Contains(actual, e.Value.Text))
+		case StartsWith:
+			return truthOf(strings.HasPrefix(actual, e.Value.Text))
+		case EndsWith:
+			return truthOf(strings.HasSuffix(actual, e.Value.Text))
+		}
+		cmp = strings.Compare(actual, e.Value.Text)
+	case time.Time:
+		cmp = actual.Compare(e.Value.Time)
+	}
+
+	switch e.Op {
+	case Eq:
+		return truthOf(cmp == 0)
+	case Ne:
+		return truthOf(cmp != 0)
+	case Gt:
+		return truthOf(cmp > 0)
+	case Ge:
+		return truthOf(cmp >= 0)
+	case Lt:
+		return truthOf(cmp < 0)
+	case Le:
+		return truthOf(cmp <= 0)
+	}
+	return truthFalse
+}
@@ -0,0 +1,127 @@
+// Package filter implements the expression language accepted by the filter
+// parameter of GET /books, for example
+//
+//	author eq 'Robert Martin' and created_at gt 2024-01-01 or title contains 'Go'
+//
+// Expressions are parsed into an AST that is type-checked against the fields
+// of domain.Book. The AST can be evaluated against a book in memory or
+// translated to a SQL WHERE clause.
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"solid/internal/domain"
+)
+
+// Error is a syntax or type error in a filter. Position is the 1-based
+// character offset where the problem starts.
+type Error struct {
+	Position int
+	Message  string
+}
+
+func newError(input string, offset int, format string, args ...interface{}) *Error {
+	return &Error{
+		Position: utf8.RuneCountInString(input[:offset]) + 1,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeTime
+	TypeNumber
+	TypeBool
+	TypeNull
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeTime:
+		return "date"
+	case TypeNumber:
+		return "number"
+	case TypeBool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// Field is a filterable field of domain.Book, named after its json tag.
+type Field struct {
+	Name     string
+	Type     Type
+	Nullable bool
+	index    []int
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	fields   = bookFields()
+)
+
+func bookFields() map[string]*Field {
+	typ := reflect.TypeOf(domain.Book{})
+	out := make(map[string]*Field, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		field := &Field{Name: name, index: structField.Index}
+		fieldType := structField.Type
+		if fieldType.Kind() == reflect.Pointer {
+			field.Nullable = true
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case fieldType == timeType:
+			field.Type = TypeTime
+		case fieldType.Kind() == reflect.String:
+			field.Type = TypeString
+		default:
+			continue
+		}
+		out[name] = field
+	}
+	return out
+}
+
+// Fields returns the names of the fields a filter can refer to.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// value returns the field of book as a string or time.Time, or nil when a
+// nullable field is not set.
+func (f *Field) value(book *domain.Book) interface{} {
+	v := reflect.ValueOf(book).Elem().FieldByIndex(f.index)
+	if f.Nullable {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
@@ -0,0 +1,131 @@
+package filter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+	"solid/internal/filter"
+)
+
+func books() []*domain.Book {
+	deleted := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	return []*domain.Book{
+		{ID: "1", Title: "Clean Code", Author: "Robert Martin", CreatedAt: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "2", Title: "Clean Architecture", Author: "Robert Martin", CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "3", Title: "The Go Programming Language", Author: "Alan Donovan", CreatedAt: time.Date(2015, 10, 26, 0, 0, 0, 0, time.UTC)},
+		{ID: "4", Title: "Refactoring", Author: "Martin Fowler", CreatedAt: time.Date(2018, 11, 20, 0, 0, 0, 0, time.UTC), DeletedAt: &deleted},
+	}
+}
+
+func matching(t *testing.T, input string) []string {
+	t.Helper()
+	expr, err := filter.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", input, err)
+	}
+	ids := []string{}
+	for _, book := range books() {
+		if expr.Match(book) {
+			ids = append(ids, book.ID)
+		}
+	}
+	return ids
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{`author eq 'Robert Martin' and created_at gt 2024-01-01 or title contains 'Go'`, []string{"2", "3"}},
+		{`author eq 'Robert Martin' and (created_at gt 2024-01-01 or title contains 'Go')`, []string{"2"}},
+		{`title startswith 'Clean' and not title endswith 'Code'`, []string{"2"}},
+		{`author NE 'Robert Martin'`, []string{"3", "4"}},
+		{`created_at ge 2015-10-26 and created_at lt '2018-11-20T00:00:00Z'`, []string{"3"}},
+		{`created_at le 2015-10-26T00:00:00Z`, []string{"3"}},
+		{`deleted_at eq null`, []string{"1", "2", "3"}},
+		{`deleted_at ne null`, []string{"4"}},
+		{`title eq 'It''s'`, []string{}},
+	}
+	for _, tt := range tests {
+		got := matching(t, tt.filter)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s matched %v, want %v", tt.filter, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s matched %v, want %v", tt.filter, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestMatchNullIsUnknown(t *testing.T) {
+	// Neither a comparison with an unset field nor its negation matches.
+	if got := matching(t, `deleted_at gt 2020-01-01`); len(got) != 1 || got[0] != "4" {
+		t.Errorf("deleted_at gt 2020-01-01 matched %v, want [4]", got)
+	}
+	if got := matching(t, `not deleted_at gt 2020-01-01`); len(got) != 0 {
+		t.Errorf("not deleted_at gt 2020-01-01 matched %v, want []", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	expr, err := filter.Parse(`not (title eq 'It''s' or author eq 'x') and created_at gt 2024-01-01`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `((not (title eq 'It''s' or author eq 'x')) and created_at gt 2024-01-01T00:00:00Z)`
+	if got := expr.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		filter   string
+		position int
+		message  string
+	}{
+		{``, 1, "empty filter"},
+		{`rating eq '5'`, 1, `unknown field "rating"; expected one of author, created_at, deleted_at, id, isbn, title, updated_at`},
+		{`title is 'Go'`, 7, `expected an operator after title, got "is"`},
+		{`title eq Go`, 10, `expected a value, got "Go"; strings are quoted like 'Go'`},
+		{`title eq 'Go`, 10, "unterminated string"},
+		{`title eq 'Go' and`, 18, "expected a field name, got end of filter"},
+		{`title eq 'Go' author eq 'x'`, 15, `expected and, or or end of filter, got "author"`},
+		{`(title eq 'Go'`, 15, "expected ) to close the ( at position 1, got end of filter"},
+		{`title eq 'Gö' and title eq 5`, 28, "cannot compare string field title with number \"5\""},
+		{`created_at gt 'soon'`, 15, "cannot compare date field created_at with string 'soon'"},
+		{`created_at gt 2024-13-01`, 15, `invalid literal "2024-13-01"; dates are written 2024-01-31 or 2024-01-31T09:30:00Z`},
+		{`created_at contains '2024'`, 12, "contains needs a string field, but created_at is a date"},
+		{`deleted_at gt null`, 12, "null can only be compared with eq or ne"},
+		{`title eq null`, 10, "title is never null"},
+		{`title eq 'Go' & author eq 'x'`, 15, `unexpected character '&'`},
+	}
+	for _, tt := range tests {
+		_, err := filter.Parse(tt.filter)
+		var parseErr *filter.Error
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) error = %v, want *filter.Error", tt.filter, err)
+			continue
+		}
+		if parseErr.Position != tt.position || parseErr.Message != tt.message {
+			t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.filter, parseErr.Message, parseErr.Position, tt.message, tt.position)
+		}
+	}
+}
+
+func TestParseNestingLimit(t *testing.T) {
+	input := ""
+	for i := 0; i < 100; i++ {
+		input += "not "
+	}
+	if _, err := filter.Parse(input + "title eq 'Go'"); err == nil {
+		t.Error("Parse() accepted 100 nested nots")
+	}
+}
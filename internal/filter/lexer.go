@@ -0,0 +1,119 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenLiteral
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// offset is the byte offset of the token in the input.
+	offset int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of filter"
+	case tokenString:
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(t.text, "'", "''"))
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type lexer struct {
+	input  string
+	offset int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.offset < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.offset:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.offset += size
+	}
+	if l.offset >= len(l.input) {
+		return token{kind: tokenEOF, offset: l.offset}, nil
+	}
+
+	start := l.offset
+	r, size := utf8.DecodeRuneInString(l.input[start:])
+	switch {
+	case r == '(':
+		l.offset += size
+		return token{kind: tokenLParen, text: "(", offset: start}, nil
+	case r == ')':
+		l.offset += size
+		return token{kind: tokenRParen, text: ")", offset: start}, nil
+	case r == '\'':
+		return l.quoted(start)
+	case unicode.IsLetter(r) || r == '_':
+		return token{kind: tokenIdent, text: l.run(isIdentRune), offset: start}, nil
+	case unicode.IsDigit(r) || r == '-' || r == '+':
+		return token{kind: tokenLiteral, text: l.run(isLiteralRune), offset: start}, nil
+	}
+	return token{}, l.errorAt(start, "unexpected character %q", r)
+}
+
+// quoted reads a single-quoted string, in which ” stands for a quote.
+func (l *lexer) quoted(start int) (token, error) {
+	var text strings.Builder
+	l.offset++
+	for l.offset < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.offset:])
+		l.offset += size
+		if r != '\'' {
+			text.WriteRune(r)
+			continue
+		}
+		if strings.HasPrefix(l.input[l.offset:], "'") {
+			text.WriteRune('\'')
+			l.offset++
+			continue
+		}
+		return token{kind: tokenString, text: text.String(), offset: start}, nil
+	}
+	return token{}, l.errorAt(start, "unterminated string")
+}
+
+func (l *lexer) run(accept func(rune) bool) string {
+	start := l.offset
+	for l.offset < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.offset:])
+		if !accept(r) {
+			break
+		}
+		l.offset += size
+	}
+	return l.input[start:l.offset]
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// isLiteralRune accepts the characters of numbers, dates and RFC 3339
+// timestamps such as 2024-01-01T09:30:00.5+02:00.
+func isLiteralRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-+:._", r)
+}
+
+func (l *lexer) errorAt(offset int, format string, args ...interface{}) *Error {
+	return newError(l.input, offset, format, args...)
+}
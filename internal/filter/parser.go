@@ -0,0 +1,245 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDepth bounds the nesting of parentheses and not.
+const maxDepth = 32
+
+// Parse parses a filter and type-checks it against the fields of
+// domain.Book. Keywords and operators are case-insensitive; and binds
+// tighter than or.
+//
+//	filter     = or
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = field operator value
+//	operator   = eq | ne | gt | ge | lt | le | contains | startswith | endswith
+//	value      = 'string' | date | timestamp | null
+func Parse(input string) (Expr, error) {
+	p := &parser{lexer: lexer{input: input}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenEOF {
+		return nil, p.errorAt(p.tok, "empty filter")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, p.errorAt(p.tok, "expected and, or or end of filter, got %s", p.tok)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	lexer lexer
+	tok   token
+	depth int
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) keyword(word string) bool {
+	return p.tok.kind == tokenIdent && strings.EqualFold(p.tok.text, word)
+}
+
+func (p *parser) errorAt(tok token, format string, args ...interface{}) *Error {
+	return newError(p.lexer.input, tok.offset, format, args...)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword(string(Or)) {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: Or, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword(string(And)) {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: And, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if !p.keyword("not") && p.tok.kind != tokenLParen {
+		return p.parseComparison()
+	}
+
+	if p.depth++; p.depth > maxDepth {
+		return nil, p.errorAt(p.tok, "filter is nested more than %d levels deep", maxDepth)
+	}
+	defer func() { p.depth-- }()
+
+	open := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if open.kind == tokenIdent {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenRParen {
+		return nil, p.errorAt(p.tok, "expected ) to close the ( at position %d, got %s",
+			newError(p.lexer.input, open.offset, "").Position, p.tok)
+	}
+	return expr, p.advance()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	fieldTok := p.tok
+	if fieldTok.kind != tokenIdent {
+		return nil, p.errorAt(fieldTok, "expected a field name, got %s", fieldTok)
+	}
+	field, ok := fields[fieldTok.text]
+	if !ok {
+		return nil, p.errorAt(fieldTok, "unknown field %s; expected one of %s", fieldTok, strings.Join(Fields(), ", "))
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	opTok := p.tok
+	op, ok := parseOperator(opTok)
+	if !ok {
+		return nil, p.errorAt(opTok, "expected an operator after %s, got %s", field.Name, opTok)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	valueTok := p.tok
+	value, err := p.parseValue(valueTok)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{Field: field, Op: op, Value: value}
+	return comparison, p.check(comparison, opTok, valueTok)
+}
+
+func parseOperator(tok token) (Operator, bool) {
+	if tok.kind != tokenIdent {
+		return "", false
+	}
+	for _, op := range operators {
+		if strings.EqualFold(tok.text, string(op)) {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseValue(tok token) (Value, error) {
+	switch tok.kind {
+	case tokenString:
+		return Value{Type: TypeString, Text: tok.text}, nil
+	case tokenLiteral:
+		if t, ok := parseTime(tok.text); ok {
+			return Value{Type: TypeTime, Time: t}, nil
+		}
+		if _, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			return Value{Type: TypeNumber, Text: tok.text}, nil
+		}
+		return Value{}, p.errorAt(tok, "invalid literal %s; dates are written 2024-01-31 or 2024-01-31T09:30:00Z", tok)
+	case tokenIdent:
+		switch strings.ToLower(tok.text) {
+		case "null":
+			return Value{Type: TypeNull}, nil
+		case "true", "false":
+			return Value{Type: TypeBool, Text: strings.ToLower(tok.text)}, nil
+		}
+		return Value{}, p.errorAt(tok, "expected a value, got %s; strings are quoted like 'Go'", tok)
+	}
+	return Value{}, p.errorAt(tok, "expected a value, got %s", tok)
+}
+
+func parseTime(text string) (time.Time, bool) {
+	if t, err := time.Parse(time.DateOnly, text); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// check type-checks a comparison. Quoted dates are accepted for date
+// fields.
+func (p *parser) check(c *Comparison, opTok, valueTok token) error {
+	field := c.Field
+	switch c.Op {
+	case Contains, StartsWith, EndsWith:
+		if field.Type != TypeString {
+			return p.errorAt(opTok, "%s needs a string field, but %s is a %s", c.Op, field.Name, field.Type)
+		}
+	}
+
+	if c.Value.Type == TypeNull {
+		if c.Op != Eq && c.Op != Ne {
+			return p.errorAt(opTok, "null can only be compared with eq or ne")
+		}
+		if !field.Nullable {
+			return p.errorAt(valueTok, "%s is never null", field.Name)
+		}
+		return nil
+	}
+
+	if field.Type == TypeTime && c.Value.Type == TypeString {
+		if t, ok := parseTime(c.Value.Text); ok {
+			c.Value = Value{Type: TypeTime, Time: t}
+		}
+	}
+	if c.Value.Type != field.Type {
+		return p.errorAt(valueTok, "cannot compare %s field %s with %s %s", field.Type, field.Name, c.Value.Type, valueTok)
+	}
+	return nil
+}
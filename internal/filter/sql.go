@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Placeholder renders the n-th (1-based) bind parameter of a query.
+type Placeholder func(n int) string
+
+// QuestionMark renders ? placeholders, as used by MySQL and SQLite.
+func QuestionMark(int) string { return "?" }
+
+// Dollar renders $1, $2, ... placeholders, as used by PostgreSQL.
+func Dollar(n int) string { return fmt.Sprintf("$%d", n) }
+
+// ToSQL translates expr to a SQL WHERE clause over columns named after the
+// book's json fields, with literals passed as bind arguments. Comparisons
+// with NULL columns follow the same three-valued logic as Match.
+func ToSQL(expr Expr, placeholder Placeholder) (string, []interface{}) {
+	w := &sqlWriter{placeholder: placeholder}
+	w.write(expr)
+	return w.buf.String(), w.args
+}
+
+type sqlWriter struct {
+	buf         strings.Builder
+	args        []interface{}
+	placeholder Placeholder
+}
+
+var sqlOperators = map[Operator]string{
+	Eq: "=", Ne: "<>", Gt: ">", Ge: ">=", Lt: "<", Le: "<=",
+}
+
+func (w *sqlWriter) write(expr Expr) {
+	switch e := expr.(type) {
+	case *Logical:
+		w.operand(e.Left, e.Op)
+		w.buf.WriteString(" " + strings.ToUpper(string(e.Op)) + " ")
+		w.operand(e.Right, e.Op)
+	case *Not:
+		w.buf.WriteString("NOT (")
+		w.write(e.X)
+		w.buf.WriteString(")")
+	case *Comparison:
+		w.comparison(e)
+	}
+}
+
+// operand writes a side of a logical expression, in parentheses when it is
+// a logical expression with a different operator.
+func (w *sqlWriter) operand(expr Expr, parent LogicalOp) {
+	if e, ok := expr.(*Logical); ok && e.Op != parent {
+		w.buf.WriteString("(")
+		w.write(e)
+		w.buf.WriteString(")")
+		return
+	}
+	w.write(expr)
+}
+
+func (w *sqlWriter) comparison(e *Comparison) {
+	column := e.Field.Name
+	if e.Value.Type == TypeNull {
+		if e.Op == Eq {
+			w.buf.WriteString(column + " IS NULL")
+		} else {
+			w.buf.WriteString(column + " IS NOT NULL")
+		}
+		return
+	}
+
+	switch e.Op {
+	case Contains:
+		w.like(column, "%"+escapeLike(e.Value.Text)+"%")
+	case StartsWith:
+		w.like(column, escapeLike(e.Value.Text)+"%")
+	case EndsWith:
+		w.like(column, "%"+escapeLike(e.Value.Text))
+	default:
+		w.buf.WriteString(column + " " + sqlOperators[e.Op] + " " + w.bind(e.Value))
+	}
+}
+
+func (w *sqlWriter) like(column, pattern string) {
+	w.buf.WriteString(column + " LIKE " + w.bind(Value{Type: TypeString, Text: pattern}) + ` ESCAPE '\'`)
+}
+
+func (w *sqlWriter) bind(value Value) string {
+	if value.Type == TypeTime {
+		w.args = append(w.args, value.Time)
+	} else {
+		w.args = append(w.args, value.Text)
+	}
+	return w.placeholder(len(w.args))
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
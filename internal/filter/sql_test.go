@@ -0,0 +1,57 @@
+package filter_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"solid/internal/filter"
+)
+
+func TestToSQL(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		filter      string
+		placeholder filter.Placeholder
+		want        string
+		args        []interface{}
+	}{
+		{
+			`author eq 'Robert Martin' and created_at gt 2024-01-01 or title contains 'Go'`,
+			filter.Dollar,
+			`(author = $1 AND created_at > $2) OR title LIKE $3 ESCAPE '\'`,
+			[]interface{}{"Robert Martin", since, "%Go%"},
+		},
+		{
+			`author eq 'x' and (title startswith '50%' or title endswith 'a_b')`,
+			filter.QuestionMark,
+			`author = ? AND (title LIKE ? ESCAPE '\' OR title LIKE ? ESCAPE '\')`,
+			[]interface{}{"x", `50\%%`, `%a\_b`},
+		},
+		{
+			`not deleted_at eq null and title ne 'x' and isbn ge '1'`,
+			filter.Dollar,
+			`NOT (deleted_at IS NULL) AND title <> $1 AND isbn >= $2`,
+			[]interface{}{"x", "1"},
+		},
+		{
+			`deleted_at ne null or updated_at le 2024-01-01 or updated_at lt 2024-01-01`,
+			filter.Dollar,
+			`deleted_at IS NOT NULL OR updated_at <= $1 OR updated_at < $2`,
+			[]interface{}{since, since},
+		},
+	}
+	for _, tt := range tests {
+		expr, err := filter.Parse(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		where, args := filter.ToSQL(expr, tt.placeholder)
+		if where != tt.want {
+			t.Errorf("ToSQL(%s) = %s, want %s", tt.filter, where, tt.want)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("ToSQL(%s) args = %v, want %v", tt.filter, args, tt.args)
+		}
+	}
+}
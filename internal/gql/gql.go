@@ -0,0 +1,30 @@
+package gql
+
+import (
+	"solid/internal/service"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type Config struct {
+	MaxDepth       int
+	MaxComplexity  int
+	MaxQueryLength int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxDepth:       8,
+		MaxComplexity:  1000,
+		MaxQueryLength: 10000,
+	}
+}
+
+func NewSchema(service *service.BookService, config Config) (*graphql.Schema, error) {
+	return graphql.ParseSchema(
+		Schema,
+		NewResolver(service),
+		graphql.MaxDepth(config.MaxDepth),
+		graphql.MaxQueryLength(config.MaxQueryLength),
+	)
+}
@@ -0,0 +1,222 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"solid/internal/domain"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const nestedListWeight = 10
+
+var nestedListFields = map[string]bool{
+	"history": true,
+	"merges":  true,
+}
+
+// pagedFields are the fields whose cost is multiplied by their "first"
+// argument, with the default the schema gives it.
+var pagedFields = map[string]int{
+	"books": 20,
+}
+
+// Exec runs the operation once its complexity fits limit. The cost of the
+// whole operation is computed from the document before any resolver runs,
+// so a document that is too complex has no side effects, not even those of
+// its first mutations. A limit of zero or less disables the check.
+func Exec(ctx context.Context, schema *graphql.Schema, limit int, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	if limit <= 0 {
+		return schema.Exec(ctx, query, operationName, variables)
+	}
+
+	cost, err := complexity(query, operationName, variables)
+	if err != nil {
+		// Let the schema report documents it rejects; anything it accepts
+		// but we cannot price is refused rather than run unchecked.
+		if errs := schema.ValidateWithVariables(query, variables); len(errs) > 0 {
+			return &graphql.Response{Errors: errs}
+		}
+		return errorResponse(domain.ErrInvalidInput.WithMessage("query could not be analyzed: " + err.Error()))
+	}
+	if cost > limit {
+		return errorResponse(domain.ErrQueryTooComplex.WithMessage(fmt.Sprintf("query complexity %d exceeds limit of %d", cost, limit)))
+	}
+	return schema.Exec(ctx, query, operationName, variables)
+}
+
+func errorResponse(err *domain.DomainError) *graphql.Response {
+	resolverErr := &resolverError{err: err}
+	return &graphql.Response{Errors: []*gqlerrors.QueryError{{
+		Message:       err.Error(),
+		Extensions:    resolverErr.Extensions(),
+		ResolverError: resolverErr,
+	}}}
+}
+
+// complexity prices the operation that would run: every field selected
+// under a root field costs 1, or 10 below history or merges, with a minimum
+// of 1 per root field, and paged root fields multiply that by first.
+func complexity(query, operationName string, variables map[string]interface{}) (int, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return 0, err
+	}
+	op, err := doc.operation(operationName)
+	if err != nil {
+		return 0, err
+	}
+
+	roots, err := doc.flatten(op.selections, make(map[string]bool))
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, root := range roots {
+		if strings.HasPrefix(root.name, "__") {
+			continue
+		}
+
+		paths := make(map[string]bool)
+		if err := doc.collect(root.selections, "", paths, make(map[string]bool)); err != nil {
+			return 0, err
+		}
+		cost := 0
+		for path := range paths {
+			cost += pathWeight(path)
+		}
+		if cost == 0 {
+			cost = 1
+		}
+
+		if def, paged := pagedFields[root.name]; paged {
+			first := def
+			if arg, ok := root.args["first"]; ok {
+				if n, ok := op.intValue(arg, variables); ok {
+					first = n
+				}
+			}
+			// Out-of-range values are rejected by the resolver.
+			if first > 1 && first <= maxPageSize {
+				cost *= first
+			}
+		}
+		total += cost
+	}
+	return total, nil
+}
+
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) != 1 {
+			return nil, fmt.Errorf("operation name is required for %d operations", len(d.operations))
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// flatten resolves fragment spreads and inline fragments into the fields
+// they select.
+func (d *document) flatten(selections []*selection, visiting map[string]bool) ([]*selection, error) {
+	fields := make([]*selection, 0, len(selections))
+	for _, sel := range selections {
+		switch {
+		case sel.spread != "":
+			frag, ok := d.fragments[sel.spread]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %q", sel.spread)
+			}
+			if visiting[sel.spread] {
+				return nil, fmt.Errorf("fragment %q spreads itself", sel.spread)
+			}
+			visiting[sel.spread] = true
+			spread, err := d.flatten(frag.selections, visiting)
+			delete(visiting, sel.spread)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, spread...)
+		case sel.name == "":
+			inline, err := d.flatten(sel.selections, visiting)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, inline...)
+		default:
+			fields = append(fields, sel)
+		}
+	}
+	return fields, nil
+}
+
+// collect adds the dotted path of every field selected below a root field,
+// the way graphql.SelectedFieldNames reports them: by field name, without
+// meta fields and without duplicates.
+func (d *document) collect(selections []*selection, prefix string, paths map[string]bool, visiting map[string]bool) error {
+	fields, err := d.flatten(selections, visiting)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if strings.HasPrefix(field.name, "__") {
+			continue
+		}
+		path := prefix + field.name
+		paths[path] = true
+		if err := d.collect(field.selections, path+".", paths, visiting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pathWeight(path string) int {
+	for _, field := range strings.Split(path, ".") {
+		if nestedListFields[field] {
+			return nestedListWeight
+		}
+	}
+	return 1
+}
+
+type resolverError struct {
+	err *domain.DomainError
+}
+
+func (e *resolverError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resolverError) Unwrap() error {
+	return e.err
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code":   e.err.Code,
+		"status": e.err.StatusCode,
+	}
+	if e.err.Details != nil {
+		extensions["details"] = e.err.Details
+	}
+	return extensions
+}
+
+func wrapError(err error) error {
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		return &resolverError{err: domainErr}
+	}
+	return err
+}
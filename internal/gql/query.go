@@ -0,0 +1,554 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// query.go parses just enough of a GraphQL executable document to price it
+// before execution: graphql-go keeps its own parser internal. Values are
+// kept only where the cost needs them (scalar literals and variables).
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+type lexer struct {
+	src string
+	pos int
+	tok token
+}
+
+func (l *lexer) next() error {
+	l.skipIgnored()
+	if l.pos >= len(l.src) {
+		l.tok = token{kind: tokenEOF}
+		return nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		l.tok = token{kind: tokenPunct, value: "..."}
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		l.pos++
+		l.tok = token{kind: tokenPunct, value: string(c)}
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		l.tok = token{kind: tokenName, value: l.src[start:l.pos]}
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		return l.string()
+	default:
+		return fmt.Errorf("unexpected character %q at offset %d", c, start)
+	}
+	return nil
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number() error {
+	start := l.pos
+	kind := tokenInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if !l.digits() {
+		return fmt.Errorf("invalid number at offset %d", start)
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if !l.digits() {
+			return fmt.Errorf("invalid number at offset %d", start)
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.digits() {
+			return fmt.Errorf("invalid number at offset %d", start)
+		}
+	}
+	l.tok = token{kind: kind, value: l.src[start:l.pos]}
+	return nil
+}
+
+func (l *lexer) digits() bool {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	return l.pos > start
+}
+
+// string skips a string or block string; its contents never affect cost.
+func (l *lexer) string() error {
+	start := l.pos
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		l.pos += 3
+		for l.pos < len(l.src) {
+			switch {
+			case strings.HasPrefix(l.src[l.pos:], `\"""`):
+				l.pos += 4
+			case strings.HasPrefix(l.src[l.pos:], `"""`):
+				l.pos += 3
+				l.tok = token{kind: tokenString}
+				return nil
+			default:
+				l.pos++
+			}
+		}
+		return fmt.Errorf("unterminated string at offset %d", start)
+	}
+
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+		case '"':
+			l.pos++
+			l.tok = token{kind: tokenString}
+			return nil
+		case '\n', '\r':
+			return fmt.Errorf("unterminated string at offset %d", start)
+		default:
+			l.pos++
+		}
+	}
+	return fmt.Errorf("unterminated string at offset %d", start)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	name       string
+	defaults   map[string]argValue
+	selections []*selection
+}
+
+type fragment struct {
+	selections []*selection
+}
+
+// selection is a field, a fragment spread (spread set) or an inline
+// fragment (only selections set).
+type selection struct {
+	name       string
+	args       map[string]argValue
+	spread     string
+	selections []*selection
+}
+
+// argValue is a scalar literal or a variable reference; lists and objects
+// are parsed but not kept.
+type argValue struct {
+	variable string
+	kind     tokenKind
+	literal  string
+}
+
+type parser struct {
+	lex lexer
+}
+
+func parseDocument(src string) (*document, error) {
+	p := &parser{lex: lexer{src: src}}
+	if err := p.lex.next(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.lex.tok.kind != tokenEOF {
+		if p.peekName("fragment") {
+			name, frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = frag
+			continue
+		}
+		op, err := p.operation()
+		if err != nil {
+			return nil, err
+		}
+		doc.operations = append(doc.operations, op)
+	}
+	return doc, nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{defaults: make(map[string]argValue)}
+	if p.peekPunct("{") {
+		selections, err := p.selectionSet()
+		op.selections = selections
+		return op, err
+	}
+
+	kind, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if kind != "query" && kind != "mutation" && kind != "subscription" {
+		return nil, fmt.Errorf("unexpected %q, expected an operation", kind)
+	}
+	if p.lex.tok.kind == tokenName {
+		op.name = p.lex.tok.value
+		if err := p.lex.next(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekPunct("(") {
+		if err := p.variableDefinitions(op); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	op.selections, err = p.selectionSet()
+	return op, err
+}
+
+func (p *parser) variableDefinitions(op *operation) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.peekPunct(")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if p.peekPunct("=") {
+			if err := p.lex.next(); err != nil {
+				return err
+			}
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			op.defaults[name] = v
+		}
+		if err := p.directives(); err != nil {
+			return err
+		}
+	}
+	return p.expect(")")
+}
+
+func (p *parser) typeRef() error {
+	if p.peekPunct("[") {
+		if err := p.lex.next(); err != nil {
+			return err
+		}
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.peekPunct("!") {
+		return p.lex.next()
+	}
+	return nil
+}
+
+func (p *parser) fragment() (string, *fragment, error) {
+	if err := p.lex.next(); err != nil {
+		return "", nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return "", nil, err
+	}
+	if !p.peekName("on") {
+		return "", nil, fmt.Errorf("expected type condition for fragment %s", name)
+	}
+	if err := p.lex.next(); err != nil {
+		return "", nil, err
+	}
+	if _, err := p.name(); err != nil {
+		return "", nil, err
+	}
+	if err := p.directives(); err != nil {
+		return "", nil, err
+	}
+	selections, err := p.selectionSet()
+	return name, &fragment{selections: selections}, err
+}
+
+func (p *parser) selectionSet() ([]*selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	selections := make([]*selection, 0)
+	for !p.peekPunct("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	return selections, p.expect("}")
+}
+
+func (p *parser) selection() (*selection, error) {
+	if p.peekPunct("...") {
+		return p.fragmentSelection()
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.peekPunct(":") {
+		if err := p.lex.next(); err != nil {
+			return nil, err
+		}
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	sel := &selection{name: name, args: make(map[string]argValue)}
+	if p.peekPunct("(") {
+		if sel.args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peekPunct("{") {
+		if sel.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+func (p *parser) fragmentSelection() (*selection, error) {
+	if err := p.lex.next(); err != nil {
+		return nil, err
+	}
+	if p.lex.tok.kind == tokenName && p.lex.tok.value != "on" {
+		spread := p.lex.tok.value
+		if err := p.lex.next(); err != nil {
+			return nil, err
+		}
+		return &selection{spread: spread}, p.directives()
+	}
+
+	if p.peekName("on") {
+		if err := p.lex.next(); err != nil {
+			return nil, err
+		}
+		if _, err := p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	return &selection{selections: selections}, err
+}
+
+func (p *parser) arguments() (map[string]argValue, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := make(map[string]argValue)
+	for !p.peekPunct(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.expect(")")
+}
+
+func (p *parser) directives() error {
+	for p.peekPunct("@") {
+		if err := p.lex.next(); err != nil {
+			return err
+		}
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.peekPunct("(") {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) value() (argValue, error) {
+	tok := p.lex.tok
+	switch {
+	case tok.kind == tokenPunct && tok.value == "$":
+		if err := p.lex.next(); err != nil {
+			return argValue{}, err
+		}
+		name, err := p.name()
+		return argValue{variable: name}, err
+	case tok.kind == tokenPunct && tok.value == "[":
+		return argValue{}, p.skipComposite("[", "]")
+	case tok.kind == tokenPunct && tok.value == "{":
+		return argValue{}, p.skipComposite("{", "}")
+	case tok.kind == tokenPunct || tok.kind == tokenEOF:
+		return argValue{}, fmt.Errorf("unexpected %q, expected a value", tok.value)
+	}
+	return argValue{kind: tok.kind, literal: tok.value}, p.lex.next()
+}
+
+// skipComposite skips a list or object value.
+func (p *parser) skipComposite(open, close string) error {
+	if err := p.lex.next(); err != nil {
+		return err
+	}
+	for !p.peekPunct(close) {
+		if p.lex.tok.kind == tokenEOF {
+			return fmt.Errorf("unterminated %s value", open)
+		}
+		if open == "{" {
+			if _, err := p.name(); err != nil {
+				return err
+			}
+			if err := p.expect(":"); err != nil {
+				return err
+			}
+		}
+		if _, err := p.value(); err != nil {
+			return err
+		}
+	}
+	return p.lex.next()
+}
+
+func (p *parser) name() (string, error) {
+	if p.lex.tok.kind != tokenName {
+		return "", fmt.Errorf("unexpected %q, expected a name", p.lex.tok.value)
+	}
+	name := p.lex.tok.value
+	return name, p.lex.next()
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peekPunct(punct) {
+		return fmt.Errorf("unexpected %q, expected %q", p.lex.tok.value, punct)
+	}
+	return p.lex.next()
+}
+
+func (p *parser) peekPunct(punct string) bool {
+	return p.lex.tok.kind == tokenPunct && p.lex.tok.value == punct
+}
+
+func (p *parser) peekName(name string) bool {
+	return p.lex.tok.kind == tokenName && p.lex.tok.value == name
+}
+
+// intValue resolves an Int argument from a literal, the request variables
+// or the variable's default.
+func (op *operation) intValue(v argValue, variables map[string]interface{}) (int, bool) {
+	if v.variable != "" {
+		if raw, ok := variables[v.variable]; ok {
+			return toInt(raw)
+		}
+		if def, ok := op.defaults[v.variable]; ok {
+			return op.intValue(def, variables)
+		}
+		return 0, false
+	}
+	if v.kind != tokenInt {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v.literal)
+	return n, err == nil
+}
+
+func toInt(raw interface{}) (int, bool) {
+	switch n := raw.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), n == float64(int(n))
+	case interface{ Int64() (int64, error) }:
+		i, err := n.Int64()
+		return int(i), err == nil
+	}
+	return 0, false
+}
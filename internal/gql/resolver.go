@@ -0,0 +1,338 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"solid/internal/domain"
+	"solid/internal/service"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const maxPageSize = 100
+
+type Resolver struct {
+	service *service.BookService
+}
+
+func NewResolver(service *service.BookService) *Resolver {
+	return &Resolver{service: service}
+}
+
+func (r *Resolver) Book(ctx context.Context, args struct{ ID graphql.ID }) (*bookResolver, error) {
+	book, err := r.service.GetBook(ctx, string(args.ID))
+	if errors.Is(err, domain.ErrBookNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &bookResolver{book: book, service: r.service}, nil
+}
+
+func (r *Resolver) BookByISBN(ctx context.Context, args struct{ ISBN string }) (*bookResolver, error) {
+	book, err := r.service.GetBookByISBN(ctx, args.ISBN)
+	if errors.Is(err, domain.ErrBookNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &bookResolver{book: book, service: r.service}, nil
+}
+
+type bookFilter struct {
+	Title  *string
+	Author *string
+	ISBN   *string
+}
+
+func (f *bookFilter) Match(book *domain.Book) bool {
+	if f == nil {
+		return true
+	}
+	if f.Title != nil && !containsFold(book.Title, *f.Title) {
+		return false
+	}
+	if f.Author != nil && !containsFold(book.Author, *f.Author) {
+		return false
+	}
+	if f.ISBN != nil && book.ISBN != domain.NormalizeISBN(*f.ISBN) {
+		return false
+	}
+	return true
+}
+
+type booksArgs struct {
+	First  int32
+	After  *string
+	Filter *bookFilter
+}
+
+func (r *Resolver) Books(ctx context.Context, args booksArgs) (*bookConnectionResolver, error) {
+	first := int(args.First)
+	if first < 0 || first > maxPageSize {
+		return nil, wrapError(domain.ErrInvalidInput.WithMessage("first must be between 0 and 100"))
+	}
+
+	// A nil *bookFilter in the interface would not be a nil filter.
+	var filter domain.BookFilter
+	if args.Filter != nil {
+		filter = args.Filter
+	}
+	cursor := ""
+	if args.After != nil {
+		cursor = *args.After
+	}
+
+	// The service pages by creation time and ID, so a cursor stays valid
+	// after its book is deleted or stops matching the filter.
+	limit := first
+	if limit == 0 {
+		limit = 1
+	}
+	page, err := r.service.ListBooksPage(ctx, filter, cursor, limit)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if first == 0 {
+		page.HasMore = len(page.Books) > 0
+		page.Books = nil
+	}
+
+	return &bookConnectionResolver{
+		books:       page.Books,
+		filter:      filter,
+		hasNextPage: page.HasMore,
+		service:     r.service,
+	}, nil
+}
+
+type createBookInput struct {
+	Title  string
+	Author string
+	ISBN   string
+	Force  bool
+}
+
+func (r *Resolver) CreateBook(ctx context.Context, args struct{ Input createBookInput }) (*bookResolver, error) {
+	book, _, err := r.service.CreateBook(ctx, args.Input.Title, args.Input.Author, args.Input.ISBN, service.CreateBookOptions{Force: args.Input.Force})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &bookResolver{book: book, service: r.service}, nil
+}
+
+type updateBookInput struct {
+	Title  *string
+	Author *string
+	ISBN   *string
+}
+
+func (r *Resolver) UpdateBook(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input updateBookInput
+}) (*bookResolver, error) {
+	book, err := r.service.UpdateBook(ctx, string(args.ID), value(args.Input.Title), value(args.Input.Author), value(args.Input.ISBN))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &bookResolver{book: book, service: r.service}, nil
+}
+
+func (r *Resolver) DeleteBook(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	if err := r.service.DeleteBook(ctx, string(args.ID)); err != nil {
+		return false, wrapError(err)
+	}
+	return true, nil
+}
+
+type bookResolver struct {
+	book    *domain.Book
+	service *service.BookService
+}
+
+func (b *bookResolver) ID() graphql.ID {
+	return graphql.ID(b.book.ID)
+}
+
+func (b *bookResolver) Title() string {
+	return b.book.Title
+}
+
+func (b *bookResolver) Author() string {
+	return b.book.Author
+}
+
+func (b *bookResolver) ISBN() string {
+	return b.book.ISBN
+}
+
+func (b *bookResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: b.book.CreatedAt}
+}
+
+func (b *bookResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: b.book.UpdatedAt}
+}
+
+func (b *bookResolver) History(ctx context.Context) ([]*auditEntryResolver, error) {
+	entries, err := b.service.BookHistory(ctx, b.book.ID)
+	if errors.Is(err, domain.ErrBookNotFound) {
+		return []*auditEntryResolver{}, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resolvers := make([]*auditEntryResolver, len(entries))
+	for i, entry := range entries {
+		resolvers[i] = &auditEntryResolver{entry: entry}
+	}
+	return resolvers, nil
+}
+
+func (b *bookResolver) Merges(ctx context.Context) ([]*mergeResolver, error) {
+	merges, err := b.service.ListMerges(ctx, b.book.ID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resolvers := make([]*mergeResolver, len(merges))
+	for i, merge := range merges {
+		resolvers[i] = &mergeResolver{merge: merge}
+	}
+	return resolvers, nil
+}
+
+type bookConnectionResolver struct {
+	books       []*domain.Book
+	filter      domain.BookFilter
+	hasNextPage bool
+	service     *service.BookService
+}
+
+func (c *bookConnectionResolver) Edges() []*bookEdgeResolver {
+	edges := make([]*bookEdgeResolver, len(c.books))
+	for i, book := range c.books {
+		edges[i] = &bookEdgeResolver{book: &bookResolver{book: book, service: c.service}}
+	}
+	return edges
+}
+
+func (c *bookConnectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNextPage: c.hasNextPage}
+	if len(c.books) > 0 {
+		cursor := service.BookCursor(c.books[len(c.books)-1])
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+// TotalCount is computed only when asked for, as it reads every matching
+// book.
+func (c *bookConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	books, err := c.service.FindBooks(ctx, c.filter)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return int32(len(books)), nil
+}
+
+type bookEdgeResolver struct {
+	book *bookResolver
+}
+
+func (e *bookEdgeResolver) Cursor() string {
+	return service.BookCursor(e.book.book)
+}
+
+func (e *bookEdgeResolver) Node() *bookResolver {
+	return e.book
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	return p.endCursor
+}
+
+type auditEntryResolver struct {
+	entry *domain.AuditEntry
+}
+
+func (a *auditEntryResolver) Version() int32 {
+	return int32(a.entry.Version)
+}
+
+func (a *auditEntryResolver) Action() string {
+	return a.entry.Action
+}
+
+func (a *auditEntryResolver) Actor() string {
+	return a.entry.Actor
+}
+
+func (a *auditEntryResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: a.entry.Timestamp}
+}
+
+func (a *auditEntryResolver) Changes() []*fieldChangeResolver {
+	changes := make([]*fieldChangeResolver, len(a.entry.Changes))
+	for i, change := range a.entry.Changes {
+		changes[i] = &fieldChangeResolver{change: change}
+	}
+	return changes
+}
+
+type fieldChangeResolver struct {
+	change domain.FieldChange
+}
+
+func (f *fieldChangeResolver) Field() string {
+	return f.change.Field
+}
+
+func (f *fieldChangeResolver) From() string {
+	return f.change.From
+}
+
+func (f *fieldChangeResolver) To() string {
+	return f.change.To
+}
+
+type mergeResolver struct {
+	merge *domain.Merge
+}
+
+func (m *mergeResolver) SourceID() graphql.ID {
+	return graphql.ID(m.merge.SourceID)
+}
+
+func (m *mergeResolver) TargetID() graphql.ID {
+	return graphql.ID(m.merge.TargetID)
+}
+
+func (m *mergeResolver) MergedAt() graphql.Time {
+	return graphql.Time{Time: m.merge.MergedAt}
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
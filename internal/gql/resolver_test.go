@@ -0,0 +1,260 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"solid/internal/repository"
+	"solid/internal/service"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+func newSchema(t *testing.T, config Config) *graphql.Schema {
+	t.Helper()
+	bookService := service.NewBookService(
+		repository.NewInMemoryBookRepository(),
+		service.WithHistory(repository.NewInMemoryHistoryRepository()),
+	)
+	schema, err := NewSchema(bookService, config)
+	if err != nil {
+		t.Fatalf("unexpected error parsing schema: %v", err)
+	}
+	return schema
+}
+
+func exec(t *testing.T, schema *graphql.Schema, ctx context.Context, query string, variables map[string]interface{}) (map[string]interface{}, *graphql.Response) {
+	t.Helper()
+	resp := schema.Exec(ctx, query, "", variables)
+	var data map[string]interface{}
+	if len(resp.Data) > 0 {
+		json.Unmarshal(resp.Data, &data)
+	}
+	return data, resp
+}
+
+const createMutation = `mutation($input: CreateBookInput!) { createBook(input: $input) { id title } }`
+
+func createBook(t *testing.T, schema *graphql.Schema, title, author, isbn string) string {
+	t.Helper()
+	data, resp := exec(t, schema, context.Background(), createMutation, map[string]interface{}{
+		"input": map[string]interface{}{"title": title, "author": author, "isbn": isbn},
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	return data["createBook"].(map[string]interface{})["id"].(string)
+}
+
+func TestSchema(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("mutations and nested history", func(t *testing.T) {
+		schema := newSchema(t, DefaultConfig())
+		id := createBook(t, schema, "Clean Code", "Robert Martin", "0132350882")
+
+		_, resp := exec(t, schema, ctx, `mutation($id: ID!) { updateBook(id: $id, input: {title: "Clean Coder"}) { title } }`, map[string]interface{}{"id": id})
+		if len(resp.Errors) > 0 {
+			t.Fatalf("unexpected errors: %v", resp.Errors)
+		}
+
+		data, resp := exec(t, schema, ctx, `query($id: ID!) { book(id: $id) { title author history { version action } } }`, map[string]interface{}{"id": id})
+		if len(resp.Errors) > 0 {
+			t.Fatalf("unexpected errors: %v", resp.Errors)
+		}
+		book := data["book"].(map[string]interface{})
+		if book["title"] != "Clean Coder" || book["author"] != "Robert Martin" {
+			t.Errorf("unexpected book: %v", book)
+		}
+		if history := book["history"].([]interface{}); len(history) != 2 {
+			t.Errorf("expected 2 history entries, got %d", len(history))
+		}
+
+		data, _ = exec(t, schema, ctx, `query { bookByISBN(isbn: "013-235-0882") { title } }`, nil)
+		if data["bookByISBN"] == nil {
+			t.Errorf("expected book by isbn, got nil")
+		}
+
+		data, _ = exec(t, schema, ctx, `mutation($id: ID!) { deleteBook(id: $id) }`, map[string]interface{}{"id": id})
+		if data["deleteBook"] != true {
+			t.Errorf("expected deleteBook to return true, got %v", data["deleteBook"])
+		}
+		data, _ = exec(t, schema, ctx, `query($id: ID!) { book(id: $id) { title } }`, map[string]interface{}{"id": id})
+		if data["book"] != nil {
+			t.Errorf("expected deleted book to be null, got %v", data["book"])
+		}
+	})
+
+	t.Run("paginated list with filter", func(t *testing.T) {
+		schema := newSchema(t, DefaultConfig())
+		createBook(t, schema, "Clean Code", "Robert Martin", "0132350882")
+		createBook(t, schema, "Refactoring", "Martin Fowler", "0201485672")
+		createBook(t, schema, "Clean Architecture", "Robert Martin", "0134494164")
+
+		query := `query($after: String) {
+			books(first: 1, after: $after, filter: {author: "robert"}) {
+				totalCount
+				edges { node { title } }
+				pageInfo { hasNextPage endCursor }
+			}
+		}`
+		data, resp := exec(t, schema, ctx, query, nil)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("unexpected errors: %v", resp.Errors)
+		}
+		books := data["books"].(map[string]interface{})
+		pageInfo := books["pageInfo"].(map[string]interface{})
+		if books["totalCount"].(float64) != 2 || pageInfo["hasNextPage"] != true {
+			t.Fatalf("unexpected first page: %v", books)
+		}
+
+		data, _ = exec(t, schema, ctx, query, map[string]interface{}{"after": pageInfo["endCursor"]})
+		books = data["books"].(map[string]interface{})
+		edges := books["edges"].([]interface{})
+		title := edges[0].(map[string]interface{})["node"].(map[string]interface{})["title"]
+		if len(edges) != 1 || title != "Clean Architecture" || books["pageInfo"].(map[string]interface{})["hasNextPage"] != false {
+			t.Errorf("unexpected second page: %v", books)
+		}
+	})
+
+	t.Run("cursor survives deleting its book", func(t *testing.T) {
+		schema := newSchema(t, DefaultConfig())
+		createBook(t, schema, "Clean Code", "Robert Martin", "0132350882")
+		createBook(t, schema, "Refactoring", "Martin Fowler", "0201485672")
+		createBook(t, schema, "Clean Architecture", "Robert Martin", "0134494164")
+
+		query := `query($after: String) { books(first: 1, after: $after) { edges { node { id title } } pageInfo { endCursor } } }`
+		page := func(after interface{}) (map[string]interface{}, interface{}) {
+			data, resp := exec(t, schema, ctx, query, map[string]interface{}{"after": after})
+			if len(resp.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", resp.Errors)
+			}
+			books := data["books"].(map[string]interface{})
+			edges := books["edges"].([]interface{})
+			if len(edges) != 1 {
+				t.Fatalf("expected one edge, got %v", edges)
+			}
+			return edges[0].(map[string]interface{})["node"].(map[string]interface{}), books["pageInfo"].(map[string]interface{})["endCursor"]
+		}
+		first, cursor := page(nil)
+		second, cursor := page(cursor)
+		exec(t, schema, ctx, `mutation($id: ID!) { deleteBook(id: $id) }`, map[string]interface{}{"id": second["id"]})
+
+		if third, _ := page(cursor); third["id"] == first["id"] || third["id"] == second["id"] {
+			t.Errorf("expected the book after the deleted one, got %v", third)
+		}
+
+		_, resp := exec(t, schema, ctx, query, map[string]interface{}{"after": "bm90IGEgY3Vyc29y"})
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "INVALID_INPUT" {
+			t.Errorf("expected INVALID_INPUT for an unknown cursor, got %v", resp.Errors)
+		}
+	})
+
+	t.Run("domain errors in extensions", func(t *testing.T) {
+		schema := newSchema(t, DefaultConfig())
+
+		_, resp := exec(t, schema, ctx, createMutation, map[string]interface{}{
+			"input": map[string]interface{}{"title": "", "author": "Robert Martin", "isbn": "0132350882"},
+		})
+
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "INVALID_INPUT" {
+			t.Errorf("expected INVALID_INPUT extension, got %v", resp.Errors)
+		}
+	})
+
+	t.Run("rejects deep queries", func(t *testing.T) {
+		config := DefaultConfig()
+		config.MaxDepth = 3
+		schema := newSchema(t, config)
+
+		_, resp := exec(t, schema, ctx, `{ books { edges { node { history { changes { field } } } } } }`, nil)
+
+		if len(resp.Errors) == 0 {
+			t.Error("expected depth limit error")
+		}
+	})
+
+	t.Run("rejects complex queries", func(t *testing.T) {
+		schema := newSchema(t, DefaultConfig())
+
+		resp := Exec(ctx, schema, 100, `{ books(first: 100) { edges { node { title history { version } } } } }`, "", nil)
+
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "QUERY_TOO_COMPLEX" {
+			t.Fatalf("expected QUERY_TOO_COMPLEX, got %v", resp.Errors)
+		}
+		if !strings.Contains(resp.Errors[0].Message, "exceeds limit of 100") {
+			t.Errorf("unexpected message: %s", resp.Errors[0].Message)
+		}
+	})
+
+	t.Run("rejects complex mutations before running any", func(t *testing.T) {
+		schema := newSchema(t, DefaultConfig())
+		query := `mutation {
+			first: createBook(input: {title: "Clean Code", author: "Robert Martin", isbn: "0132350882"}) { id title author }
+			second: createBook(input: {title: "Refactoring", author: "Martin Fowler", isbn: "0201485672"}) { id title author }
+		}`
+
+		resp := Exec(ctx, schema, 5, query, "", nil)
+
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "QUERY_TOO_COMPLEX" || len(resp.Data) != 0 {
+			t.Fatalf("expected only QUERY_TOO_COMPLEX, got %s %v", resp.Data, resp.Errors)
+		}
+		data, _ := exec(t, schema, ctx, `{ books { totalCount } }`, nil)
+		if count := data["books"].(map[string]interface{})["totalCount"]; count != float64(0) {
+			t.Errorf("expected no book to be created, got %v", count)
+		}
+	})
+
+	t.Run("reports invalid documents", func(t *testing.T) {
+		schema := newSchema(t, DefaultConfig())
+
+		resp := Exec(ctx, schema, 100, `{ books { edges `, "", nil)
+
+		if len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] == "INVALID_INPUT" {
+			t.Errorf("expected the schema's syntax error, got %v", resp.Errors)
+		}
+	})
+}
+
+func TestComplexity(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]interface{}
+		want      int
+	}{
+		{"single field", `{ book(id: "1") { title } }`, "", nil, 1},
+		{"leaf only", `mutation { deleteBook(id: "1") }`, "", nil, 1},
+		{"nested lists", `{ book(id: "1") { title history { version actor } } }`, "", nil, 1 + 10 + 10 + 10},
+		{"default page size", `{ books { totalCount } }`, "", nil, 20},
+		{"literal page size", `{ books(first: 5) { edges { node { id } } } }`, "", nil, 15},
+		{"variable page size", `query($n: Int) { books(first: $n) { totalCount } }`, "", map[string]interface{}{"n": float64(3)}, 3},
+		{"variable default", `query($n: Int = 4) { books(first: $n) { totalCount } }`, "", nil, 4},
+		{"aliases and duplicates", `{ a: book(id: "1") { title t: title } b: book(id: "2") { title } }`, "", nil, 2},
+		{"fragments", `query { book(id: "1") { ...F ... on Book { author } } } fragment F on Book { title __typename }`, "", nil, 2},
+		{"named operation", `query A { book(id: "1") { id } } query B { books(first: 2) { totalCount } }`, "B", nil, 2},
+		{"strings and comments", `{ # comment
+			book(id: """block "quoted" """) { title } }`, "", nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := complexity(tt.query, tt.operation, tt.variables)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("complexity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	for _, query := range []string{`{ book(id: "1") { ...F } } fragment F on Book { ...F }`, `query A { book(id: "1") { id } } query B { book(id: "1") { id } }`} {
+		if _, err := complexity(query, "", nil); err == nil {
+			t.Errorf("expected an error for %s", query)
+		}
+	}
+}
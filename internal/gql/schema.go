@@ -0,0 +1,88 @@
+package gql
+
+const Schema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+scalar Time
+
+type Query {
+	book(id: ID!): Book
+	bookByISBN(isbn: String!): Book
+	books(first: Int = 20, after: String, filter: BookFilter): BookConnection!
+}
+
+type Mutation {
+	createBook(input: CreateBookInput!): Book!
+	updateBook(id: ID!, input: UpdateBookInput!): Book!
+	deleteBook(id: ID!): Boolean!
+}
+
+input BookFilter {
+	title: String
+	author: String
+	isbn: String
+}
+
+input CreateBookInput {
+	title: String!
+	author: String!
+	isbn: String!
+	force: Boolean = false
+}
+
+input UpdateBookInput {
+	title: String
+	author: String
+	isbn: String
+}
+
+type Book {
+	id: ID!
+	title: String!
+	author: String!
+	isbn: String!
+	createdAt: Time!
+	updatedAt: Time!
+	history: [AuditEntry!]!
+	merges: [Merge!]!
+}
+
+type BookConnection {
+	edges: [BookEdge!]!
+	pageInfo: PageInfo!
+	totalCount: Int!
+}
+
+type BookEdge {
+	cursor: String!
+	node: Book!
+}
+
+type PageInfo {
+	hasNextPage: Boolean!
+	endCursor: String
+}
+
+type AuditEntry {
+	version: Int!
+	action: String!
+	actor: String!
+	timestamp: Time!
+	changes: [FieldChange!]!
+}
+
+type FieldChange {
+	field: String!
+	from: String!
+	to: String!
+}
+
+type Merge {
+	sourceId: ID!
+	targetId: ID!
+	mergedAt: Time!
+}
+`
@@ -3,15 +3,26 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"solid/internal/domain"
+	"solid/internal/filter"
+	v1 "solid/internal/handler/v1"
+	"solid/internal/render"
 	"solid/internal/service"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 )
 
-const requestTimeout = 5 * time.Second
+// RequestTimeout bounds the work of a single request; the gRPC server
+// applies the same limit.
+const RequestTimeout = 5 * time.Second
 
 type BookHandler struct {
 	service *service.BookService
@@ -23,114 +34,525 @@ func NewBookHandler(service *service.BookService) *BookHandler {
 	}
 }
 
-type createBookRequest struct {
-	Title  string `json:"title"`
-	Author string `json:"author"`
-	ISBN   string `json:"isbn"`
-}
-
-type updateBookRequest struct {
-	Title  string `json:"title"`
-	Author string `json:"author"`
-	ISBN   string `json:"isbn"`
-}
-
 type errorResponse struct {
-	Error string `json:"error"`
-	Code  string `json:"code,omitempty"`
+	Error   string      `json:"error"`
+	Code    string      `json:"code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 func (h *BookHandler) Create(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
 	defer cancel()
 
-	var req createBookRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "invalid request payload", "INVALID_JSON")
+	var req v1.CreateBookRequest
+	if !decodeBody(w, r, &req) {
 		return
 	}
 
-	book, err := h.service.CreateBook(ctx, req.Title, req.Author, req.ISBN)
+	opts := service.CreateBookOptions{Force: r.URL.Query().Get("force") == "true"}
+
+	book, candidates, err := h.service.CreateBook(ctx, req.Title, req.Author, req.ISBN, opts)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, book)
+	if len(candidates) > 0 {
+		ids := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			ids = append(ids, c.BookID)
+		}
+		w.Header().Set("Warning", fmt.Sprintf(`299 - "possible duplicate of %s"`, strings.Join(ids, ", ")))
+	}
+
+	respond(w, r, http.StatusCreated, v1.NewBook(book))
 }
 
 func (h *BookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
 	defer cancel()
 
 	id := mux.Vars(r)["id"]
+	projection, err := v1.ParseProjection(r.URL.Query())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
 
 	book, err := h.service.GetBook(ctx, id)
+	if errors.Is(err, domain.ErrBookNotFound) {
+		if targetID, aliasErr := h.service.ResolveAlias(ctx, id); aliasErr == nil {
+			target := *r.URL
+			target.Path = strings.TrimSuffix(r.URL.Path, id) + targetID
+			http.Redirect(w, r, target.RequestURI(), http.StatusMovedPermanently)
+			return
+		}
+	}
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	views, err := h.project(ctx, projection, []*domain.Book{book})
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, book)
+	respond(w, r, http.StatusOK, views[0])
 }
 
 func (h *BookHandler) List(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
 	defer cancel()
 
-	books, err := h.service.ListBooks(ctx)
+	query := r.URL.Query()
+	projection, err := v1.ParseProjection(query)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	bookFilter, err := parseFilter(query)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if !query.Has("limit") && !query.Has("after") {
+		books, err := h.service.FindBooks(ctx, bookFilter)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+
+		h.respondWithBooks(ctx, w, r, projection, books)
+		return
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, r, http.StatusBadRequest, "limit must be a positive integer", domain.ErrInvalidInput.Code)
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.service.ListBooksPage(ctx, bookFilter, query.Get("after"), limit)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	if page.HasMore {
+		next := *r.URL
+		query.Set("after", page.NextCursor)
+		next.RawQuery = query.Encode()
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+	h.respondWithBooks(ctx, w, r, projection, page.Books)
+}
+
+// parseFilter parses ?filter=, returning a nil filter when it is absent.
+func parseFilter(query url.Values) (domain.BookFilter, error) {
+	raw := query.Get("filter")
+	if raw == "" {
+		return nil, nil
+	}
+	expr, err := filter.Parse(raw)
+	var parseErr *filter.Error
+	if errors.As(err, &parseErr) {
+		return nil, domain.ErrInvalidFilter.
+			WithMessage(fmt.Sprintf("%s: %s", domain.ErrInvalidFilter.Message, parseErr)).
+			WithDetails(map[string]interface{}{"position": parseErr.Position, "filter": raw})
+	}
 	if err != nil {
-		handleError(w, err)
+		return nil, err
+	}
+	return expr, nil
+}
+
+func (h *BookHandler) respondWithBooks(ctx context.Context, w http.ResponseWriter, r *http.Request, projection v1.Projection, books []*domain.Book) {
+	if projection.IsZero() {
+		respond(w, r, http.StatusOK, v1.NewBooks(books))
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, books)
+	views, err := h.project(ctx, projection, books)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	respond(w, r, http.StatusOK, views)
+}
+
+// project trims books to the requested fields and loads the related
+// resources they expand. Duplicates are found for all books in one pass over
+// the catalog.
+func (h *BookHandler) project(ctx context.Context, projection v1.Projection, books []*domain.Book) ([]interface{}, error) {
+	var duplicates map[string][]service.DuplicateCandidate
+	if slices.Contains(projection.Expand, v1.ExpandDuplicates) {
+		var err error
+		if duplicates, err = h.service.FindDuplicatesOf(ctx, books); err != nil {
+			return nil, err
+		}
+	}
+
+	views := make([]interface{}, 0, len(books))
+	for _, book := range books {
+		related := make(map[string]interface{}, len(projection.Expand))
+		for _, name := range projection.Expand {
+			switch name {
+			case v1.ExpandHistory:
+				entries, err := h.service.BookHistory(ctx, book.ID)
+				if err != nil {
+					return nil, err
+				}
+				related[name] = v1.NewAuditEntries(entries)
+			case v1.ExpandMerges:
+				merges, err := h.service.ListMerges(ctx, book.ID)
+				if err != nil {
+					return nil, err
+				}
+				related[name] = v1.NewMerges(merges)
+			case v1.ExpandDuplicates:
+				related[name] = v1.NewDuplicateCandidates(duplicates[book.ID])
+			}
+		}
+		views = append(views, projection.View(v1.NewBook(book), related))
+	}
+	return views, nil
+}
+
+func (h *BookHandler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	groups, err := h.service.DuplicateReport(ctx)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewDuplicateGroups(groups))
+}
+
+func (h *BookHandler) Changes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	query := r.URL.Query()
+
+	var since uint64
+	if raw := query.Get("since"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "since must be a non-negative integer", domain.ErrInvalidInput.Code)
+			return
+		}
+		since = parsed
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, r, http.StatusBadRequest, "limit must be a positive integer", domain.ErrInvalidInput.Code)
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.service.ListChanges(ctx, since, limit)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewChangePage(page))
+}
+
+func (h *BookHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	if prefix == "" {
+		respondWithError(w, r, http.StatusBadRequest, "prefix is required", domain.ErrInvalidInput.Code)
+		return
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, r, http.StatusBadRequest, "limit must be a positive integer", domain.ErrInvalidInput.Code)
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := h.service.SuggestBooks(ctx, query.Get("field"), prefix, limit)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewSuggestions(suggestions))
 }
 
 func (h *BookHandler) Update(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
 	defer cancel()
 
 	id := mux.Vars(r)["id"]
 
-	var req updateBookRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "invalid request payload", "INVALID_JSON")
+	var req v1.UpdateBookRequest
+	if !decodeBody(w, r, &req) {
 		return
 	}
 
 	book, err := h.service.UpdateBook(ctx, id, req.Title, req.Author, req.ISBN)
 	if err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewBook(book))
+}
+
+func (h *BookHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	var req v1.MergeBookRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	book, err := h.service.MergeBooks(ctx, id, req.SourceID, req.Fields)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewBook(book))
+}
+
+func (h *BookHandler) Merges(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	merges, err := h.service.ListMerges(ctx, id)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewMerges(merges))
+}
+
+func (h *BookHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	books, err := h.service.ListTrash(ctx)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewBooks(books))
+}
+
+func (h *BookHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	book, err := h.service.RestoreBook(ctx, id)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewBook(book))
+}
+
+func (h *BookHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	entries, err := h.service.BookHistory(ctx, id)
+	if err != nil {
+		handleError(w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, book)
+	respond(w, r, http.StatusOK, v1.NewAuditEntries(entries))
+}
+
+func (h *BookHandler) Version(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id, version, ok := parseVersion(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.BookVersion(ctx, id, version)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewAuditEntry(entry))
+}
+
+func (h *BookHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id, version, ok := parseVersion(w, r)
+	if !ok {
+		return
+	}
+
+	book, err := h.service.RevertBook(ctx, id, version)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, v1.NewBook(book))
+}
+
+func (h *BookHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	var req v1.BatchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	ops := make([]service.BatchOperation, len(req.Operations))
+	for i, op := range req.Operations {
+		ops[i] = service.BatchOperation{
+			Op:     op.Op,
+			ID:     op.ID,
+			Title:  op.Title,
+			Author: op.Author,
+			ISBN:   op.ISBN,
+			Force:  op.Force,
+		}
+	}
+
+	results, err := h.service.ExecuteBatch(ctx, ops, req.Atomic)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	resp := v1.BatchResponse{Results: make([]v1.BatchResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = toBatchResult(i, ops[i].Op, result)
+	}
+
+	respond(w, r, http.StatusOK, resp)
+}
+
+func toBatchResult(index int, op string, result service.BatchResult) v1.BatchResult {
+	if result.Err != nil {
+		resp := v1.BatchResult{
+			Index:  index,
+			Status: domain.GetStatusCode(result.Err),
+			Error:  result.Err.Error(),
+		}
+		var domainErr *domain.DomainError
+		if errors.As(result.Err, &domainErr) {
+			resp.Code = domainErr.Code
+		}
+		return resp
+	}
+
+	status := http.StatusOK
+	switch op {
+	case service.BatchCreate:
+		status = http.StatusCreated
+	case service.BatchDelete:
+		status = http.StatusNoContent
+	}
+	return v1.BatchResult{Index: index, Status: status, Book: v1.NewBook(result.Book)}
 }
 
 func (h *BookHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
 	defer cancel()
 
 	id := mux.Vars(r)["id"]
 
 	if err := h.service.DeleteBook(ctx, id); err != nil {
-		handleError(w, err)
+		handleError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func handleError(w http.ResponseWriter, err error) {
-	statusCode := domain.GetStatusCode(err)
-	code := ""
-	
-	if domainErr, ok := err.(*domain.DomainError); ok {
-		code = domainErr.Code
+func parseVersion(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	vars := mux.Vars(r)
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version < 1 {
+		respondWithError(w, r, http.StatusBadRequest, "version must be a positive integer", domain.ErrInvalidInput.Code)
+		return "", 0, false
 	}
-	
-	respondWithError(w, statusCode, err.Error(), code)
+	return vars["id"], version, true
+}
+
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
+	resp := errorResponse{Error: err.Error()}
+
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		resp.Code = domainErr.Code
+		resp.Details = domainErr.Details
+	}
+
+	respond(w, r, domain.GetStatusCode(err), resp)
+}
+
+// respond writes payload in the media type negotiated from the Accept
+// header. Errors that no accepted type can carry (such as an error object
+// requested as CSV) are written as JSON instead of being replaced by a 406.
+func respond(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	codec, body, err := render.Default.Encode(r.Header.Get("Accept"), payload)
+	if errors.Is(err, render.ErrNotAcceptable) && code < http.StatusBadRequest {
+		respondWithJSON(w, http.StatusNotAcceptable, errorResponse{
+			Error: "the response cannot be produced in any of the accepted media types",
+			Code:  "NOT_ACCEPTABLE",
+		})
+		return
+	}
+	if err != nil {
+		respondWithJSON(w, code, payload)
+		return
+	}
+
+	w.Header().Set("Content-Type", render.ContentType(codec))
+	w.WriteHeader(code)
+	w.Write(body)
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
@@ -139,8 +561,8 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-func respondWithError(w http.ResponseWriter, code int, message, errorCode string) {
-	respondWithJSON(w, code, errorResponse{
+func respondWithError(w http.ResponseWriter, r *http.Request, code int, message, errorCode string) {
+	respond(w, r, code, errorResponse{
 		Error: message,
 		Code:  errorCode,
 	})
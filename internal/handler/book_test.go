@@ -0,0 +1,137 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"solid/internal/router/routertest"
+)
+
+func TestSparseFieldsets(t *testing.T) {
+	r := routertest.New(t)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	created := serve(http.MethodPost, "/v1/books", `{"title":"Clean Code","author":"Robert Martin","isbn":"0132350882"}`)
+	var book struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(created.Body.Bytes(), &book)
+	serve(http.MethodPost, "/v1/books?force=true", `{"title":"Clean Code","author":"Robert Martin","isbn":"9780132350884"}`)
+
+	t.Run("fields", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books/"+book.ID+"?fields=title,id", "")
+
+		want := `{"id":"` + book.ID + `","title":"Clean Code"}` + "\n"
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("expected %s, got %d %s", want, rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("expand", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books?fields=id&expand=duplicates,history", "")
+
+		var books []map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &books); err != nil || len(books) != 2 {
+			t.Fatalf("unexpected response %d %s", rec.Code, rec.Body.String())
+		}
+		var keys []string
+		for key := range books[0] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if strings.Join(keys, ",") != "duplicates,history,id" {
+			t.Errorf("unexpected keys %v", keys)
+		}
+		if !strings.Contains(string(books[0]["duplicates"]), `"score"`) || !strings.Contains(string(books[0]["history"]), `"action":"created"`) {
+			t.Errorf("expected embedded duplicates and history, got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("unknown names", func(t *testing.T) {
+		for path, code := range map[string]string{
+			"/v1/books/" + book.ID + "?fields=id,price": "UNKNOWN_FIELD",
+			"/v1/books?expand=reviews":                  "UNKNOWN_EXPANSION",
+		} {
+			rec := serve(http.MethodGet, path, "")
+
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"code":"`+code+`"`) ||
+				!strings.Contains(rec.Body.String(), `"allowed":`) {
+				t.Errorf("%s: expected 400 %s, got %d %s", path, code, rec.Code, rec.Body.String())
+			}
+		}
+	})
+}
+
+func TestFilter(t *testing.T) {
+	r := routertest.New(t)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	serve(http.MethodPost, "/v1/books", `{"title":"Clean Code","author":"Robert Martin","isbn":"0132350882"}`)
+	serve(http.MethodPost, "/v1/books", `{"title":"The Go Programming Language","author":"Alan Donovan","isbn":"0134190440"}`)
+	serve(http.MethodPost, "/v1/books", `{"title":"Refactoring","author":"Martin Fowler","isbn":"0201485672"}`)
+	titles := func(rec *httptest.ResponseRecorder) string {
+		var books []struct {
+			Title string `json:"title"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &books)
+		var out []string
+		for _, book := range books {
+			out = append(out, book.Title)
+		}
+		sort.Strings(out)
+		return strings.Join(out, ", ")
+	}
+
+	t.Run("matching books", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books?filter="+url.QueryEscape("author eq 'Robert Martin' or title contains 'Go'"), "")
+
+		if want := "Clean Code, The Go Programming Language"; rec.Code != http.StatusOK || titles(rec) != want {
+			t.Errorf("expected %s, got %d %s", want, rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books?limit=1&filter="+url.QueryEscape("not author eq 'Alan Donovan'"), "")
+		link := rec.Header().Get("Link")
+		if rec.Code != http.StatusOK || !strings.Contains(link, "filter=") {
+			t.Fatalf("expected a next link keeping the filter, got %d %q", rec.Code, link)
+		}
+
+		next := serve(http.MethodGet, strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`), "")
+		if got := titles(rec) + ", " + titles(next); got != "Clean Code, Refactoring" {
+			t.Errorf("expected both pages to skip the filtered book, got %s", got)
+		}
+	})
+
+	t.Run("error position", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books?filter="+url.QueryEscape("title eq 'Go' and created_at gt 'soon'"), "")
+
+		var resp struct {
+			Code    string `json:"code"`
+			Details struct {
+				Position int `json:"position"`
+			} `json:"details"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusBadRequest || resp.Code != "INVALID_FILTER" || resp.Details.Position != 33 {
+			t.Errorf("expected 400 INVALID_FILTER at position 33, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+}
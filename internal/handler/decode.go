@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"solid/internal/render"
+)
+
+// decodeBody reads the request body with the codec registered for its
+// Content-Type and writes the error response itself when that fails. A body
+// without Content-Type is rejected with 415; an empty one is read as JSON so
+// the client learns the body is missing. The body must hold a single value
+// that sets only fields v declares, within the size limit of
+// middleware.MaxBodySize.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	contentType := r.Header.Get("Content-Type")
+	codec := render.Codec(render.JSON{})
+	if contentType != "" {
+		var ok bool
+		if codec, ok = render.Default.Lookup(contentType); !ok {
+			respondWithError(w, r, http.StatusUnsupportedMediaType, "unsupported content type "+contentType, "UNSUPPORTED_MEDIA_TYPE")
+			return false
+		}
+	}
+
+	data, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondWithError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), "BODY_TOO_LARGE")
+		return false
+	}
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "could not read request body", "INVALID_BODY")
+		return false
+	}
+	if contentType == "" && len(data) > 0 {
+		respondWithError(w, r, http.StatusUnsupportedMediaType, "request body has no Content-Type", "UNSUPPORTED_MEDIA_TYPE")
+		return false
+	}
+
+	err = codec.Decode(bytes.NewReader(data), v)
+	var decodeErr *render.DecodeError
+	switch {
+	case errors.As(err, &decodeErr):
+		respond(w, r, http.StatusBadRequest, errorResponse{
+			Error:   "invalid request payload: " + decodeErr.Error(),
+			Code:    "INVALID_JSON",
+			Details: decodeErrorDetails(decodeErr),
+		})
+		return false
+	case err != nil:
+		respondWithError(w, r, http.StatusBadRequest, "invalid request payload: "+err.Error(), "INVALID_JSON")
+		return false
+	}
+	return true
+}
+
+func decodeErrorDetails(err *render.DecodeError) map[string]interface{} {
+	details := make(map[string]interface{})
+	if err.Field != "" {
+		details["field"] = err.Field
+	}
+	if err.Offset >= 0 {
+		details["offset"] = err.Offset
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
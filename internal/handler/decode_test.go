@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"solid/internal/router/routertest"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	r := routertest.New(t)
+	serve := func(method, path, contentType string, body io.Reader, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, body)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	type errorBody struct {
+		Error   string `json:"error"`
+		Code    string `json:"code"`
+		Details struct {
+			Field  string `json:"field"`
+			Offset *int   `json:"offset"`
+		} `json:"details"`
+	}
+
+	for name, tt := range map[string]struct {
+		contentType, body string
+		status            int
+		code, field       string
+		offset            int
+	}{
+		"unknown field":    {"application/json", `{"title":"Clean Code","titel":"x"}`, http.StatusBadRequest, "INVALID_JSON", "titel", 22},
+		"wrong type":       {"application/json", `{"title":"Clean Code","author":42}`, http.StatusBadRequest, "INVALID_JSON", "author", 22},
+		"trailing data":    {"application/json", `{"title":"Clean Code"} {"title":"x"}`, http.StatusBadRequest, "INVALID_JSON", "", 23},
+		"malformed":        {"application/json", `{"title":"Clean Code",}`, http.StatusBadRequest, "INVALID_JSON", "", 22},
+		"empty":            {"application/json", ``, http.StatusBadRequest, "INVALID_JSON", "", 0},
+		"unknown yaml key": {"application/yaml", "title: Clean Code\ntitel: x\n", http.StatusBadRequest, "INVALID_JSON", "titel", -1},
+		"yaml documents":   {"application/yaml", "title: A\n---\ntitle: B\n", http.StatusBadRequest, "INVALID_JSON", "", -1},
+		"media type":       {"text/plain", `title=Clean Code`, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "", -1},
+		"no content type":  {"", `{"title":"Clean Code"}`, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "", -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := serve(http.MethodPost, "/v1/books", tt.contentType, strings.NewReader(tt.body))
+
+			var resp errorBody
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			offset := -1
+			if resp.Details.Offset != nil {
+				offset = *resp.Details.Offset
+			}
+			if rec.Code != tt.status || resp.Code != tt.code || resp.Details.Field != tt.field || offset != tt.offset {
+				t.Errorf("expected %d %s field %q offset %d, got %d %s", tt.status, tt.code, tt.field, tt.offset, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("chunked body without content type", func(t *testing.T) {
+		for _, path := range []string{"/v1/books", "/graphql"} {
+			rec := serve(http.MethodPost, path, "", struct{ io.Reader }{strings.NewReader(`{"query":"{ books { totalCount } }"}`)})
+			if rec.Code != http.StatusUnsupportedMediaType || !strings.Contains(rec.Body.String(), `"code":"UNSUPPORTED_MEDIA_TYPE"`) {
+				t.Errorf("%s: expected 415 UNSUPPORTED_MEDIA_TYPE, got %d %s", path, rec.Code, rec.Body.String())
+			}
+		}
+	})
+
+	t.Run("strict graphql body", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/graphql", "application/json", strings.NewReader(`{"query":"{ books { totalCount } }","variabels":{}}`))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"field":"variabels"`) {
+			t.Errorf("expected 400 naming the unknown field, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		large := `{"title":"` + strings.Repeat("x", routertest.MaxBodySize) + `"}`
+		// A chunked body has no Content-Length, so only reading it trips the limit.
+		chunked := struct{ io.Reader }{strings.NewReader(large)}
+
+		for _, rec := range []*httptest.ResponseRecorder{
+			serve(http.MethodPost, "/v1/books", "application/json", strings.NewReader(large)),
+			serve(http.MethodPut, "/v1/books/missing", "application/json", chunked),
+			serve(http.MethodPost, "/v1/books", "application/json", struct{ io.Reader }{strings.NewReader(large)}, "Idempotency-Key", "large"),
+		} {
+			if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), `"code":"BODY_TOO_LARGE"`) {
+				t.Errorf("expected 413 BODY_TOO_LARGE, got %d %s", rec.Code, rec.Body.String())
+			}
+		}
+	})
+}
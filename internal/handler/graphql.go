@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"solid/internal/gql"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type GraphQLHandler struct {
+	schema        *graphql.Schema
+	maxComplexity int
+}
+
+func NewGraphQLHandler(schema *graphql.Schema, maxComplexity int) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema:        schema,
+		maxComplexity: maxComplexity,
+	}
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	// Extensions is accepted so strict decoding does not reject clients
+	// that send it, but no extension is supported.
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	var req graphQLRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Query == "" {
+		respondWithError(w, r, http.StatusBadRequest, "query cannot be empty", "INVALID_INPUT")
+		return
+	}
+
+	resp := gql.Exec(ctx, h.schema, h.maxComplexity, req.Query, req.OperationName, req.Variables)
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *GraphQLHandler) GraphiQL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(graphiQLPage))
+}
+
+const graphiQLPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>GraphiQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css" />
+</head>
+<body style="margin: 0;">
+  <div id="graphiql" style="height: 100vh;"></div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: '/graphql' });
+    ReactDOM.createRoot(document.getElementById('graphiql')).render(React.createElement(GraphiQL, { fetcher }));
+  </script>
+</body>
+</html>
+`
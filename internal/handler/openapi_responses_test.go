@@ -0,0 +1,294 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"solid/api/openapi"
+	"solid/internal/router/routertest"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+type apiSpec struct {
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+
+	compiler *jsonschema.Compiler
+	raw      map[string]interface{}
+}
+
+type operation struct {
+	Responses map[string]response `json:"responses"`
+}
+
+type response struct {
+	Ref     string                     `json:"$ref"`
+	Content map[string]json.RawMessage `json:"content"`
+}
+
+func loadSpec(t *testing.T) *apiSpec {
+	t.Helper()
+
+	var spec apiSpec
+	if err := json.Unmarshal(openapi.Spec, &spec); err != nil {
+		t.Fatalf("invalid openapi document: %v", err)
+	}
+	if err := json.Unmarshal(openapi.Spec, &spec.raw); err != nil {
+		t.Fatalf("invalid openapi document: %v", err)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(openapi.Spec))
+	if err != nil {
+		t.Fatalf("invalid openapi document: %v", err)
+	}
+	spec.compiler = jsonschema.NewCompiler()
+	if err := spec.compiler.AddResource("openapi.json", doc); err != nil {
+		t.Fatalf("unexpected error adding openapi document: %v", err)
+	}
+	return &spec
+}
+
+// validate checks that the recorded response is documented for the
+// operation and, for JSON bodies, that it matches the documented schema.
+func (s *apiSpec) validate(t *testing.T, method, path string, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	method = strings.ToLower(method)
+	rawOp, ok := s.Paths[path][method]
+	if !ok {
+		t.Fatalf("%s %s is not documented", method, path)
+	}
+	var op operation
+	if err := json.Unmarshal(rawOp, &op); err != nil {
+		t.Fatalf("invalid operation %s %s: %v", method, path, err)
+	}
+
+	status := strconv.Itoa(rec.Code)
+	resp, ok := op.Responses[status]
+	if !ok {
+		t.Fatalf("%s %s returned undocumented status %s: %s", method, path, status, rec.Body.String())
+	}
+	pointer := "#/paths/" + escapePointer(path) + "/" + method + "/responses/" + status
+	if resp.Ref != "" {
+		pointer = resp.Ref
+		resp = s.resolveResponse(t, resp.Ref)
+	}
+	if len(resp.Content) == 0 {
+		return
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(rec.Header().Get("Content-Type"), ";")[0])
+	if _, ok := resp.Content[mediaType]; !ok {
+		t.Fatalf("%s %s returned undocumented content type %q for %s", method, path, mediaType, status)
+	}
+	if mediaType != "application/json" {
+		return
+	}
+
+	schema, err := s.compiler.Compile("openapi.json" + pointer + "/content/application~1json/schema")
+	if err != nil {
+		t.Fatalf("unexpected error compiling schema for %s %s %s: %v", method, path, status, err)
+	}
+	body, err := jsonschema.UnmarshalJSON(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("%s %s returned invalid JSON: %v", method, path, err)
+	}
+	if err := schema.Validate(body); err != nil {
+		t.Errorf("%s %s %s does not match the schema: %v\n%s", method, path, status, err, rec.Body.String())
+	}
+}
+
+func (s *apiSpec) resolveResponse(t *testing.T, ref string) response {
+	t.Helper()
+
+	var node interface{} = s.raw
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		node = node.(map[string]interface{})[token]
+	}
+	encoded, _ := json.Marshal(node)
+
+	var resp response
+	if err := json.Unmarshal(encoded, &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", ref, err)
+	}
+	return resp
+}
+
+func escapePointer(path string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1", "{", "%7B", "}", "%7D").Replace(path)
+}
+
+func TestResponsesMatchSpec(t *testing.T) {
+	spec := loadSpec(t)
+	r := routertest.New(t)
+
+	do := func(method, path, template string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		var reader *bytes.Reader
+		switch b := body.(type) {
+		case nil:
+			reader = bytes.NewReader(nil)
+		case string:
+			reader = bytes.NewReader([]byte(b))
+		default:
+			encoded, _ := json.Marshal(b)
+			reader = bytes.NewReader(encoded)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		spec.validate(t, method, template, rec)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) map[string]interface{} {
+		t.Helper()
+		var out map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+			t.Fatalf("unexpected error decoding %s: %v", rec.Body.String(), err)
+		}
+		return out
+	}
+	expect := func(rec *httptest.ResponseRecorder, status int) {
+		t.Helper()
+		if rec.Code != status {
+			t.Fatalf("expected status %d, got %d: %s", status, rec.Code, rec.Body.String())
+		}
+	}
+
+	expect(do(http.MethodGet, "/health", "/health", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/openapi.json", "/openapi.json", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/docs", "/docs", nil), http.StatusOK)
+
+	created := do(http.MethodPost, "/v1/books", "/books", map[string]string{
+		"title": "Clean Code", "author": "Robert Martin", "isbn": "0132350882",
+	})
+	expect(created, http.StatusCreated)
+	bookID := decode(created)["id"].(string)
+
+	expect(do(http.MethodPost, "/v1/books", "/books", "{"), http.StatusBadRequest)
+	expect(do(http.MethodPost, "/v1/books", "/books", map[string]string{"title": "No ISBN"}), http.StatusBadRequest)
+	expect(do(http.MethodPost, "/v1/books", "/books", map[string]string{
+		"title": "Another Book", "author": "Someone Else", "isbn": "0132350882",
+	}), http.StatusConflict)
+	expect(do(http.MethodPost, "/v1/books", "/books", map[string]string{
+		"title": "Clean Code", "author": "Robert Martin", "isbn": "9780132350884",
+	}), http.StatusConflict)
+
+	duplicate := do(http.MethodPost, "/v1/books?force=true", "/books", map[string]string{
+		"title": "Clean Code", "author": "Robert Martin", "isbn": "9780132350884",
+	})
+	expect(duplicate, http.StatusCreated)
+	if warning := duplicate.Header().Get("Warning"); !strings.Contains(warning, "possible duplicate of "+bookID) {
+		t.Errorf("expected a duplicate warning naming %s, got %q", bookID, warning)
+	}
+	duplicateID := decode(duplicate)["id"].(string)
+
+	expect(do(http.MethodPost, "/v1/books", "/books", map[string]string{
+		"title": "Refactoring", "author": "Martin Fowler", "isbn": "0201485672",
+	}, "Idempotency-Key", "create-refactoring"), http.StatusCreated)
+	expect(do(http.MethodPost, "/v1/books", "/books", map[string]string{
+		"title": "Refactoring 2", "author": "Martin Fowler", "isbn": "0201485672",
+	}, "Idempotency-Key", "create-refactoring"), http.StatusUnprocessableEntity)
+
+	expect(do(http.MethodGet, "/v1/books", "/books", nil), http.StatusOK)
+	if link := do(http.MethodGet, "/v1/books?limit=1", "/books", nil).Header().Get("Link"); !strings.Contains(link, `rel="next"`) {
+		t.Errorf("expected a next link, got %q", link)
+	}
+	expect(do(http.MethodGet, "/v1/books?after=bogus", "/books", nil), http.StatusBadRequest)
+	expect(do(http.MethodGet, "/v1/books/"+bookID, "/books/{id}", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/missing", "/books/{id}", nil), http.StatusNotFound)
+	expect(do(http.MethodGet, "/v1/books/"+bookID+"?fields=id,title&expand=history,merges,duplicates", "/books/{id}", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books?fields=id,isbn&expand=duplicates", "/books", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/"+bookID+"?fields=price", "/books/{id}", nil), http.StatusBadRequest)
+	expect(do(http.MethodGet, "/v1/books?expand=reviews", "/books", nil), http.StatusBadRequest)
+	expect(do(http.MethodGet, "/v1/books?filter="+url.QueryEscape("author eq 'Martin Fowler' or title contains 'Clean'")+"&limit=1", "/books", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books?filter="+url.QueryEscape("price gt 10"), "/books", nil), http.StatusBadRequest)
+	expect(do(http.MethodPut, "/v1/books/"+bookID, "/books/{id}", map[string]string{"title": "Clean Code, 1st Edition"}), http.StatusOK)
+	expect(do(http.MethodPut, "/v1/books/"+bookID, "/books/{id}", map[string]string{"title": "Clean Code"}, "Idempotency-Key", "rename"), http.StatusOK)
+	if replayed := do(http.MethodPut, "/v1/books/"+bookID, "/books/{id}", map[string]string{"title": "Clean Code"}, "Idempotency-Key", "rename"); replayed.Header().Get("Idempotency-Replayed") != "true" {
+		t.Errorf("expected the retried update to be replayed, got %v", replayed.Header())
+	}
+	expect(do(http.MethodPut, "/v1/books/"+bookID, "/books/{id}", "{"), http.StatusBadRequest)
+	expect(do(http.MethodGet, "/v1/books/suggest?prefix=clean", "/books/suggest", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/suggest", "/books/suggest", nil), http.StatusBadRequest)
+	expect(do(http.MethodGet, "/v1/books/duplicates", "/books/duplicates", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/changes?since=1&limit=2", "/books/changes", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/changes?since=-1", "/books/changes", nil), http.StatusBadRequest)
+
+	expect(do(http.MethodGet, "/v1/books/"+bookID+"/history", "/books/{id}/history", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/"+bookID+"/history/1", "/books/{id}/history/{version}", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/"+bookID+"/history/x", "/books/{id}/history/{version}", nil), http.StatusBadRequest)
+	expect(do(http.MethodGet, "/v1/books/"+bookID+"/history/99", "/books/{id}/history/{version}", nil), http.StatusNotFound)
+	expect(do(http.MethodPost, "/v1/books/"+bookID+"/history/1/revert", "/books/{id}/history/{version}/revert", nil), http.StatusOK)
+
+	expect(do(http.MethodPost, "/v1/books/"+bookID+"/merge", "/books/{id}/merge", map[string]interface{}{
+		"source_id": duplicateID, "fields": map[string]string{"isbn": "source"},
+	}), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/books/"+duplicateID, "/books/{id}", nil), http.StatusMovedPermanently)
+	expect(do(http.MethodGet, "/v1/books/"+bookID+"/merges", "/books/{id}/merges", nil), http.StatusOK)
+
+	batch := do(http.MethodPost, "/v1/books/batch", "/books/batch", map[string]interface{}{
+		"operations": []map[string]interface{}{
+			{"op": "create", "title": "Working Effectively with Legacy Code", "author": "Michael Feathers", "isbn": "0131177052"},
+			{"op": "update", "id": "missing", "title": "Nothing"},
+			{"op": "delete", "id": bookID},
+		},
+	})
+	expect(batch, http.StatusOK)
+	expect(do(http.MethodPost, "/v1/books/batch", "/books/batch", map[string]interface{}{"operations": []interface{}{}}), http.StatusBadRequest)
+
+	expect(do(http.MethodGet, "/v1/books/trash", "/books/trash", nil), http.StatusOK)
+	expect(do(http.MethodPost, "/v1/books/"+bookID+"/restore", "/books/{id}/restore", nil), http.StatusOK)
+	expect(do(http.MethodPost, "/v1/books/"+bookID+"/restore", "/books/{id}/restore", nil), http.StatusNotFound)
+	expect(do(http.MethodDelete, "/v1/books/"+bookID, "/books/{id}", nil), http.StatusNoContent)
+
+	subscription := do(http.MethodPost, "/v1/webhooks", "/webhooks", map[string]interface{}{
+		"url": "http://example.com/hook", "events": []string{"book.created"},
+	})
+	expect(subscription, http.StatusCreated)
+	subscriptionID := decode(subscription)["id"].(string)
+
+	expect(do(http.MethodPost, "/v1/webhooks", "/webhooks", map[string]interface{}{"url": "not a url"}), http.StatusBadRequest)
+	expect(do(http.MethodPost, "/v1/books", "/books", map[string]string{
+		"title": "Domain-Driven Design", "author": "Eric Evans", "isbn": "0321125215",
+	}), http.StatusCreated)
+
+	expect(do(http.MethodGet, "/v1/webhooks", "/webhooks", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/webhooks/"+subscriptionID, "/webhooks/{id}", nil), http.StatusOK)
+	expect(do(http.MethodGet, "/v1/webhooks/missing", "/webhooks/{id}", nil), http.StatusNotFound)
+	expect(do(http.MethodPut, "/v1/webhooks/"+subscriptionID, "/webhooks/{id}", map[string]interface{}{"active": false}), http.StatusOK)
+
+	deliveries := do(http.MethodGet, "/v1/webhooks/"+subscriptionID+"/deliveries", "/webhooks/{id}/deliveries", nil)
+	expect(deliveries, http.StatusOK)
+	var delivered []map[string]interface{}
+	if err := json.Unmarshal(deliveries.Body.Bytes(), &delivered); err != nil || len(delivered) == 0 {
+		t.Fatalf("expected a delivery for the created book, got %s", deliveries.Body.String())
+	}
+	do(http.MethodPost, "/v1/webhooks/"+subscriptionID+"/deliveries/"+delivered[0]["id"].(string)+"/redeliver",
+		"/webhooks/{id}/deliveries/{deliveryID}/redeliver", nil)
+	expect(do(http.MethodPost, "/v1/webhooks/"+subscriptionID+"/deliveries/missing/redeliver",
+		"/webhooks/{id}/deliveries/{deliveryID}/redeliver", nil), http.StatusNotFound)
+	expect(do(http.MethodGet, "/v1/webhooks/dead-letters", "/webhooks/dead-letters", nil), http.StatusOK)
+	expect(do(http.MethodDelete, "/v1/webhooks/"+subscriptionID, "/webhooks/{id}", nil), http.StatusNoContent)
+
+	expect(do(http.MethodPost, "/graphql", "/graphql", map[string]interface{}{
+		"query": `{ books(first: 5) { edges { node { id title } } } }`,
+	}), http.StatusOK)
+	expect(do(http.MethodPost, "/graphql", "/graphql", map[string]interface{}{
+		"query": `{ book(id: "missing") { id } }`,
+	}), http.StatusOK)
+	expect(do(http.MethodPost, "/graphql", "/graphql", map[string]interface{}{"query": ""}), http.StatusBadRequest)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"solid/api/openapi"
+	"solid/internal/domain"
+	v1 "solid/internal/handler/v1"
+)
+
+func TestOpenAPISchemasMatchTypes(t *testing.T) {
+	var spec struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(openapi.Spec, &spec); err != nil {
+		t.Fatalf("invalid openapi document: %v", err)
+	}
+
+	types := map[string]interface{}{
+		"Book":                 v1.Book{},
+		"CreateBookRequest":    v1.CreateBookRequest{},
+		"UpdateBookRequest":    v1.UpdateBookRequest{},
+		"MergeBookRequest":     v1.MergeBookRequest{},
+		"BatchRequest":         v1.BatchRequest{},
+		"BatchOperation":       v1.BatchOperation{},
+		"BatchResponse":        v1.BatchResponse{},
+		"BatchResult":          v1.BatchResult{},
+		"Error":                errorResponse{},
+		"DuplicateCandidate":   v1.DuplicateCandidate{},
+		"DuplicateGroup":       v1.DuplicateGroup{},
+		"Suggestion":           v1.Suggestion{},
+		"Change":               v1.Change{},
+		"ChangePage":           v1.ChangePage{},
+		"Merge":                v1.Merge{},
+		"FieldChange":          v1.FieldChange{},
+		"AuditEntry":           v1.AuditEntry{},
+		"Subscription":         domain.Subscription{},
+		"CreateWebhookRequest": createWebhookRequest{},
+		"UpdateWebhookRequest": updateWebhookRequest{},
+		"Delivery":             domain.Delivery{},
+		"DeliveryAttempt":      domain.DeliveryAttempt{},
+		"GraphQLRequest":       graphQLRequest{},
+	}
+
+	for name, value := range types {
+		schema, ok := spec.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing from the openapi document", name)
+			continue
+		}
+
+		documented := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			documented = append(documented, property)
+		}
+		sort.Strings(documented)
+
+		actual := jsonFields(reflect.TypeOf(value))
+		if strings.Join(documented, ",") != strings.Join(actual, ",") {
+			t.Errorf("schema %s documents %v, but %T encodes %v", name, documented, value, actual)
+		}
+	}
+}
+
+func jsonFields(typ reflect.Type) []string {
+	fields := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		tag := typ.Field(i).Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+var errorCodePattern = regexp.MustCompile(`(?:NewDomainError\(|(?:respondWithError|writeJSONError)\([^;{}]*?)"([A-Z][A-Z0-9_]+)"`)
+
+func TestErrorCodesAreDocumented(t *testing.T) {
+	var spec struct {
+		Components struct {
+			Schemas struct {
+				ErrorCode struct {
+					Enum []string `json:"enum"`
+				} `json:"ErrorCode"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(openapi.Spec, &spec); err != nil {
+		t.Fatalf("invalid openapi document: %v", err)
+	}
+
+	used := make(map[string]bool)
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range errorCodePattern.FindAllStringSubmatch(string(source), -1) {
+			used[match[1]] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error scanning sources: %v", err)
+	}
+
+	documented := make(map[string]bool)
+	for _, code := range spec.Components.Schemas.ErrorCode.Enum {
+		documented[code] = true
+	}
+
+	var missing, stale []string
+	for code := range used {
+		if !documented[code] {
+			missing = append(missing, code)
+		}
+	}
+	for code := range documented {
+		if !used[code] {
+			stale = append(stale, code)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("error codes missing from the ErrorCode enum: %v", missing)
+	}
+	if len(stale) > 0 {
+		t.Errorf("ErrorCode enum lists codes that are never returned: %v", stale)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"solid/internal/stream"
+	"strconv"
+	"time"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type ChangeStreamHandler struct {
+	broker    *stream.Broker
+	heartbeat time.Duration
+}
+
+func NewChangeStreamHandler(broker *stream.Broker) *ChangeStreamHandler {
+	return &ChangeStreamHandler{
+		broker:    broker,
+		heartbeat: heartbeatInterval,
+	}
+}
+
+func (h *ChangeStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	var lastEventID uint64
+	raw := r.Header.Get("Last-Event-ID")
+	if raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Last-Event-ID must be a number", "INVALID_INPUT")
+			return
+		}
+		lastEventID = parsed
+	}
+
+	client, missed, err := h.broker.Subscribe(r.Context(), lastEventID, raw != "")
+	if errors.Is(err, stream.ErrClosed) {
+		respondWithError(w, r, http.StatusServiceUnavailable, "server is shutting down", "UNAVAILABLE")
+		return
+	}
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	defer h.broker.Unsubscribe(client)
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, msg := range missed {
+		writeEvent(w, msg)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, open := <-client.Messages():
+			if !open {
+				return
+			}
+			writeEvent(w, msg)
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, msg stream.Message) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, msg.Data)
+}
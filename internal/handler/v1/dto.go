@@ -0,0 +1,230 @@
+// Package v1 holds the request and response bodies of the /v1 REST API.
+// They are the wire contract: domain types may change freely as long as
+// these conversions keep producing the same JSON.
+package v1
+
+import (
+	"time"
+
+	"solid/internal/domain"
+	"solid/internal/service"
+	"solid/internal/suggest"
+)
+
+type Book struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Author    string     `json:"author"`
+	ISBN      string     `json:"isbn"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewBook(book *domain.Book) *Book {
+	if book == nil {
+		return nil
+	}
+	return &Book{
+		ID:        book.ID,
+		Title:     book.Title,
+		Author:    book.Author,
+		ISBN:      book.ISBN,
+		CreatedAt: book.CreatedAt,
+		UpdatedAt: book.UpdatedAt,
+		DeletedAt: book.DeletedAt,
+	}
+}
+
+func NewBooks(books []*domain.Book) []*Book {
+	out := make([]*Book, 0, len(books))
+	for _, book := range books {
+		out = append(out, NewBook(book))
+	}
+	return out
+}
+
+type CreateBookRequest struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	ISBN   string `json:"isbn"`
+}
+
+type UpdateBookRequest struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	ISBN   string `json:"isbn"`
+}
+
+type MergeBookRequest struct {
+	SourceID string            `json:"source_id"`
+	Fields   map[string]string `json:"fields"`
+}
+
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchOperation struct {
+	Op     string `json:"op"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	ISBN   string `json:"isbn"`
+	Force  bool   `json:"force"`
+}
+
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	Book   *Book  `json:"book,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Code   string `json:"code,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type AuditEntry struct {
+	BookID    string        `json:"book_id"`
+	Version   int           `json:"version"`
+	Action    string        `json:"action"`
+	Actor     string        `json:"actor"`
+	RequestID string        `json:"request_id,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+	Changes   []FieldChange `json:"changes"`
+	Snapshot  Book          `json:"snapshot"`
+}
+
+func NewAuditEntry(entry *domain.AuditEntry) *AuditEntry {
+	var changes []FieldChange
+	if entry.Changes != nil {
+		changes = make([]FieldChange, 0, len(entry.Changes))
+		for _, change := range entry.Changes {
+			changes = append(changes, FieldChange{Field: change.Field, From: change.From, To: change.To})
+		}
+	}
+	return &AuditEntry{
+		BookID:    entry.BookID,
+		Version:   entry.Version,
+		Action:    entry.Action,
+		Actor:     entry.Actor,
+		RequestID: entry.RequestID,
+		Timestamp: entry.Timestamp,
+		Changes:   changes,
+		Snapshot:  *NewBook(&entry.Snapshot),
+	}
+}
+
+func NewAuditEntries(entries []*domain.AuditEntry) []*AuditEntry {
+	out := make([]*AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, NewAuditEntry(entry))
+	}
+	return out
+}
+
+type Change struct {
+	Sequence  uint64    `json:"sequence"`
+	Operation string    `json:"operation"`
+	BookID    string    `json:"book_id"`
+	Book      *Book     `json:"book,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type ChangePage struct {
+	Changes   []*Change `json:"changes"`
+	NextSince uint64    `json:"next_since"`
+	HasMore   bool      `json:"has_more"`
+}
+
+func NewChange(change *domain.Change) *Change {
+	return &Change{
+		Sequence:  change.Sequence,
+		Operation: change.Operation,
+		BookID:    change.BookID,
+		Book:      NewBook(change.Book),
+		Timestamp: change.Timestamp,
+	}
+}
+
+func NewChangePage(page *domain.ChangePage) *ChangePage {
+	changes := make([]*Change, 0, len(page.Changes))
+	for _, change := range page.Changes {
+		changes = append(changes, NewChange(change))
+	}
+	return &ChangePage{Changes: changes, NextSince: page.NextSince, HasMore: page.HasMore}
+}
+
+type Merge struct {
+	SourceID string            `json:"source_id"`
+	TargetID string            `json:"target_id"`
+	Fields   map[string]string `json:"fields"`
+	MergedAt time.Time         `json:"merged_at"`
+}
+
+func NewMerges(merges []*domain.Merge) []*Merge {
+	out := make([]*Merge, 0, len(merges))
+	for _, merge := range merges {
+		out = append(out, &Merge{
+			SourceID: merge.SourceID,
+			TargetID: merge.TargetID,
+			Fields:   merge.Fields,
+			MergedAt: merge.MergedAt,
+		})
+	}
+	return out
+}
+
+type DuplicateCandidate struct {
+	BookID string  `json:"book_id"`
+	Title  string  `json:"title"`
+	Author string  `json:"author"`
+	Score  float64 `json:"score"`
+}
+
+func NewDuplicateCandidates(candidates []service.DuplicateCandidate) []DuplicateCandidate {
+	out := make([]DuplicateCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		out = append(out, DuplicateCandidate{
+			BookID: candidate.BookID,
+			Title:  candidate.Title,
+			Author: candidate.Author,
+			Score:  candidate.Score,
+		})
+	}
+	return out
+}
+
+type DuplicateGroup struct {
+	Books []DuplicateCandidate `json:"books"`
+}
+
+func NewDuplicateGroups(groups []service.DuplicateGroup) []DuplicateGroup {
+	out := make([]DuplicateGroup, 0, len(groups))
+	for _, group := range groups {
+		out = append(out, DuplicateGroup{Books: NewDuplicateCandidates(group.Books)})
+	}
+	return out
+}
+
+type Suggestion struct {
+	Text   string `json:"text"`
+	BookID string `json:"book_id"`
+}
+
+func NewSuggestions(suggestions []suggest.Suggestion) []Suggestion {
+	out := make([]Suggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		out = append(out, Suggestion{Text: suggestion.Text, BookID: suggestion.BookID})
+	}
+	return out
+}
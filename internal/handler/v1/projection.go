@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"solid/internal/domain"
+)
+
+// Related resources that ?expand= can embed in a book.
+const (
+	ExpandHistory    = "history"
+	ExpandMerges     = "merges"
+	ExpandDuplicates = "duplicates"
+)
+
+var (
+	BookFields = jsonFields(reflect.TypeOf(domain.Book{}))
+	Expansions = []string{ExpandHistory, ExpandMerges, ExpandDuplicates}
+)
+
+// Projection is the sparse fieldset (?fields=id,title) and the expansions
+// (?expand=history) requested for book responses. The zero value keeps
+// every field and expands nothing.
+type Projection struct {
+	Fields []string
+	Expand []string
+}
+
+// ParseProjection reads ?fields= and ?expand=, each a comma-separated list
+// that may also be repeated, and rejects names that are not fields of
+// domain.Book or known expansions.
+func ParseProjection(query url.Values) (Projection, error) {
+	fields, err := parseNames(query["fields"], BookFields, domain.ErrUnknownField)
+	if err != nil {
+		return Projection{}, err
+	}
+	expand, err := parseNames(query["expand"], Expansions, domain.ErrUnknownExpansion)
+	if err != nil {
+		return Projection{}, err
+	}
+	return Projection{Fields: fields, Expand: expand}, nil
+}
+
+func parseNames(values, allowed []string, unknownErr *domain.DomainError) ([]string, error) {
+	var names, unknown []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			switch {
+			case name == "" || slices.Contains(names, name) || slices.Contains(unknown, name):
+			case slices.Contains(allowed, name):
+				names = append(names, name)
+			default:
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, unknownErr.
+			WithMessage(fmt.Sprintf("%s %s; expected one of %s", unknownErr.Message, strings.Join(unknown, ", "), strings.Join(allowed, ", "))).
+			WithDetails(map[string][]string{"unknown": unknown, "allowed": allowed})
+	}
+	return names, nil
+}
+
+func (p Projection) IsZero() bool {
+	return len(p.Fields) == 0 && len(p.Expand) == 0
+}
+
+// View applies the projection to a book. related holds the value of each
+// expansion by name.
+func (p Projection) View(book *Book, related map[string]interface{}) *BookView {
+	return &BookView{book: book, projection: p, related: related}
+}
+
+// BookView is a book reduced to a projection's fields, in the order of
+// Book, followed by the expanded resources in the order they were asked for.
+type BookView struct {
+	book       *Book
+	projection Projection
+	related    map[string]interface{}
+}
+
+func (v *BookView) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(v.book)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(key string, value []byte) {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	for _, field := range BookFields {
+		value, ok := values[field]
+		if ok && (len(v.projection.Fields) == 0 || slices.Contains(v.projection.Fields, field)) {
+			write(field, value)
+		}
+	}
+	for _, name := range v.projection.Expand {
+		value, err := json.Marshal(v.related[name])
+		if err != nil {
+			return nil, err
+		}
+		write(name, value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func jsonFields(typ reflect.Type) []string {
+	fields := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"solid/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+func NewWebhookHandler(service *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+type createWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+type updateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	var req createWebhookRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	sub, err := h.service.CreateSubscription(ctx, req.URL, req.Events, req.Secret)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	sub, err := h.service.GetSubscription(ctx, id)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	subs, err := h.service.ListSubscriptions(ctx)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	var req updateWebhookRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	sub, err := h.service.UpdateSubscription(ctx, id, req.URL, req.Events, req.Active)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	if err := h.service.DeleteSubscription(ctx, id); err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+
+	deliveries, err := h.service.ListDeliveries(ctx, id)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	deliveries, err := h.service.ListDeadLetters(ctx)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), RequestTimeout)
+	defer cancel()
+
+	vars := mux.Vars(r)
+
+	delivery, err := h.service.Redeliver(ctx, vars["id"], vars["deliveryID"])
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respond(w, r, http.StatusAccepted, delivery)
+}
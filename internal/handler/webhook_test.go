@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"solid/internal/domain"
+	"solid/internal/service"
+	"solid/pkg/mocks"
+
+	"github.com/gorilla/mux"
+)
+
+func serveWebhook(h http.HandlerFunc, method, body string, vars map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/webhooks", strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h(rec, mux.SetURLVars(req, vars))
+	return rec
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		h := NewWebhookHandler(service.NewWebhookService(&mocks.WebhookRepository{}))
+
+		rec := serveWebhook(h.Create, http.MethodPost, `{"url":"https://example.com/hook","events":["*"],"secret":"s3cret"}`, nil)
+
+		var sub domain.Subscription
+		json.Unmarshal(rec.Body.Bytes(), &sub)
+		if rec.Code != http.StatusCreated || sub.ID == "" || sub.Secret != "s3cret" {
+			t.Errorf("expected 201 with the secret, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("invalid subscription", func(t *testing.T) {
+		h := NewWebhookHandler(service.NewWebhookService(&mocks.WebhookRepository{}))
+
+		rec := serveWebhook(h.Create, http.MethodPost, `{"url":"https://example.com/hook","events":["book.read"]}`, nil)
+
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"code":"INVALID_INPUT"`) {
+			t.Errorf("expected 400 INVALID_INPUT, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestWebhookHandler_GetByID(t *testing.T) {
+	h := NewWebhookHandler(service.NewWebhookService(&mocks.WebhookRepository{
+		FindSubscriptionFunc: func(ctx context.Context, id string) (*domain.Subscription, error) {
+			if id != "sub-1" {
+				return nil, domain.ErrSubscriptionNotFound
+			}
+			return &domain.Subscription{ID: id, Secret: "s3cret"}, nil
+		},
+	}))
+
+	rec := serveWebhook(h.GetByID, http.MethodGet, "", map[string]string{"id": "sub-1"})
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "s3cret") {
+		t.Errorf("expected 200 without the secret, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveWebhook(h.GetByID, http.MethodGet, "", map[string]string{"id": "missing"})
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"code":"SUBSCRIPTION_NOT_FOUND"`) {
+		t.Errorf("expected 404 SUBSCRIPTION_NOT_FOUND, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	var saved *domain.Delivery
+	h := NewWebhookHandler(service.NewWebhookService(&mocks.WebhookRepository{
+		FindDeliveryFunc: func(ctx context.Context, id string) (*domain.Delivery, error) {
+			return &domain.Delivery{ID: id, SubscriptionID: "sub-1", Status: domain.DeliveryDead}, nil
+		},
+		SaveDeliveryFunc: func(ctx context.Context, delivery *domain.Delivery) error {
+			saved = delivery
+			return nil
+		},
+	}))
+
+	rec := serveWebhook(h.Redeliver, http.MethodPost, "", map[string]string{"id": "sub-1", "deliveryID": "delivery-1"})
+	if rec.Code != http.StatusAccepted || saved == nil || saved.Status != domain.DeliveryPending {
+		t.Errorf("expected 202 and a pending delivery, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveWebhook(h.Redeliver, http.MethodPost, "", map[string]string{"id": "sub-2", "deliveryID": "delivery-1"})
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another subscription's delivery, got %d", rec.Code)
+	}
+}
+
+func TestWebhookHandler_Delete(t *testing.T) {
+	var deleted string
+	h := NewWebhookHandler(service.NewWebhookService(&mocks.WebhookRepository{
+		DeleteSubscriptionFunc: func(ctx context.Context, id string) error {
+			deleted = id
+			return nil
+		},
+	}))
+
+	rec := serveWebhook(h.Delete, http.MethodDelete, "", map[string]string{"id": "sub-1"})
+
+	if rec.Code != http.StatusNoContent || deleted != "sub-1" {
+		t.Errorf("expected 204 deleting sub-1, got %d (%q)", rec.Code, deleted)
+	}
+}
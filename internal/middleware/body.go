@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DefaultMaxBodySize is the request body limit used when none is configured.
+const DefaultMaxBodySize = 1 << 20
+
+// MaxBodySize rejects requests whose declared Content-Length exceeds limit
+// bytes with 413 and caps the body of every other request, so reading past
+// the limit fails with *http.MaxBytesError.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit), "BODY_TOO_LARGE")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"solid/internal/domain"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotency-Replayed"
+)
+
+type idempotentResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	response    *idempotentResponse
+	expiresAt   time.Time
+}
+
+type IdempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *IdempotencyStore) begin(key, fingerprint string) (*idempotencyEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, entry := range s.entries {
+			if entry.response != nil && now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if entry, exists := s.entries[key]; exists {
+		if entry.response == nil || now.Before(entry.expiresAt) {
+			return entry, false
+		}
+	}
+
+	s.entries[key] = &idempotencyEntry{fingerprint: fingerprint}
+	return nil, true
+}
+
+func (s *IdempotencyStore) complete(key string, response *idempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if response.statusCode >= http.StatusInternalServerError {
+		delete(s.entries, key)
+		return
+	}
+
+	entry := s.entries[key]
+	entry.response = response
+	entry.expiresAt = s.now().Add(s.ttl)
+}
+
+func (s *IdempotencyStore) abandon(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
+
+// Idempotency stores the first response to a POST, PUT or PATCH carrying an
+// Idempotency-Key and replays it for retries. It buffers the body, so it
+// must run behind MaxBodySize.
+func Idempotency(store *IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !idempotentMethods[r.Method] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), "BODY_TOO_LARGE")
+				return
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "could not read request body", "INVALID_BODY")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scopedKey := domain.ActorFromContext(r.Context()) + "\x00" + key
+			fingerprint := fingerprintRequest(r, body)
+
+			existing, started := store.begin(scopedKey, fingerprint)
+			if !started {
+				switch {
+				case existing.fingerprint != fingerprint:
+					writeJSONError(w, http.StatusUnprocessableEntity, "idempotency key was already used with a different request", "IDEMPOTENCY_KEY_REUSED")
+				case existing.response == nil:
+					writeJSONError(w, http.StatusConflict, "a request with this idempotency key is still in progress", "IDEMPOTENCY_REQUEST_IN_PROGRESS")
+				default:
+					replay(w, existing.response)
+				}
+				return
+			}
+
+			recorder := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			completed := false
+			defer func() {
+				if !completed {
+					store.abandon(scopedKey)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			store.complete(scopedKey, &idempotentResponse{
+				statusCode: recorder.statusCode,
+				header:     w.Header().Clone(),
+				body:       recorder.body.Bytes(),
+			})
+			completed = true
+		})
+	}
+}
+
+// idempotentMethods are the methods whose retries could apply a change
+// twice: a retried PUT still records a second update in the history.
+var idempotentMethods = map[string]bool{
+	http.MethodPost:  true,
+	http.MethodPut:   true,
+	http.MethodPatch: true,
+}
+
+func fingerprintRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RequestURI()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, response *idempotentResponse) {
+	for name, values := range response.header {
+		if name == RequestIDHeader {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotencyReplayedHeader, "true")
+	w.WriteHeader(response.statusCode)
+	w.Write(response.body)
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func writeJSONError(w http.ResponseWriter, code int, message, errorCode string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+		"code":  errorCode,
+	})
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+)
+
+func newIdempotentHandler(store *IdempotencyStore, calls *int32, status int) http.Handler {
+	return MaxBodySize(64)(Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		w.Header().Set("Location", "/books/1")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d}`, n)
+	})))
+}
+
+func send(h http.Handler, method, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/books", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("replays the first response", func(t *testing.T) {
+		var calls int32
+		h := newIdempotentHandler(NewIdempotencyStore(time.Hour), &calls, http.StatusCreated)
+
+		first := send(h, http.MethodPost, "key-1", `{"title":"Clean Code"}`)
+		second := send(h, http.MethodPost, "key-1", `{"title":"Clean Code"}`)
+
+		if calls != 1 {
+			t.Errorf("expected handler to run once, ran %d times", calls)
+		}
+		if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+			t.Errorf("expected replayed response, got %d %s", second.Code, second.Body.String())
+		}
+		if second.Header().Get("Location") != "/books/1" || second.Header().Get(IdempotencyReplayedHeader) != "true" {
+			t.Errorf("expected replayed headers, got %v", second.Header())
+		}
+	})
+
+	t.Run("rejects key reuse with a different body", func(t *testing.T) {
+		var calls int32
+		h := newIdempotentHandler(NewIdempotencyStore(time.Hour), &calls, http.StatusCreated)
+
+		send(h, http.MethodPost, "key-1", `{"title":"Clean Code"}`)
+		rec := send(h, http.MethodPost, "key-1", `{"title":"Refactoring"}`)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d", rec.Code)
+		}
+	})
+
+	t.Run("rejects concurrent duplicates", func(t *testing.T) {
+		store := NewIdempotencyStore(time.Hour)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		h := Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			send(h, http.MethodPost, "key-1", `{}`)
+			close(done)
+		}()
+		<-started
+		rec := send(h, http.MethodPost, "key-1", `{}`)
+		close(release)
+		<-done
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected 409, got %d", rec.Code)
+		}
+	})
+
+	t.Run("keys are scoped per principal", func(t *testing.T) {
+		var calls int32
+		h := newIdempotentHandler(NewIdempotencyStore(time.Hour), &calls, http.StatusCreated)
+
+		for _, actor := range []string{"alice", "bob"} {
+			req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{}`))
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+			req = req.WithContext(domain.WithActor(req.Context(), actor))
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		if calls != 2 {
+			t.Errorf("expected handler to run for each principal, ran %d times", calls)
+		}
+	})
+
+	t.Run("server errors are not cached", func(t *testing.T) {
+		var calls int32
+		h := newIdempotentHandler(NewIdempotencyStore(time.Hour), &calls, http.StatusInternalServerError)
+
+		send(h, http.MethodPost, "key-1", `{}`)
+		send(h, http.MethodPost, "key-1", `{}`)
+
+		if calls != 2 {
+			t.Errorf("expected retry after server error, ran %d times", calls)
+		}
+	})
+
+	t.Run("entries expire after ttl", func(t *testing.T) {
+		var calls int32
+		store := NewIdempotencyStore(time.Minute)
+		now := time.Now()
+		store.now = func() time.Time { return now }
+		h := newIdempotentHandler(store, &calls, http.StatusCreated)
+
+		send(h, http.MethodPost, "key-1", `{}`)
+		store.now = func() time.Time { return now.Add(2 * time.Minute) }
+		send(h, http.MethodPost, "key-1", `{}`)
+
+		if calls != 2 {
+			t.Errorf("expected expired key to run again, ran %d times", calls)
+		}
+	})
+
+	t.Run("replays updates", func(t *testing.T) {
+		var calls int32
+		h := newIdempotentHandler(NewIdempotencyStore(time.Hour), &calls, http.StatusOK)
+
+		send(h, http.MethodPut, "key-1", `{"title":"Clean Coder"}`)
+		second := send(h, http.MethodPut, "key-1", `{"title":"Clean Coder"}`)
+
+		if calls != 1 || second.Header().Get(IdempotencyReplayedHeader) != "true" {
+			t.Errorf("expected the retried update to be replayed, ran %d times", calls)
+		}
+	})
+
+	t.Run("body limit comes from MaxBodySize", func(t *testing.T) {
+		var calls int32
+		h := newIdempotentHandler(NewIdempotencyStore(time.Hour), &calls, http.StatusCreated)
+		// A reader without a length reaches the handler, so the limit is
+		// hit while buffering.
+		req := httptest.NewRequest(http.MethodPost, "/books", struct{ io.Reader }{strings.NewReader(strings.Repeat("x", 65))})
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusRequestEntityTooLarge || calls != 0 {
+			t.Errorf("expected 413 before the handler, got %d after %d calls", rec.Code, calls)
+		}
+	})
+
+	t.Run("ignores other methods and missing keys", func(t *testing.T) {
+		var calls int32
+		h := newIdempotentHandler(NewIdempotencyStore(time.Hour), &calls, http.StatusOK)
+
+		send(h, http.MethodDelete, "key-1", `{}`)
+		send(h, http.MethodDelete, "key-1", `{}`)
+		send(h, http.MethodPost, "", `{}`)
+		send(h, http.MethodPost, "", `{}`)
+
+		if calls != 4 {
+			t.Errorf("expected every request to run, ran %d times", calls)
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"solid/internal/domain"
 )
 
 type responseWriter struct {
@@ -16,6 +18,10 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -28,11 +34,12 @@ func Logger(next http.Handler) http.Handler {
 		next.ServeHTTP(wrapped, r)
 
 		log.Printf(
-			"%s %s %d %s",
+			"%s %s %d %s request_id=%s",
 			r.Method,
 			r.RequestURI,
 			wrapped.statusCode,
 			time.Since(start),
+			domain.RequestIDFromContext(r.Context()),
 		)
 	})
 }
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"solid/internal/render"
+)
+
+// Negotiate rejects requests before they reach a handler when no registered
+// codec can produce a media type the Accept header allows (406) or when the
+// body has no Content-Type or one without a codec (415). Chunked bodies,
+// whose length is unknown here, are checked again when the handler reads
+// them.
+func Negotiate(codecs *render.Registry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+
+			if len(codecs.Negotiate(r.Header.Get("Accept"))) == 0 {
+				writeJSONError(w, http.StatusNotAcceptable, "none of the accepted media types can be produced", "NOT_ACCEPTABLE")
+				return
+			}
+			if contentType := r.Header.Get("Content-Type"); r.ContentLength > 0 && contentType == "" {
+				writeJSONError(w, http.StatusUnsupportedMediaType, "request body has no Content-Type", "UNSUPPORTED_MEDIA_TYPE")
+				return
+			} else if contentType != "" && r.ContentLength != 0 {
+				if _, ok := codecs.Lookup(contentType); !ok {
+					writeJSONError(w, http.StatusUnsupportedMediaType, "unsupported content type "+contentType, "UNSUPPORTED_MEDIA_TYPE")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
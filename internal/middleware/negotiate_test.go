@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"solid/internal/router/routertest"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	r := routertest.New(t)
+	serve := func(method, path, accept, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	expect := func(t *testing.T, rec *httptest.ResponseRecorder, status int, contentType, body string) {
+		t.Helper()
+		if rec.Code != status || rec.Header().Get("Content-Type") != contentType || !strings.Contains(rec.Body.String(), body) {
+			t.Errorf("expected %d %s containing %q, got %d %s:\n%s",
+				status, contentType, body, rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+		}
+	}
+
+	created := serve(http.MethodPost, "/v1/books", "application/xml", "application/yaml",
+		"title: Clean Code\nauthor: Robert Martin\nisbn: 0132350882\n")
+	expect(t, created, http.StatusCreated, "application/xml", "<title>Clean Code</title>")
+	serve(http.MethodPost, "/v1/books", "", "application/xml",
+		"<book><title>Refactoring</title><author>Martin Fowler</author><isbn>0201485672</isbn></book>")
+
+	t.Run("list as csv", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books", "text/csv", "", "")
+		expect(t, rec, http.StatusOK, "text/csv", "id,title,author,isbn,created_at,updated_at\n")
+		if lines := strings.Count(rec.Body.String(), "\n"); lines != 3 {
+			t.Errorf("expected a header and two rows, got %d lines", lines)
+		}
+	})
+
+	t.Run("list as yaml", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books", "application/yaml", "", "")
+		expect(t, rec, http.StatusOK, "application/yaml", "- id: ")
+	})
+
+	t.Run("quality values", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books", "application/json;q=0.5, application/msgpack", "", "")
+		expect(t, rec, http.StatusOK, "application/msgpack", "Refactoring")
+	})
+
+	t.Run("csv is only for lists", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books/suggest?prefix=cle", "text/csv", "", "")
+		expect(t, rec, http.StatusOK, "text/csv", "Clean Code")
+
+		rec = serve(http.MethodGet, "/v1/books/duplicates", "text/csv", "", "")
+		expect(t, rec, http.StatusOK, "text/csv", "")
+
+		rec = serve(http.MethodGet, "/v1/books/changes", "text/csv", "", "")
+		expect(t, rec, http.StatusNotAcceptable, "application/json", "NOT_ACCEPTABLE")
+	})
+
+	t.Run("errors fall back to json", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books/missing", "text/csv", "", "")
+		expect(t, rec, http.StatusNotFound, "application/json", "BOOK_NOT_FOUND")
+
+		rec = serve(http.MethodGet, "/v1/books/missing", "application/xml", "", "")
+		expect(t, rec, http.StatusNotFound, "application/xml", "<code>BOOK_NOT_FOUND</code>")
+	})
+
+	t.Run("unsupported accept", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/v1/books", "image/png", "application/json",
+			`{"title":"Domain-Driven Design","author":"Eric Evans","isbn":"0321125215"}`)
+		expect(t, rec, http.StatusNotAcceptable, "application/json", "NOT_ACCEPTABLE")
+
+		if list := serve(http.MethodGet, "/v1/books", "", "", ""); strings.Contains(list.Body.String(), "Domain-Driven Design") {
+			t.Error("expected the rejected request not to create a book")
+		}
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/v1/books", "", "text/plain", "Clean Code")
+		expect(t, rec, http.StatusUnsupportedMediaType, "application/json", "UNSUPPORTED_MEDIA_TYPE")
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/v1/books", "", "application/xml", "<book><title>")
+		expect(t, rec, http.StatusBadRequest, "application/json", "INVALID_JSON")
+	})
+}
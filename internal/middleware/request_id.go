@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"solid/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// ActorHeader names the actor recorded in the audit history. It is taken
+	// on trust: nothing authenticates it, so any client can claim any actor.
+	// Deployments that rely on the history for accountability must set it in
+	// an authenticating proxy and strip it from client requests.
+	ActorHeader = "X-Actor"
+)
+
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := domain.WithRequestID(r.Context(), requestID)
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			ctx = domain.WithActor(ctx, actor)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	APIVersionHeader  = "API-Version"
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+)
+
+// Deprecation describes when an API version was deprecated and when it will
+// be removed. Zero times leave the matching header out.
+type Deprecation struct {
+	At     time.Time
+	Sunset time.Time
+}
+
+// RequestedVersion returns the version parameter of the Accept header, so
+// "application/json; version=2" asks for version "2". It returns "" when no
+// media range names a version.
+func RequestedVersion(r *http.Request) string {
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if version, ok := params["version"]; ok {
+			return strings.TrimPrefix(strings.ToLower(version), "v")
+		}
+	}
+	return ""
+}
+
+// RequireVersion marks responses of a versioned route group and rejects
+// requests whose Accept header asks for a different version.
+func RequireVersion(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requested := RequestedVersion(r); requested != "" && requested != version {
+				writeJSONError(w, http.StatusNotAcceptable,
+					fmt.Sprintf("API version %s was requested from /v%s", requested, version), "UNSUPPORTED_API_VERSION")
+				return
+			}
+			w.Header().Set(APIVersionHeader, version)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// VersionFallback serves unversioned paths such as /books. The version comes
+// from the Accept header when given; otherwise the request is served by
+// defaultVersion with Deprecation, Sunset and successor Link headers telling
+// the client to move to the versioned path. Install it as the router's
+// NotFoundHandler so it only sees paths no route matched.
+func VersionFallback(router http.Handler, versions []string, defaultVersion string, deprecation Deprecation) http.Handler {
+	supported := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		supported[version] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for version := range supported {
+			prefix := "/v" + version
+			if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		version := RequestedVersion(r)
+		switch {
+		case version == "":
+			version = defaultVersion
+			successor := "/v" + version + r.URL.Path
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+			if !deprecation.At.IsZero() {
+				w.Header().Set(DeprecationHeader, "@"+strconv.FormatInt(deprecation.At.Unix(), 10))
+			}
+			if !deprecation.Sunset.IsZero() {
+				w.Header().Set(SunsetHeader, deprecation.Sunset.UTC().Format(http.TimeFormat))
+			}
+		case !supported[version]:
+			writeJSONError(w, http.StatusNotAcceptable,
+				fmt.Sprintf("API version %s is not supported", version), "UNSUPPORTED_API_VERSION")
+			return
+		}
+
+		versioned := r.Clone(r.Context())
+		versioned.URL.Path = "/v" + version + r.URL.Path
+		versioned.URL.RawPath = ""
+		router.ServeHTTP(w, versioned)
+	})
+}
@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"solid/internal/router/routertest"
+)
+
+func TestVersioning(t *testing.T) {
+	r := routertest.New(t)
+	serve := func(method, path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("versioned path", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/v1/books", "application/json")
+
+		if rec.Code != http.StatusOK || rec.Header().Get("API-Version") != "1" {
+			t.Errorf("expected 200 from version 1, got %d %v", rec.Code, rec.Header())
+		}
+		if rec.Header().Get("Deprecation") != "" {
+			t.Errorf("expected no deprecation header, got %q", rec.Header().Get("Deprecation"))
+		}
+	})
+
+	t.Run("unversioned path is deprecated", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/books?limit=5", "")
+
+		if rec.Code != http.StatusOK || rec.Header().Get("API-Version") != "1" {
+			t.Fatalf("expected 200 from version 1, got %d %v", rec.Code, rec.Header())
+		}
+		if got := rec.Header().Get("Deprecation"); got != "@"+strconv.FormatInt(routertest.DeprecatedAt.Unix(), 10) {
+			t.Errorf("unexpected Deprecation %q", got)
+		}
+		if got := rec.Header().Get("Sunset"); got != "Thu, 01 Apr 2027 00:00:00 GMT" {
+			t.Errorf("unexpected Sunset %q", got)
+		}
+		if got := rec.Header().Get("Link"); got != `</v1/books>; rel="successor-version"` {
+			t.Errorf("unexpected Link %q", got)
+		}
+	})
+
+	t.Run("version from accept header", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/books", "application/json; version=1")
+
+		if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "" {
+			t.Errorf("expected an undeprecated 200, got %d %v", rec.Code, rec.Header())
+		}
+	})
+
+	t.Run("unsupported versions", func(t *testing.T) {
+		for _, path := range []string{"/books", "/v1/books"} {
+			rec := serve(http.MethodGet, path, "application/json; version=2")
+
+			if rec.Code != http.StatusNotAcceptable || !strings.Contains(rec.Body.String(), "UNSUPPORTED_API_VERSION") {
+				t.Errorf("%s: expected 406, got %d %s", path, rec.Code, rec.Body.String())
+			}
+		}
+	})
+
+	t.Run("unknown paths", func(t *testing.T) {
+		for _, path := range []string{"/missing", "/v1/missing", "/v1"} {
+			if rec := serve(http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected 404, got %d", path, rec.Code)
+			}
+		}
+		if rec := serve(http.MethodPatch, "/books", ""); rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"solid/internal/domain"
+)
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	MaxAttempts  int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+		MaxAttempts:  10,
+	}
+}
+
+type Dispatcher struct {
+	repository domain.OutboxRepository
+	sinks      []Sink
+	config     Config
+	now        func() time.Time
+}
+
+func NewDispatcher(repository domain.OutboxRepository, config Config, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		repository: repository,
+		sinks:      sinks,
+		config:     config,
+		now:        time.Now,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	messages, err := d.repository.PendingOutbox(ctx, d.now(), d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+
+		if err := d.deliver(ctx, msg); err != nil {
+			if markErr := d.fail(ctx, msg, err); markErr != nil {
+				return delivered, markErr
+			}
+			continue
+		}
+
+		if err := d.repository.MarkDelivered(ctx, msg.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, msg *domain.OutboxMessage) error {
+	var failures []string
+	for _, sink := range d.sinks {
+		if err := sink.Deliver(ctx, msg); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("delivery failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// fail schedules another attempt, or moves the message to the dead letters
+// once it has failed MaxAttempts times.
+func (d *Dispatcher) fail(ctx context.Context, msg *domain.OutboxMessage, err error) error {
+	if d.exhausted(msg) {
+		log.Printf("outbox message %s dead after %d attempts: %v", msg.ID, msg.Attempts+1, err)
+		return d.repository.MarkDead(ctx, msg.ID, err.Error())
+	}
+	next := d.now().Add(d.backoff(msg.Attempts))
+	return d.repository.MarkFailed(ctx, msg.ID, err.Error(), next)
+}
+
+func (d *Dispatcher) exhausted(msg *domain.OutboxMessage) bool {
+	return msg.Attempts+1 >= d.config.MaxAttempts
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+	"solid/internal/repository"
+)
+
+type recordingSink struct {
+	delivered []string
+	fail      bool
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Deliver(ctx context.Context, msg *domain.OutboxMessage) error {
+	if s.fail {
+		return errors.New("sink unavailable")
+	}
+	s.delivered = append(s.delivered, msg.ID)
+	return nil
+}
+
+func TestDispatcher_DispatchPending(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers and removes messages", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		sink := &recordingSink{}
+		dispatcher := NewDispatcher(repo, DefaultConfig(), sink)
+
+		delivered, err := dispatcher.DispatchPending(ctx)
+
+		if err != nil || delivered != 1 {
+			t.Fatalf("expected 1 delivery, got %d (%v)", delivered, err)
+		}
+		pending, _ := repo.PendingOutbox(ctx, time.Now(), 0)
+		if len(pending) != 0 {
+			t.Errorf("expected outbox to be drained, got %d", len(pending))
+		}
+	})
+
+	t.Run("retries with backoff", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		sink := &recordingSink{fail: true}
+		now := time.Now()
+		dispatcher := NewDispatcher(repo, DefaultConfig(), sink)
+		dispatcher.now = func() time.Time { return now }
+
+		dispatcher.DispatchPending(ctx)
+
+		pending, _ := repo.PendingOutbox(ctx, now, 0)
+		if len(pending) != 0 {
+			t.Fatal("expected failed message to wait for its backoff")
+		}
+		pending, _ = repo.PendingOutbox(ctx, now.Add(time.Second), 0)
+		if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError == "" {
+			t.Fatalf("expected 1 message with recorded failure, got %+v", pending)
+		}
+
+		sink.fail = false
+		dispatcher.now = func() time.Time { return now.Add(time.Second) }
+		delivered, _ := dispatcher.DispatchPending(ctx)
+
+		if delivered != 1 || sink.delivered[0] != pending[0].ID {
+			t.Errorf("expected retried delivery with the same deduplication ID, got %v", sink.delivered)
+		}
+	})
+
+	t.Run("dead letters after max attempts", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		sink := &recordingSink{fail: true}
+		now := time.Now()
+		config := DefaultConfig()
+		config.MaxAttempts = 2
+		dispatcher := NewDispatcher(repo, config, sink)
+		dispatcher.now = func() time.Time { return now }
+
+		dispatcher.DispatchPending(ctx)
+		dispatcher.now = func() time.Time { return now.Add(time.Hour) }
+		dispatcher.DispatchPending(ctx)
+
+		pending, _ := repo.PendingOutbox(ctx, now.Add(24*time.Hour), 0)
+		if len(pending) != 0 {
+			t.Fatalf("expected no pending messages, got %d", len(pending))
+		}
+		dead, _ := repo.DeadOutbox(ctx)
+		if len(dead) != 1 || dead[0].Attempts != 2 || dead[0].DeadAt == nil || dead[0].LastError == "" {
+			t.Errorf("expected 1 dead letter after 2 attempts, got %+v", dead)
+		}
+	})
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewDispatcher(nil, Config{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := dispatcher.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookSink_Deliver(t *testing.T) {
+	ctx := context.Background()
+	var gotID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = r.Header.Get(DeduplicationHeader)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	err := NewWebhookSink(server.URL).Deliver(ctx, &domain.OutboxMessage{ID: "msg-1"})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if gotID != "msg-1" {
+		t.Errorf("expected deduplication header msg-1, got %q", gotID)
+	}
+}
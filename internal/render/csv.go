@@ -0,0 +1,144 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// CSV renders lists of objects, one row per element. Nested objects are
+// flattened into dotted columns (snapshot.title) and nested lists are
+// written as JSON. Anything else is ErrUnsupported.
+type CSV struct{}
+
+func (CSV) MediaTypes() []string {
+	return []string{"text/csv"}
+}
+
+func (CSV) Encode(w io.Writer, v interface{}) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	rows, ok := tree.([]interface{})
+	if !ok {
+		return ErrUnsupported
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	records := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		obj, ok := row.(object)
+		if !ok {
+			return ErrUnsupported
+		}
+		record := make(map[string]string)
+		keys, err := flatten("", obj, record)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+		records = append(records, record)
+	}
+
+	writer := csv.NewWriter(w)
+	if len(columns) > 0 {
+		writer.Write(columns)
+	}
+	for _, record := range records {
+		line := make([]string, len(columns))
+		for i, column := range columns {
+			line[i] = record[column]
+		}
+		writer.Write(line)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// flatten stores the members of obj in record and returns their columns in
+// order.
+func flatten(prefix string, obj object, record map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(obj))
+	for _, m := range obj {
+		key := prefix + m.key
+		switch value := m.value.(type) {
+		case object:
+			nested, err := flatten(key+".", value, record)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, nested...)
+			continue
+		case []interface{}:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			record[key] = string(data)
+		case nil:
+			record[key] = ""
+		default:
+			record[key] = fmt.Sprint(value)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Decode reads a header row and stores the rows in v: all of them when v is
+// a slice, otherwise the only one.
+func (CSV) Decode(r io.Reader, v interface{}) error {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return errors.New("csv: missing header row")
+	}
+
+	header := rows[0]
+	records := make([]interface{}, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{})
+		for i, column := range header {
+			unflatten(record, strings.Split(column, "."), row[i])
+		}
+		records = append(records, record)
+	}
+
+	typ := reflect.TypeOf(v)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Slice {
+		return fromTree(records, v)
+	}
+	if len(records) != 1 {
+		return fmt.Errorf("csv: expected one row, got %d", len(records))
+	}
+	return fromTree(records[0], v)
+}
+
+func unflatten(record map[string]interface{}, path []string, value string) {
+	if len(path) == 1 {
+		record[path[0]] = value
+		return
+	}
+	nested, ok := record[path[0]].(map[string]interface{})
+	if !ok {
+		nested = make(map[string]interface{})
+		record[path[0]] = nested
+	}
+	unflatten(nested, path[1:], value)
+}
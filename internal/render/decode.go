@@ -0,0 +1,138 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DecodeError describes a request body that does not decode into the value
+// it is read into. Field is the dotted path of the offending field, if any,
+// and Offset the 0-based byte offset in the body of the offending key or
+// character, or -1 when the codec cannot tell.
+type DecodeError struct {
+	Field   string
+	Offset  int64
+	Message string
+}
+
+func (e *DecodeError) Error() string {
+	if e.Offset < 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s at byte offset %d", e.Message, e.Offset)
+}
+
+// decodeJSON reads exactly one JSON value into v, rejecting fields that v
+// does not declare. Errors other than read errors are *DecodeError.
+func decodeJSON(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return jsonDecodeError(data, err, dec.InputOffset())
+	}
+	if end := dec.InputOffset(); len(bytes.TrimSpace(data[end:])) > 0 {
+		return &DecodeError{Offset: skipSpace(data, end), Message: "unexpected data after the JSON value"}
+	}
+	return nil
+}
+
+func jsonDecodeError(data []byte, err error, offset int64) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return &DecodeError{Offset: 0, Message: "request body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &DecodeError{Offset: int64(len(data)), Message: "request body ends in the middle of a JSON value"}
+	case errors.As(err, &syntaxErr):
+		// The offending character is the last one read.
+		return &DecodeError{Offset: syntaxErr.Offset - 1, Message: "malformed JSON: " + syntaxErr.Error()}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return &DecodeError{Offset: skipSpace(data, 0), Message: fmt.Sprintf("expected a %s, got a %s", jsonKind(typeErr.Type), typeErr.Value)}
+		}
+		path := strings.Split(typeErr.Field, ".")
+		return &DecodeError{
+			Field:   typeErr.Field,
+			Offset:  keyOffset(data, path[len(path)-1], typeErr.Offset),
+			Message: fmt.Sprintf("field %q must be a %s, got a %s", typeErr.Field, jsonKind(typeErr.Type), typeErr.Value),
+		}
+	}
+	// encoding/json reports unknown fields only through the message.
+	if name, ok := strings.CutPrefix(err.Error(), `json: unknown field "`); ok {
+		name = strings.TrimSuffix(name, `"`)
+		return &DecodeError{Field: name, Offset: keyOffset(data, name, offset), Message: fmt.Sprintf("unknown field %q", name)}
+	}
+	return err
+}
+
+func skipSpace(data []byte, offset int64) int64 {
+	return int64(len(data) - len(bytes.TrimLeft(data[offset:], " \t\r\n")))
+}
+
+// keyOffset returns the offset of the opening quote of the first object key
+// named name in data, or fallback when there is none.
+func keyOffset(data []byte, name string, fallback int64) int64 {
+	type frame struct{ object, key bool }
+	var stack []frame
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		start := dec.InputOffset()
+		token, err := dec.Token()
+		if err != nil {
+			return fallback
+		}
+		if key, ok := token.(string); ok && len(stack) > 0 && stack[len(stack)-1].key {
+			stack[len(stack)-1].key = false
+			if key == name {
+				// Only whitespace and a comma precede the key's opening quote.
+				return start + int64(bytes.IndexByte(data[start:], '"'))
+			}
+			continue
+		}
+		switch token {
+		case json.Delim('{'):
+			stack = append(stack, frame{object: true, key: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, frame{})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].key = true
+		}
+	}
+}
+
+// jsonKind names the JSON type that decodes into typ.
+func jsonKind(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return typ.String()
+}
@@ -0,0 +1,22 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type JSON struct{}
+
+func (JSON) MediaTypes() []string {
+	return []string{"application/json"}
+}
+
+func (JSON) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode reads a single JSON value and rejects fields v does not declare,
+// reporting problems as *DecodeError.
+func (JSON) Decode(r io.Reader, v interface{}) error {
+	return decodeJSON(r, v)
+}
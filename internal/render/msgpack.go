@@ -0,0 +1,85 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type MessagePack struct{}
+
+func (MessagePack) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+func (MessagePack) Encode(w io.Writer, v interface{}) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	return encodeMessagePack(msgpack.NewEncoder(w), tree)
+}
+
+func (MessagePack) Decode(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	body := bytes.NewReader(data)
+	document, err := msgpack.NewDecoder(body).DecodeInterface()
+	if err != nil {
+		return err
+	}
+	if body.Len() > 0 {
+		return &DecodeError{Offset: body.Size() - int64(body.Len()), Message: "unexpected data after the MessagePack value"}
+	}
+	return fromTree(document, v)
+}
+
+func encodeMessagePack(enc *msgpack.Encoder, value interface{}) error {
+	switch value := value.(type) {
+	case object:
+		if err := enc.EncodeMapLen(len(value)); err != nil {
+			return err
+		}
+		for _, m := range value {
+			if err := enc.EncodeString(m.key); err != nil {
+				return err
+			}
+			if err := encodeMessagePack(enc, m.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if err := enc.EncodeArrayLen(len(value)); err != nil {
+			return err
+		}
+		for _, item := range value {
+			if err := encodeMessagePack(enc, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case string:
+		return enc.EncodeString(value)
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return enc.EncodeInt(i)
+		}
+		if u, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return enc.EncodeUint(u)
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return err
+		}
+		return enc.EncodeFloat64(f)
+	case bool:
+		return enc.EncodeBool(value)
+	}
+	return enc.EncodeNil()
+}
@@ -0,0 +1,182 @@
+// Package render encodes response bodies and decodes request bodies in the
+// media type a client negotiates. JSON is the canonical representation: the
+// other codecs convert to and from it, so every format uses the same field
+// names and order as the json tags of the wire types.
+package render
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupported is returned by a codec that cannot represent a value, such
+// as CSV for anything but a list of objects.
+var ErrUnsupported = errors.New("render: value not supported by this codec")
+
+// ErrNotAcceptable is returned by Registry.Encode when no codec accepted by
+// the client can represent the value.
+var ErrNotAcceptable = errors.New("render: no acceptable media type")
+
+type Codec interface {
+	// MediaTypes lists the media types the codec handles, the one written in
+	// Content-Type first.
+	MediaTypes() []string
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+type Registry struct {
+	mu     sync.RWMutex
+	codecs []Codec
+}
+
+func NewRegistry(codecs ...Codec) *Registry {
+	return &Registry{codecs: codecs}
+}
+
+// Default serves JSON, XML, CSV, YAML and MessagePack, with JSON used when
+// the client expresses no preference.
+var Default = NewRegistry(JSON{}, XML{}, CSV{}, YAML{}, MessagePack{})
+
+// Register adds a codec to the Default registry.
+func Register(codec Codec) {
+	Default.Register(codec)
+}
+
+// Register adds a codec. Codecs registered earlier win ties in negotiation
+// and media types they share.
+func (r *Registry) Register(codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs = append(r.codecs, codec)
+}
+
+// Lookup returns the codec for a Content-Type header value.
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, codec := range r.codecs {
+		for _, candidate := range codec.MediaTypes() {
+			if candidate == mediaType {
+				return codec, true
+			}
+		}
+	}
+	return nil, false
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// Negotiate returns the codecs acceptable for an Accept header value, most
+// preferred first. An empty header accepts every codec, JSON first.
+func (r *Registry) Negotiate(accept string) []Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if strings.TrimSpace(accept) == "" {
+		return append([]Codec(nil), r.codecs...)
+	}
+
+	ranges := parseAccept(accept)
+	type candidate struct {
+		codec       Codec
+		q           float64
+		specificity int
+	}
+	var candidates []candidate
+	for _, codec := range r.codecs {
+		best := candidate{codec: codec, q: -1, specificity: -1}
+		for _, mediaType := range codec.MediaTypes() {
+			typ, subtype, _ := strings.Cut(mediaType, "/")
+			for _, rng := range ranges {
+				specificity := 0
+				switch {
+				case rng.typ == typ && rng.subtype == subtype:
+					specificity = 2
+				case rng.typ == typ && rng.subtype == "*":
+					specificity = 1
+				case rng.typ == "*" && rng.subtype == "*":
+				default:
+					continue
+				}
+				// The most specific matching range decides the quality.
+				if specificity > best.specificity || (specificity == best.specificity && rng.q > best.q) {
+					best.q, best.specificity = rng.q, specificity
+				}
+			}
+		}
+		if best.q > 0 {
+			candidates = append(candidates, best)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		return candidates[i].specificity > candidates[j].specificity
+	})
+	codecs := make([]Codec, 0, len(candidates))
+	for _, c := range candidates {
+		codecs = append(codecs, c.codec)
+	}
+	return codecs
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// Encode renders v with the most preferred acceptable codec that supports
+// it and returns that codec with the encoded body.
+func (r *Registry) Encode(accept string, v interface{}) (Codec, []byte, error) {
+	for _, codec := range r.Negotiate(accept) {
+		var buf bytes.Buffer
+		err := codec.Encode(&buf, v)
+		if errors.Is(err, ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return codec, buf.Bytes(), nil
+	}
+	return nil, nil, ErrNotAcceptable
+}
+
+// ContentType is the media type a codec writes in Content-Type.
+func ContentType(codec Codec) string {
+	return codec.MediaTypes()[0]
+}
@@ -0,0 +1,237 @@
+package render_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"solid/internal/render"
+)
+
+type book struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Pages     int        `json:"pages"`
+	Signed    bool       `json:"signed"`
+	Tags      []string   `json:"tags"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func sample() book {
+	return book{
+		ID:        "1",
+		Title:     "Clean Code",
+		Pages:     464,
+		Signed:    true,
+		Tags:      []string{"craft", "classic"},
+		CreatedAt: time.Date(2008, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func mediaType(codec render.Codec) string {
+	if codec == nil {
+		return ""
+	}
+	return render.ContentType(codec)
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/xml", "application/xml"},
+		{"application/json; version=1", "application/json"},
+		{"application/x-yaml", "application/yaml"},
+		{"application/vnd.msgpack", "application/msgpack"},
+		{"text/csv", "text/csv"},
+		{"application/xml;q=0.5, application/yaml", "application/yaml"},
+		{"application/*;q=0.2, application/xml;q=0.9", "application/xml"},
+		{"*/*;q=0.1, text/csv", "text/csv"},
+		{"application/json;q=0, */*", "application/xml"},
+		{"image/png", ""},
+	}
+
+	for _, tt := range tests {
+		codecs := render.Default.Negotiate(tt.accept)
+		var got render.Codec
+		if len(codecs) > 0 {
+			got = codecs[0]
+		}
+		if mediaType(got) != tt.want {
+			t.Errorf("Accept %q: expected %q, got %q", tt.accept, tt.want, mediaType(got))
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, contentType := range []string{"application/json", "application/xml", "application/yaml", "application/msgpack"} {
+		t.Run(contentType, func(t *testing.T) {
+			codec, ok := render.Default.Lookup(contentType)
+			if !ok {
+				t.Fatalf("no codec for %s", contentType)
+			}
+
+			var buf bytes.Buffer
+			if err := codec.Encode(&buf, sample()); err != nil {
+				t.Fatalf("unexpected encode error: %v", err)
+			}
+			var decoded book
+			if err := codec.Decode(&buf, &decoded); err != nil {
+				t.Fatalf("unexpected decode error: %v", err)
+			}
+
+			want := sample()
+			if decoded.Title != want.Title || decoded.Pages != want.Pages || !decoded.Signed ||
+				strings.Join(decoded.Tags, ",") != "craft,classic" || !decoded.CreatedAt.Equal(want.CreatedAt) {
+				t.Errorf("expected %+v, got %+v", want, decoded)
+			}
+		})
+	}
+}
+
+func TestXML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (render.XML{}).Encode(&buf, map[string]interface{}{"fields": map[string]string{"1st": "x"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<response><fields><entry key="1st">x</entry></fields></response>`) {
+		t.Errorf("unexpected document %s", buf.String())
+	}
+
+	var decoded struct {
+		Title  string            `json:"title"`
+		Tags   []string          `json:"tags"`
+		Fields map[string]string `json:"fields"`
+	}
+	body := `<book><title>Refactoring</title><tags><item>classic</item></tags><fields><entry key="1st">x</entry></fields></book>`
+	if err := (render.XML{}).Decode(strings.NewReader(body), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Title != "Refactoring" || len(decoded.Tags) != 1 || decoded.Fields["1st"] != "x" {
+		t.Errorf("unexpected value %+v", decoded)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	deleted := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	books := []book{sample(), sample()}
+	books[1].ID, books[1].DeletedAt = "2", &deleted
+
+	var buf bytes.Buffer
+	if err := (render.CSV{}).Encode(&buf, books); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "id,title,pages,signed,tags,created_at,deleted_at\n" +
+		`1,Clean Code,464,true,"[""craft"",""classic""]",2008-08-01T00:00:00Z,` + "\n" +
+		`2,Clean Code,464,true,"[""craft"",""classic""]",2008-08-01T00:00:00Z,2024-01-02T00:00:00Z` + "\n"
+	if buf.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, buf.String())
+	}
+
+	if err := (render.CSV{}).Encode(io.Discard, sample()); !errors.Is(err, render.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for a single object, got %v", err)
+	}
+
+	var decoded book
+	if err := (render.CSV{}).Decode(strings.NewReader("title,pages,signed\nRefactoring,448,false\n"), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Title != "Refactoring" || decoded.Pages != 448 || decoded.Signed {
+		t.Errorf("unexpected value %+v", decoded)
+	}
+	if err := (render.CSV{}).Decode(strings.NewReader("title\nA\nB\n"), &decoded); err == nil {
+		t.Error("expected an error for several rows into one value")
+	}
+}
+
+func TestDecodeIsStrict(t *testing.T) {
+	type author struct {
+		Name string `json:"name"`
+	}
+	var decoded struct {
+		Title   string            `json:"title"`
+		Authors []author          `json:"authors"`
+		Fields  map[string]string `json:"fields"`
+	}
+	tests := []struct {
+		body   string
+		field  string
+		offset int64
+	}{
+		{`{"fields":{"name":"x"},"authors":[{"name":"A"},{"nmae":"B"}]}`, "nmae", 48},
+		{`{"authors":[{}],` + "\n" + `  "title":["Clean Code"]}`, "title", 19},
+		{`{"title":"Clean Code"}  x`, "", 24},
+		{`{"title":"Clean Code"`, "", 21},
+		{`[]`, "", 0},
+	}
+	for _, tt := range tests {
+		err := (render.JSON{}).Decode(strings.NewReader(tt.body), &decoded)
+
+		var decodeErr *render.DecodeError
+		if !errors.As(err, &decodeErr) || decodeErr.Field != tt.field || decodeErr.Offset != tt.offset {
+			t.Errorf("%s: expected field %q at %d, got %v", tt.body, tt.field, tt.offset, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	(render.MessagePack{}).Encode(&buf, map[string]string{"title": "A"})
+	(render.MessagePack{}).Encode(&buf, map[string]string{"title": "B"})
+	if err := (render.MessagePack{}).Decode(&buf, &decoded); err == nil {
+		t.Error("expected an error for two MessagePack values")
+	}
+	buf.Reset()
+	(render.MessagePack{}).Encode(&buf, map[string]string{"titel": "A"})
+	var decodeErr *render.DecodeError
+	if err := (render.MessagePack{}).Decode(&buf, &decoded); !errors.As(err, &decodeErr) || decodeErr.Field != "titel" || decodeErr.Offset != -1 {
+		t.Errorf("expected unknown field titel without an offset, got %v", err)
+	}
+}
+
+func TestEncodeFallsBackToNextAcceptableCodec(t *testing.T) {
+	codec, body, err := render.Default.Encode("text/csv, application/json;q=0.5", sample())
+	if err != nil || mediaType(codec) != "application/json" || !bytes.Contains(body, []byte(`"title":"Clean Code"`)) {
+		t.Errorf("expected JSON fallback, got %q %s %v", mediaType(codec), body, err)
+	}
+
+	if _, _, err := render.Default.Encode("text/csv", sample()); !errors.Is(err, render.ErrNotAcceptable) {
+		t.Errorf("expected ErrNotAcceptable, got %v", err)
+	}
+}
+
+type plainText struct{}
+
+func (plainText) MediaTypes() []string { return []string{"text/plain"} }
+
+func (plainText) Encode(w io.Writer, v interface{}) error {
+	b, ok := v.(book)
+	if !ok {
+		return render.ErrUnsupported
+	}
+	_, err := io.WriteString(w, b.Title)
+	return err
+}
+
+func (plainText) Decode(r io.Reader, v interface{}) error {
+	return render.ErrUnsupported
+}
+
+func TestRegister(t *testing.T) {
+	registry := render.NewRegistry(render.JSON{})
+	registry.Register(plainText{})
+
+	codec, body, err := registry.Encode("text/plain", sample())
+	if err != nil || mediaType(codec) != "text/plain" || string(body) != "Clean Code" {
+		t.Errorf("expected the registered codec, got %q %q %v", mediaType(codec), body, err)
+	}
+	if _, ok := registry.Lookup("text/plain; charset=utf-8"); !ok {
+		t.Error("expected Lookup to find the registered codec")
+	}
+}
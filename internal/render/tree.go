@@ -0,0 +1,209 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// object is a JSON object that keeps the order of its members.
+type object []member
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toTree converts v to its JSON document made of object, []interface{},
+// string, json.Number, bool and nil values.
+func toTree(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return readTree(dec)
+}
+
+func readTree(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			item, err := readTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token()
+		return items, err
+	}
+	return token, nil
+}
+
+// fromTree stores a decoded document in v through its JSON representation,
+// as strictly as JSON.Decode.
+// Formats without typed scalars (XML, CSV) only produce strings, so scalars
+// are first converted to the kind of the field they are stored in.
+func fromTree(value interface{}, v interface{}) error {
+	data, err := json.Marshal(coerce(value, reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	// Offsets in the transcoded JSON mean nothing to the client.
+	err = decodeJSON(bytes.NewReader(data), v)
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		decodeErr.Offset = -1
+	}
+	return err
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+func coerce(value interface{}, typ reflect.Type) interface{} {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == timeType || reflect.PointerTo(typ).Implements(unmarshalerType) {
+		return value
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		out := make(map[string]interface{}, len(fields))
+		for key, field := range fields {
+			out[key] = field
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			name := jsonName(typ.Field(i))
+			if field, ok := out[name]; ok && name != "" {
+				out[name] = coerce(field, typ.Field(i).Type)
+			}
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		switch value := value.(type) {
+		case []interface{}:
+			out := make([]interface{}, len(value))
+			for i, item := range value {
+				out[i] = coerce(item, typ.Elem())
+			}
+			return out
+		case nil:
+			return nil
+		case string:
+			if value == "" {
+				return []interface{}{}
+			}
+		}
+		// A list with a single element is indistinguishable from that
+		// element in XML and CSV.
+		return []interface{}{coerce(value, typ.Elem())}
+	case reflect.Map:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		out := make(map[string]interface{}, len(entries))
+		for key, entry := range entries {
+			out[key] = coerce(entry, typ.Elem())
+		}
+		return out
+	case reflect.Bool:
+		if s, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if s, ok := value.(string); ok {
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return json.Number(s)
+			}
+		}
+	case reflect.String:
+		switch value.(type) {
+		case string, nil, map[string]interface{}, []interface{}:
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+	return value
+}
+
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// XML writes the JSON document under a <response> root. Object members
+// become elements named after their keys, list elements become <item>
+// elements, and keys that are not valid XML names are written as
+// <entry key="...">.
+type XML struct{}
+
+func (XML) MediaTypes() []string {
+	return []string{"application/xml", "text/xml"}
+}
+
+func (XML) Encode(w io.Writer, v interface{}) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := writeXML(enc, "response", tree); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func (XML) Decode(r io.Reader, v interface{}) error {
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			document, err := readXML(dec)
+			if err != nil {
+				return err
+			}
+			return fromTree(document, v)
+		}
+	}
+}
+
+func writeXML(enc *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !isXMLName(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case object:
+		for _, m := range value {
+			if err := writeXML(enc, m.key, m.value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if err := writeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(value))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// readXML reads the content of the element whose start was just consumed.
+// Text-only elements become strings, elements holding only <item> children
+// become lists and all others become objects.
+func readXML(dec *xml.Decoder) (interface{}, error) {
+	var text strings.Builder
+	var names []string
+	children := make(map[string][]interface{})
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			child, err := readXML(dec)
+			if err != nil {
+				return nil, err
+			}
+			name := token.Name.Local
+			for _, attr := range token.Attr {
+				if name == "entry" && attr.Name.Local == "key" {
+					name = attr.Value
+				}
+			}
+			if _, seen := children[name]; !seen {
+				names = append(names, name)
+			}
+			children[name] = append(children[name], child)
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			switch {
+			case len(names) == 0:
+				return text.String(), nil
+			case len(names) == 1 && names[0] == "item":
+				return children["item"], nil
+			}
+			obj := make(map[string]interface{}, len(names))
+			for _, name := range names {
+				if values := children[name]; len(values) == 1 {
+					obj[name] = values[0]
+				} else {
+					obj[name] = values
+				}
+			}
+			return obj, nil
+		}
+	}
+}
+
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type YAML struct{}
+
+func (YAML) MediaTypes() []string {
+	return []string{"application/yaml", "application/x-yaml", "text/yaml"}
+}
+
+func (YAML) Encode(w io.Writer, v interface{}) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(tree)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (YAML) Decode(r io.Reader, v interface{}) error {
+	dec := yaml.NewDecoder(r)
+	var document yaml.Node
+	if err := dec.Decode(&document); err != nil {
+		return err
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); err != io.EOF {
+		return &DecodeError{Offset: -1, Message: "request body must hold a single YAML document"}
+	}
+	return fromTree(yamlValue(&document), v)
+}
+
+// yamlValue keeps scalars as written unless they are plain JSON values, so
+// an unquoted ISBN such as 0132350882 is not read as an octal number.
+func yamlValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		obj := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			obj[node.Content[i].Value] = yamlValue(node.Content[i+1])
+		}
+		return obj
+	case yaml.SequenceNode:
+		items := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			items = append(items, yamlValue(item))
+		}
+		return items
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool":
+		if b, err := strconv.ParseBool(node.Value); err == nil {
+			return b
+		}
+	case "!!int", "!!float":
+		if json.Valid([]byte(node.Value)) {
+			return json.Number(node.Value)
+		}
+	}
+	return node.Value
+}
+
+func yamlNode(value interface{}) *yaml.Node {
+	switch value := value.(type) {
+	case object:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, m := range value {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.key}, yamlNode(m.value))
+		}
+		return node
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range value {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value.String()}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: value.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
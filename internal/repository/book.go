@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"solid/internal/domain"
 
@@ -10,19 +12,29 @@ import (
 )
 
 type InMemoryBookRepository struct {
-	mu    sync.RWMutex
-	books map[string]*domain.Book
-	isbn  map[string]string
+	txMu   sync.RWMutex
+	mu     sync.RWMutex
+	books  map[string]*domain.Book
+	isbn   map[string]string
+	merges map[string]*domain.Merge
+	outbox []*domain.OutboxMessage
+	log    []*domain.Change
+	seq    uint64
+
+	history *InMemoryHistoryRepository
 }
 
 func NewInMemoryBookRepository() *InMemoryBookRepository {
 	return &InMemoryBookRepository{
-		books: make(map[string]*domain.Book),
-		isbn:  make(map[string]string),
+		books:   make(map[string]*domain.Book),
+		isbn:    make(map[string]string),
+		merges:  make(map[string]*domain.Merge),
+		history: NewInMemoryHistoryRepository(),
 	}
 }
 
 func (r *InMemoryBookRepository) Create(ctx context.Context, book *domain.Book) error {
+	defer r.acquire(ctx, true)()
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -30,26 +42,36 @@ func (r *InMemoryBookRepository) Create(ctx context.Context, book *domain.Book)
 		return domain.ErrBookAlreadyExists
 	}
 
-	book.ID = uuid.New().String()
-
 	bookCopy := *book
+	bookCopy.ID = uuid.New().String()
+	msg, err := domain.NewOutboxMessage(domain.EventBookCreated, &bookCopy)
+	if err != nil {
+		return err
+	}
+
+	book.ID = bookCopy.ID
 	r.books[book.ID] = &bookCopy
 	r.isbn[book.ISBN] = book.ID
+	r.outbox = append(r.outbox, msg)
+	r.appendChange(domain.ChangeCreate, &bookCopy)
 	return nil
 }
 
 func (r *InMemoryBookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	defer r.acquire(ctx, false)()
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	book, exists := r.books[id]
-	if !exists {
+	if !exists || book.IsDeleted() {
 		return nil, domain.ErrBookNotFound
 	}
-	return book, nil
+	bookCopy := *book
+	return &bookCopy, nil
 }
 
 func (r *InMemoryBookRepository) FindByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	defer r.acquire(ctx, false)()
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -57,26 +79,51 @@ func (r *InMemoryBookRepository) FindByISBN(ctx context.Context, isbn string) (*
 	if !exists {
 		return nil, domain.ErrBookNotFound
 	}
-	return r.books[id], nil
+	bookCopy := *r.books[id]
+	return &bookCopy, nil
 }
 
 func (r *InMemoryBookRepository) FindAll(ctx context.Context) ([]*domain.Book, error) {
+	defer r.acquire(ctx, false)()
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	books := make([]*domain.Book, 0, len(r.books))
 	for _, book := range r.books {
-		books = append(books, book)
+		if book.IsDeleted() {
+			continue
+		}
+		bookCopy := *book
+		books = append(books, &bookCopy)
+	}
+	return books, nil
+}
+
+// FindMatching returns the books that are not deleted and match filter,
+// copying only those.
+func (r *InMemoryBookRepository) FindMatching(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	books := make([]*domain.Book, 0)
+	for _, book := range r.books {
+		if book.IsDeleted() || !filter.Match(book) {
+			continue
+		}
+		bookCopy := *book
+		books = append(books, &bookCopy)
 	}
 	return books, nil
 }
 
 func (r *InMemoryBookRepository) Update(ctx context.Context, book *domain.Book) error {
+	defer r.acquire(ctx, true)()
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	existing, exists := r.books[book.ID]
-	if !exists {
+	if !exists || existing.IsDeleted() {
 		return domain.ErrBookNotFound
 	}
 
@@ -84,25 +131,169 @@ func (r *InMemoryBookRepository) Update(ctx context.Context, book *domain.Book)
 		if _, isbnExists := r.isbn[book.ISBN]; isbnExists {
 			return domain.ErrBookAlreadyExists
 		}
-		delete(r.isbn, existing.ISBN)
-		r.isbn[book.ISBN] = book.ID
 	}
 
 	bookCopy := *book
+	msg, err := domain.NewOutboxMessage(domain.EventBookUpdated, &bookCopy)
+	if err != nil {
+		return err
+	}
+
+	if existing.ISBN != book.ISBN {
+		delete(r.isbn, existing.ISBN)
+		r.isbn[book.ISBN] = book.ID
+	}
 	r.books[book.ID] = &bookCopy
+	r.outbox = append(r.outbox, msg)
+	r.appendChange(domain.ChangeUpdate, &bookCopy)
 	return nil
 }
 
 func (r *InMemoryBookRepository) Delete(ctx context.Context, id string) error {
+	defer r.acquire(ctx, true)()
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	book, exists := r.books[id]
-	if !exists {
+	if !exists || book.IsDeleted() {
 		return domain.ErrBookNotFound
 	}
 
+	now := time.Now()
+	bookCopy := *book
+	bookCopy.DeletedAt = &now
+	msg, err := domain.NewOutboxMessage(domain.EventBookDeleted, &bookCopy)
+	if err != nil {
+		return err
+	}
+
+	r.books[id] = &bookCopy
 	delete(r.isbn, book.ISBN)
-	delete(r.books, id)
+	r.outbox = append(r.outbox, msg)
+	r.appendChange(domain.ChangeDelete, &bookCopy)
+	return nil
+}
+
+func (r *InMemoryBookRepository) FindDeleted(ctx context.Context) ([]*domain.Book, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	books := make([]*domain.Book, 0)
+	for id, book := range r.books {
+		// Merged sources live on only as aliases of their target.
+		if _, merged := r.merges[id]; !book.IsDeleted() || merged {
+			continue
+		}
+		bookCopy := *book
+		books = append(books, &bookCopy)
+	}
+	sort.Slice(books, func(i, j int) bool {
+		return books[i].DeletedAt.After(*books[j].DeletedAt)
+	})
+	return books, nil
+}
+
+func (r *InMemoryBookRepository) Restore(ctx context.Context, id string) error {
+	defer r.acquire(ctx, true)()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	book, exists := r.books[id]
+	if !exists || !book.IsDeleted() {
+		return domain.ErrBookNotFound
+	}
+	if merge, merged := r.merges[id]; merged {
+		return domain.ErrBookMerged.WithMessage("book was merged into " + merge.TargetID)
+	}
+	if _, isbnExists := r.isbn[book.ISBN]; isbnExists {
+		return domain.ErrBookAlreadyExists
+	}
+
+	bookCopy := *book
+	bookCopy.DeletedAt = nil
+	msg, err := domain.NewOutboxMessage(domain.EventBookUpdated, &bookCopy)
+	if err != nil {
+		return err
+	}
+
+	r.books[id] = &bookCopy
+	r.isbn[book.ISBN] = id
+	r.outbox = append(r.outbox, msg)
+	r.appendChange(domain.ChangeRestore, &bookCopy)
 	return nil
 }
+
+func (r *InMemoryBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	defer r.acquire(ctx, true)()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for id, book := range r.books {
+		if _, merged := r.merges[id]; merged {
+			continue
+		}
+		if book.IsDeleted() && book.DeletedAt.Before(deletedBefore) {
+			delete(r.books, id)
+			r.appendChange(domain.ChangePurge, &domain.Book{ID: id})
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *InMemoryBookRepository) SaveMerge(ctx context.Context, merge *domain.Merge) error {
+	defer r.acquire(ctx, true)()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if target, exists := r.books[merge.TargetID]; !exists || target.IsDeleted() {
+		return domain.ErrBookNotFound
+	}
+
+	r.putMerge(merge)
+	return nil
+}
+
+func (r *InMemoryBookRepository) putMerge(merge *domain.Merge) {
+	for _, existing := range r.merges {
+		if existing.TargetID == merge.SourceID {
+			existing.TargetID = merge.TargetID
+		}
+	}
+
+	mergeCopy := *merge
+	r.merges[merge.SourceID] = &mergeCopy
+}
+
+func (r *InMemoryBookRepository) FindMerge(ctx context.Context, sourceID string) (*domain.Merge, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	merge, exists := r.merges[sourceID]
+	if !exists {
+		return nil, domain.ErrBookNotFound
+	}
+	mergeCopy := *merge
+	return &mergeCopy, nil
+}
+
+func (r *InMemoryBookRepository) FindMergesByTarget(ctx context.Context, targetID string) ([]*domain.Merge, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	merges := make([]*domain.Merge, 0)
+	for _, merge := range r.merges {
+		if merge.TargetID == targetID {
+			mergeCopy := *merge
+			merges = append(merges, &mergeCopy)
+		}
+	}
+	sort.Slice(merges, func(i, j int) bool {
+		return merges[i].MergedAt.Before(merges[j].MergedAt)
+	})
+	return merges, nil
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"solid/internal/domain"
+	"solid/internal/filter"
 	"testing"
+	"time"
 )
 
 func TestInMemoryBookRepository_Create(t *testing.T) {
@@ -149,6 +152,29 @@ func TestInMemoryBookRepository_FindAll(t *testing.T) {
 	})
 }
 
+func TestInMemoryBookRepository_FindMatching(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryBookRepository()
+	repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "1111111111"})
+	repo.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "2222222222"})
+	deleted := &domain.Book{Title: "Clean Architecture", Author: "Robert Martin", ISBN: "3333333333"}
+	repo.Create(ctx, deleted)
+	repo.Delete(ctx, deleted.ID)
+	filter, err := filter.Parse("author eq 'Robert Martin'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	books, err := repo.FindMatching(ctx, filter)
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(books) != 1 || books[0].Title != "Clean Code" {
+		t.Errorf("expected only Clean Code, got %v", books)
+	}
+}
+
 func TestInMemoryBookRepository_Update(t *testing.T) {
 	ctx := context.Background()
 
@@ -240,3 +266,155 @@ func TestInMemoryBookRepository_Delete(t *testing.T) {
 		}
 	})
 }
+
+func TestInMemoryBookRepository_SaveMerge(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("collapses alias chains", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		a := &domain.Book{Title: "Book A", Author: "Author", ISBN: "1111111111"}
+		b := &domain.Book{Title: "Book B", Author: "Author", ISBN: "2222222222"}
+		c := &domain.Book{Title: "Book C", Author: "Author", ISBN: "3333333333"}
+		repo.Create(ctx, a)
+		repo.Create(ctx, b)
+		repo.Create(ctx, c)
+
+		repo.Delete(ctx, a.ID)
+		repo.SaveMerge(ctx, &domain.Merge{SourceID: a.ID, TargetID: b.ID})
+		repo.Delete(ctx, b.ID)
+		err := repo.SaveMerge(ctx, &domain.Merge{SourceID: b.ID, TargetID: c.ID})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		merge, _ := repo.FindMerge(ctx, a.ID)
+		if merge.TargetID != c.ID {
+			t.Errorf("expected alias to point at %s, got %s", c.ID, merge.TargetID)
+		}
+		merges, _ := repo.FindMergesByTarget(ctx, c.ID)
+		if len(merges) != 2 {
+			t.Errorf("expected 2 merges into target, got %d", len(merges))
+		}
+	})
+
+	t.Run("merged sources stay out of the trash", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		source := &domain.Book{Title: "Book A", Author: "Author", ISBN: "1111111111"}
+		target := &domain.Book{Title: "Book B", Author: "Author", ISBN: "2222222222"}
+		repo.Create(ctx, source)
+		repo.Create(ctx, target)
+		repo.Delete(ctx, source.ID)
+		repo.SaveMerge(ctx, &domain.Merge{SourceID: source.ID, TargetID: target.ID})
+
+		if trash, _ := repo.FindDeleted(ctx); len(trash) != 0 {
+			t.Errorf("expected merged source to be left out of the trash, got %+v", trash)
+		}
+		var domainErr *domain.DomainError
+		if err := repo.Restore(ctx, source.ID); !errors.As(err, &domainErr) || domainErr.Code != "BOOK_MERGED" {
+			t.Errorf("expected BOOK_MERGED, got %v", err)
+		}
+		if purged, _ := repo.Purge(ctx, time.Now().Add(time.Minute)); purged != 0 {
+			t.Errorf("expected merged source to survive purge, purged %d", purged)
+		}
+		if merge, err := repo.FindMerge(ctx, source.ID); err != nil || merge.TargetID != target.ID {
+			t.Errorf("expected alias to keep redirecting, got %+v (%v)", merge, err)
+		}
+	})
+
+	t.Run("target not found", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+
+		err := repo.SaveMerge(ctx, &domain.Merge{SourceID: "a", TargetID: "missing"})
+
+		if err != domain.ErrBookNotFound {
+			t.Errorf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+}
+
+func TestInMemoryBookRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deleted books are hidden and listed in trash", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo.Create(ctx, book)
+
+		repo.Delete(ctx, book.ID)
+
+		books, _ := repo.FindAll(ctx)
+		if len(books) != 0 {
+			t.Errorf("expected deleted book to be excluded, got %d books", len(books))
+		}
+		trash, _ := repo.FindDeleted(ctx)
+		if len(trash) != 1 || trash[0].DeletedAt == nil {
+			t.Errorf("expected 1 tombstoned book in trash, got %+v", trash)
+		}
+		if err := repo.Delete(ctx, book.ID); err != domain.ErrBookNotFound {
+			t.Errorf("expected ErrBookNotFound on second delete, got %v", err)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo.Create(ctx, book)
+		repo.Delete(ctx, book.ID)
+
+		err := repo.Restore(ctx, book.ID)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		found, err := repo.FindByISBN(ctx, "0132350882")
+		if err != nil || found.ID != book.ID || found.DeletedAt != nil {
+			t.Errorf("expected restored book, got %+v (%v)", found, err)
+		}
+	})
+
+	t.Run("restore with taken ISBN", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo.Create(ctx, book)
+		repo.Delete(ctx, book.ID)
+		repo.Create(ctx, &domain.Book{Title: "Clean Code 2nd", Author: "Robert Martin", ISBN: "0132350882"})
+
+		err := repo.Restore(ctx, book.ID)
+
+		if err != domain.ErrBookAlreadyExists {
+			t.Errorf("expected ErrBookAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("restore active book", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo.Create(ctx, book)
+
+		if err := repo.Restore(ctx, book.ID); err != domain.ErrBookNotFound {
+			t.Errorf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		kept := &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0134757599"}
+		repo.Create(ctx, book)
+		repo.Create(ctx, kept)
+		repo.Delete(ctx, book.ID)
+
+		purged, err := repo.Purge(ctx, time.Now().Add(time.Minute))
+
+		if err != nil || purged != 1 {
+			t.Errorf("expected 1 purged book, got %d (%v)", purged, err)
+		}
+		trash, _ := repo.FindDeleted(ctx)
+		if len(trash) != 0 {
+			t.Errorf("expected empty trash, got %d", len(trash))
+		}
+		if _, err := repo.FindByID(ctx, kept.ID); err != nil {
+			t.Errorf("expected active book to survive purge, got %v", err)
+		}
+	})
+}
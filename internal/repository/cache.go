@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"solid/internal/domain"
+)
+
+type CacheConfig struct {
+	Size        int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	// LoadTimeout bounds a storage read shared by concurrent misses. The read
+	// is detached from the caller that started it, so it is not cut short
+	// when that caller gives up while others are still waiting.
+	LoadTimeout time.Duration
+}
+
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		Size:        10000,
+		TTL:         5 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+		LoadTimeout: 5 * time.Second,
+	}
+}
+
+type CacheStats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Entries      int    `json:"entries"`
+}
+
+type cacheEntry struct {
+	key       string
+	book      *domain.Book
+	expiresAt time.Time
+}
+
+type CachedBookRepository struct {
+	inner   domain.BookRepository
+	config  CacheConfig
+	now     func() time.Time
+	flights flightGroup
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	keysByBook map[string]map[string]struct{}
+	generation uint64
+	stats      CacheStats
+}
+
+func NewCachedBookRepository(inner domain.BookRepository, config CacheConfig) *CachedBookRepository {
+	if config.Size <= 0 {
+		config.Size = DefaultCacheConfig().Size
+	}
+	if config.LoadTimeout <= 0 {
+		config.LoadTimeout = DefaultCacheConfig().LoadTimeout
+	}
+	return &CachedBookRepository{
+		inner:      inner,
+		config:     config,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		keysByBook: make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *CachedBookRepository) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+func (c *CachedBookRepository) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.keysByBook = make(map[string]map[string]struct{})
+	c.generation++
+}
+
+func (c *CachedBookRepository) Create(ctx context.Context, book *domain.Book) error {
+	err := c.inner.Create(ctx, book)
+	c.invalidate(ctx, "", book.ISBN)
+	return err
+}
+
+func (c *CachedBookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	return c.lookup(ctx, idKey(id), func(ctx context.Context) (*domain.Book, error) {
+		return c.inner.FindByID(ctx, id)
+	})
+}
+
+func (c *CachedBookRepository) FindByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	return c.lookup(ctx, isbnKey(isbn), func(ctx context.Context) (*domain.Book, error) {
+		return c.inner.FindByISBN(ctx, isbn)
+	})
+}
+
+func (c *CachedBookRepository) FindAll(ctx context.Context) ([]*domain.Book, error) {
+	return c.inner.FindAll(ctx)
+}
+
+// FindMatching is not cached; it is forwarded to the inner repository, or
+// answered by filtering FindAll when the inner repository cannot filter.
+func (c *CachedBookRepository) FindMatching(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error) {
+	if inner, ok := c.inner.(domain.FilterableRepository); ok {
+		return inner.FindMatching(ctx, filter)
+	}
+	books, err := c.inner.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matching := make([]*domain.Book, 0, len(books))
+	for _, book := range books {
+		if filter.Match(book) {
+			matching = append(matching, book)
+		}
+	}
+	return matching, nil
+}
+
+func (c *CachedBookRepository) Update(ctx context.Context, book *domain.Book) error {
+	err := c.inner.Update(ctx, book)
+	c.invalidate(ctx, book.ID, book.ISBN)
+	return err
+}
+
+func (c *CachedBookRepository) Delete(ctx context.Context, id string) error {
+	err := c.inner.Delete(ctx, id)
+	c.invalidate(ctx, id, "")
+	return err
+}
+
+func (c *CachedBookRepository) FindDeleted(ctx context.Context) ([]*domain.Book, error) {
+	return c.inner.FindDeleted(ctx)
+}
+
+func (c *CachedBookRepository) Restore(ctx context.Context, id string) error {
+	err := c.inner.Restore(ctx, id)
+	c.invalidate(ctx, id, "")
+	c.invalidateNegative(ctx)
+	return err
+}
+
+func (c *CachedBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return c.inner.Purge(ctx, deletedBefore)
+}
+
+func (c *CachedBookRepository) SaveMerge(ctx context.Context, merge *domain.Merge) error {
+	return c.inner.SaveMerge(ctx, merge)
+}
+
+func (c *CachedBookRepository) FindMerge(ctx context.Context, sourceID string) (*domain.Merge, error) {
+	return c.inner.FindMerge(ctx, sourceID)
+}
+
+func (c *CachedBookRepository) FindMergesByTarget(ctx context.Context, targetID string) ([]*domain.Merge, error) {
+	return c.inner.FindMergesByTarget(ctx, targetID)
+}
+
+func (c *CachedBookRepository) lookup(ctx context.Context, key string, load func(ctx context.Context) (*domain.Book, error)) (*domain.Book, error) {
+	if _, inTx := ctx.Value(cacheTxKey{}).(*cacheTx); inTx {
+		return load(ctx)
+	}
+
+	if book, err, ok := c.get(key); ok {
+		return book, err
+	}
+
+	book, err := c.flights.do(ctx, key, c.config.LoadTimeout, func(ctx context.Context) (*domain.Book, error) {
+		generation := c.currentGeneration()
+		book, err := load(ctx)
+		if err == nil || errors.Is(err, domain.ErrBookNotFound) {
+			c.put(key, book, generation)
+		}
+		return book, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	bookCopy := *book
+	return &bookCopy, nil
+}
+
+func (c *CachedBookRepository) get(key string) (*domain.Book, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.entries[key]
+	if !exists {
+		c.stats.Misses++
+		return nil, nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.removeElement(elem)
+		c.stats.Misses++
+		return nil, nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	if entry.book == nil {
+		c.stats.NegativeHits++
+		return nil, domain.ErrBookNotFound, true
+	}
+	c.stats.Hits++
+	bookCopy := *entry.book
+	return &bookCopy, nil, true
+}
+
+func (c *CachedBookRepository) put(key string, book *domain.Book, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	ttl := c.config.TTL
+	if book == nil {
+		ttl = c.config.NegativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	if elem, exists := c.entries[key]; exists {
+		c.removeElement(elem)
+	}
+
+	entry := &cacheEntry{key: key, expiresAt: c.now().Add(ttl)}
+	if book != nil {
+		bookCopy := *book
+		entry.book = &bookCopy
+		keys, exists := c.keysByBook[book.ID]
+		if !exists {
+			keys = make(map[string]struct{})
+			c.keysByBook[book.ID] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+
+	for c.lru.Len() > c.config.Size {
+		c.removeElement(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *CachedBookRepository) invalidate(ctx context.Context, id, isbn string) {
+	if tx, inTx := ctx.Value(cacheTxKey{}).(*cacheTx); inTx {
+		tx.touch(id, isbn)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, 2)
+	if id != "" {
+		keys = append(keys, idKey(id))
+		for key := range c.keysByBook[id] {
+			keys = append(keys, key)
+		}
+	}
+	if isbn != "" {
+		keys = append(keys, isbnKey(isbn))
+	}
+
+	for _, key := range keys {
+		if elem, exists := c.entries[key]; exists {
+			c.removeElement(elem)
+		}
+		c.flights.forget(key)
+	}
+	c.generation++
+}
+
+func (c *CachedBookRepository) invalidateNegative(ctx context.Context) {
+	if tx, inTx := ctx.Value(cacheTxKey{}).(*cacheTx); inTx {
+		tx.negative = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		if elem.Value.(*cacheEntry).book == nil {
+			c.removeElement(elem)
+			c.flights.forget(key)
+		}
+	}
+	c.generation++
+}
+
+func (c *CachedBookRepository) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	if entry.book == nil {
+		return
+	}
+	if keys, exists := c.keysByBook[entry.book.ID]; exists {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.keysByBook, entry.book.ID)
+		}
+	}
+}
+
+func (c *CachedBookRepository) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+func idKey(id string) string {
+	return "id:" + id
+}
+
+func isbnKey(isbn string) string {
+	return "isbn:" + isbn
+}
+
+type TransactionalBookRepository interface {
+	domain.BookRepository
+	domain.Transactor
+}
+
+type TransactionalCachedBookRepository struct {
+	*CachedBookRepository
+	tx domain.Transactor
+}
+
+func NewTransactionalCachedBookRepository(inner TransactionalBookRepository, config CacheConfig) *TransactionalCachedBookRepository {
+	return &TransactionalCachedBookRepository{
+		CachedBookRepository: NewCachedBookRepository(inner, config),
+		tx:                   inner,
+	}
+}
+
+type cacheTxKey struct{}
+
+type cacheTx struct {
+	ids      []string
+	isbns    []string
+	negative bool
+}
+
+func (t *cacheTx) touch(id, isbn string) {
+	if id != "" {
+		t.ids = append(t.ids, id)
+	}
+	if isbn != "" {
+		t.isbns = append(t.isbns, isbn)
+	}
+}
+
+func (c *TransactionalCachedBookRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, inTx := ctx.Value(cacheTxKey{}).(*cacheTx); inTx {
+		return c.tx.WithinTx(ctx, fn)
+	}
+
+	tx := &cacheTx{}
+	err := c.tx.WithinTx(context.WithValue(ctx, cacheTxKey{}, tx), fn)
+
+	for _, id := range tx.ids {
+		c.invalidate(ctx, id, "")
+	}
+	for _, isbn := range tx.isbns {
+		c.invalidate(ctx, "", isbn)
+	}
+	if tx.negative {
+		c.invalidateNegative(ctx)
+	}
+	return err
+}
+
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	book *domain.Book
+	err  error
+}
+
+// do returns the result of fn for key, sharing one call between concurrent
+// callers. fn runs on a context that keeps ctx's values but not its
+// cancellation, bounded by timeout, so every caller waits only as long as its
+// own ctx allows without cancelling the load for the others.
+func (g *flightGroup) do(ctx context.Context, key string, timeout time.Duration, fn func(ctx context.Context) (*domain.Book, error)) (*domain.Book, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, exists := g.calls[key]
+	if !exists {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(context.WithoutCancel(ctx), key, timeout, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.book, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, timeout time.Duration, call *flightCall, fn func(ctx context.Context) (*domain.Book, error)) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer func() {
+		cancel()
+		close(call.done)
+		g.mu.Lock()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+	}()
+
+	call.book, call.err = fn(ctx)
+}
+
+func (g *flightGroup) forget(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.calls, key)
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"solid/internal/domain"
+	"solid/internal/filter"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingBookRepository struct {
+	*InMemoryBookRepository
+	finds   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingBookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	r.finds.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.InMemoryBookRepository.FindByID(ctx, id)
+}
+
+func (r *countingBookRepository) FindByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	r.finds.Add(1)
+	return r.InMemoryBookRepository.FindByISBN(ctx, isbn)
+}
+
+func newCountingRepository() *countingBookRepository {
+	return &countingBookRepository{InMemoryBookRepository: NewInMemoryBookRepository()}
+}
+
+func TestCachedBookRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("serves repeated lookups from cache", func(t *testing.T) {
+		inner := newCountingRepository()
+		cache := NewCachedBookRepository(inner, DefaultCacheConfig())
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		cache.Create(ctx, book)
+
+		for i := 0; i < 3; i++ {
+			if _, err := cache.FindByID(ctx, book.ID); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		if finds := inner.finds.Load(); finds != 1 {
+			t.Errorf("expected 1 storage lookup, got %d", finds)
+		}
+		stats := cache.Stats()
+		if stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		cache := NewCachedBookRepository(NewInMemoryBookRepository(), DefaultCacheConfig())
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		cache.Create(ctx, book)
+
+		found, _ := cache.FindByID(ctx, book.ID)
+		found.Title = "Changed"
+		again, _ := cache.FindByID(ctx, book.ID)
+
+		if again.Title != "Clean Code" {
+			t.Errorf("expected cached book to be unaffected, got %q", again.Title)
+		}
+	})
+
+	t.Run("caches not found until the isbn is created", func(t *testing.T) {
+		inner := newCountingRepository()
+		cache := NewCachedBookRepository(inner, DefaultCacheConfig())
+
+		for i := 0; i < 2; i++ {
+			if _, err := cache.FindByISBN(ctx, "0132350882"); err != domain.ErrBookNotFound {
+				t.Fatalf("expected ErrBookNotFound, got %v", err)
+			}
+		}
+		if finds := inner.finds.Load(); finds != 1 {
+			t.Errorf("expected negative result to be cached, got %d lookups", finds)
+		}
+
+		cache.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+
+		if _, err := cache.FindByISBN(ctx, "0132350882"); err != nil {
+			t.Errorf("expected created book to be found, got %v", err)
+		}
+		if stats := cache.Stats(); stats.NegativeHits != 1 {
+			t.Errorf("expected 1 negative hit, got %+v", stats)
+		}
+	})
+
+	t.Run("invalidates on update and delete", func(t *testing.T) {
+		cache := NewCachedBookRepository(NewInMemoryBookRepository(), DefaultCacheConfig())
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		cache.Create(ctx, book)
+		cache.FindByID(ctx, book.ID)
+		cache.FindByISBN(ctx, "0132350882")
+
+		updated := *book
+		updated.Title = "Clean Coder"
+		updated.ISBN = "0137081073"
+		cache.Update(ctx, &updated)
+
+		found, _ := cache.FindByID(ctx, book.ID)
+		if found.Title != "Clean Coder" {
+			t.Errorf("expected updated title, got %q", found.Title)
+		}
+		if _, err := cache.FindByISBN(ctx, "0132350882"); err != domain.ErrBookNotFound {
+			t.Errorf("expected old isbn to be invalidated, got %v", err)
+		}
+
+		cache.Delete(ctx, book.ID)
+
+		if _, err := cache.FindByID(ctx, book.ID); err != domain.ErrBookNotFound {
+			t.Errorf("expected deleted book to be gone, got %v", err)
+		}
+		if _, err := cache.FindByISBN(ctx, "0137081073"); err != domain.ErrBookNotFound {
+			t.Errorf("expected deleted isbn to be gone, got %v", err)
+		}
+	})
+
+	t.Run("expires entries after ttl", func(t *testing.T) {
+		inner := newCountingRepository()
+		cache := NewCachedBookRepository(inner, CacheConfig{Size: 10, TTL: time.Minute, NegativeTTL: time.Second})
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		cache.Create(ctx, book)
+
+		cache.FindByID(ctx, book.ID)
+		now = now.Add(2 * time.Minute)
+		cache.FindByID(ctx, book.ID)
+
+		if finds := inner.finds.Load(); finds != 2 {
+			t.Errorf("expected expired entry to be reloaded, got %d lookups", finds)
+		}
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		inner := newCountingRepository()
+		cache := NewCachedBookRepository(inner, CacheConfig{Size: 2, TTL: time.Minute})
+		ids := make([]string, 3)
+		for i, isbn := range []string{"0132350882", "0201485672", "0137081073"} {
+			book := &domain.Book{Title: "Book", Author: "Author", ISBN: isbn}
+			cache.Create(ctx, book)
+			ids[i] = book.ID
+		}
+
+		for _, i := range []int{0, 1, 0, 2} {
+			cache.FindByID(ctx, ids[i])
+		}
+		inner.finds.Store(0)
+		cache.FindByID(ctx, ids[0])
+		cache.FindByID(ctx, ids[1])
+
+		if finds := inner.finds.Load(); finds != 1 {
+			t.Errorf("expected only the evicted book to be reloaded, got %d lookups", finds)
+		}
+		if stats := cache.Stats(); stats.Evictions != 2 || stats.Entries != 2 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("collapses concurrent misses", func(t *testing.T) {
+		inner := newCountingRepository()
+		cache := NewCachedBookRepository(inner, DefaultCacheConfig())
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		cache.Create(ctx, book)
+		inner.release = make(chan struct{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := cache.FindByID(ctx, book.ID); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		for inner.finds.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(inner.release)
+		wg.Wait()
+
+		if finds := inner.finds.Load(); finds != 1 {
+			t.Errorf("expected a single storage lookup, got %d", finds)
+		}
+	})
+
+	t.Run("keeps a shared load running when its starter gives up", func(t *testing.T) {
+		inner := newCountingRepository()
+		cache := NewCachedBookRepository(inner, DefaultCacheConfig())
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		cache.Create(ctx, book)
+		inner.release = make(chan struct{})
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+		leaderErr := make(chan error)
+		go func() {
+			_, err := cache.FindByID(leaderCtx, book.ID)
+			leaderErr <- err
+		}()
+		for inner.finds.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		waiterErr := make(chan error)
+		go func() {
+			_, err := cache.FindByID(ctx, book.ID)
+			waiterErr <- err
+		}()
+
+		cancel()
+		if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the leader to stop waiting, got %v", err)
+		}
+		close(inner.release)
+		if err := <-waiterErr; err != nil {
+			t.Errorf("expected the waiter to get the book, got %v", err)
+		}
+		if finds := inner.finds.Load(); finds != 1 {
+			t.Errorf("expected a single storage lookup, got %d", finds)
+		}
+	})
+
+	t.Run("forwards filtered lookups", func(t *testing.T) {
+		cache := NewTransactionalCachedBookRepository(NewInMemoryBookRepository(), DefaultCacheConfig())
+		cache.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		cache.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"})
+
+		var repo domain.BookRepository = cache
+		filterable, ok := repo.(domain.FilterableRepository)
+		if !ok {
+			t.Fatal("expected the cache to implement FilterableRepository")
+		}
+		filter, err := filter.Parse("author eq 'Martin Fowler'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		books, err := filterable.FindMatching(ctx, filter)
+		if err != nil || len(books) != 1 || books[0].Title != "Refactoring" {
+			t.Errorf("unexpected result: %v %v", books, err)
+		}
+	})
+
+	t.Run("drops entries written in a rolled back transaction", func(t *testing.T) {
+		cache := NewTransactionalCachedBookRepository(NewInMemoryBookRepository(), DefaultCacheConfig())
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		cache.Create(ctx, book)
+		cache.FindByID(ctx, book.ID)
+		failure := errors.New("boom")
+
+		err := cache.WithinTx(ctx, func(ctx context.Context) error {
+			updated := *book
+			updated.Title = "Clean Coder"
+			cache.Update(ctx, &updated)
+			if found, _ := cache.FindByID(ctx, book.ID); found.Title != "Clean Coder" {
+				t.Errorf("expected transaction to read its own write, got %q", found.Title)
+			}
+			return failure
+		})
+
+		if err != failure {
+			t.Fatalf("expected tx error, got %v", err)
+		}
+		found, _ := cache.FindByID(ctx, book.ID)
+		if found.Title != "Clean Code" {
+			t.Errorf("expected rolled back title, got %q", found.Title)
+		}
+	})
+}
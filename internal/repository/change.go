@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"solid/internal/domain"
+)
+
+func (r *InMemoryBookRepository) Changes(ctx context.Context, since uint64, limit int) ([]*domain.Change, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.log) > 0 && since+1 < r.log[0].Sequence {
+		oldest := r.log[0].Sequence - 1
+		return nil, domain.ErrChangesExpired.
+			WithMessage(fmt.Sprintf("changes after %d are no longer retained; list the books again and continue from %d", since, r.seq)).
+			WithDetails(map[string]uint64{"oldest_since": oldest, "latest": r.seq})
+	}
+
+	start := sort.Search(len(r.log), func(i int) bool {
+		return r.log[i].Sequence > since
+	})
+
+	changes := make([]*domain.Change, 0)
+	for _, change := range r.log[start:] {
+		if limit > 0 && len(changes) >= limit {
+			break
+		}
+		changeCopy := *change
+		if change.Book != nil {
+			bookCopy := *change.Book
+			changeCopy.Book = &bookCopy
+		}
+		changes = append(changes, &changeCopy)
+	}
+	return changes, nil
+}
+
+func (r *InMemoryBookRepository) LatestSequence(ctx context.Context) (uint64, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.seq, nil
+}
+
+func (r *InMemoryBookRepository) appendChange(operation string, book *domain.Book) {
+	r.seq++
+
+	change := &domain.Change{
+		Sequence:  r.seq,
+		Operation: operation,
+		BookID:    book.ID,
+		Timestamp: time.Now(),
+	}
+	if operation != domain.ChangePurge {
+		bookCopy := *book
+		change.Book = &bookCopy
+	}
+	r.log = append(r.log, change)
+}
+
+// CompactChanges drops all but the newest keep changes from the log and
+// returns how many it dropped; keep <= 0 keeps everything. Readers whose
+// cursor falls before the oldest kept change get ErrChangesExpired.
+func (r *InMemoryBookRepository) CompactChanges(ctx context.Context, keep int) (int, error) {
+	defer r.acquire(ctx, true)()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	drop := len(r.log) - keep
+	if keep <= 0 || drop <= 0 {
+		return 0, nil
+	}
+	// Copy so the dropped changes can be collected.
+	r.log = append([]*domain.Change(nil), r.log[drop:]...)
+	return drop, nil
+}
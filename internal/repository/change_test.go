@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"solid/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestInMemoryBookRepository_Changes(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryBookRepository()
+	book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+	repo.Create(ctx, book)
+	book.Title = "Clean Coder"
+	repo.Update(ctx, book)
+	repo.Delete(ctx, book.ID)
+	repo.Restore(ctx, book.ID)
+	repo.Delete(ctx, book.ID)
+	repo.Purge(ctx, time.Now().Add(time.Minute))
+
+	t.Run("ordered with monotonic sequences", func(t *testing.T) {
+		changes, err := repo.Changes(ctx, 0, 0)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{
+			domain.ChangeCreate, domain.ChangeUpdate, domain.ChangeDelete,
+			domain.ChangeRestore, domain.ChangeDelete, domain.ChangePurge,
+		}
+		if len(changes) != len(want) {
+			t.Fatalf("expected %d changes, got %d", len(want), len(changes))
+		}
+		for i, change := range changes {
+			if change.Sequence != uint64(i+1) || change.Operation != want[i] || change.BookID != book.ID {
+				t.Errorf("unexpected change %d: %+v", i, change)
+			}
+		}
+		if changes[2].Book == nil || changes[2].Book.DeletedAt == nil {
+			t.Error("expected delete to carry a tombstone")
+		}
+		if changes[5].Book != nil {
+			t.Error("expected purge to carry no book")
+		}
+	})
+
+	t.Run("since and limit", func(t *testing.T) {
+		changes, _ := repo.Changes(ctx, 2, 2)
+
+		if len(changes) != 2 || changes[0].Sequence != 3 || changes[1].Sequence != 4 {
+			t.Errorf("unexpected changes %+v", changes)
+		}
+	})
+
+	t.Run("failed writes get no sequence", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		repo.Update(ctx, &domain.Book{ID: "missing"})
+
+		changes, _ := repo.Changes(ctx, 0, 0)
+
+		if len(changes) != 0 {
+			t.Errorf("expected no changes, got %d", len(changes))
+		}
+	})
+}
+
+func TestInMemoryBookRepository_CompactChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryBookRepository()
+	for _, isbn := range []string{"0132350882", "0201485672", "0134757599"} {
+		repo.Create(ctx, &domain.Book{Title: "Book " + isbn, Author: "Author", ISBN: isbn})
+	}
+
+	dropped, err := repo.CompactChanges(ctx, 2)
+
+	if err != nil || dropped != 1 {
+		t.Fatalf("expected 1 dropped change, got %d (%v)", dropped, err)
+	}
+	if changes, err := repo.Changes(ctx, 1, 0); err != nil || len(changes) != 2 || changes[0].Sequence != 2 {
+		t.Errorf("expected changes 2 and 3 after the oldest kept cursor, got %+v (%v)", changes, err)
+	}
+	var domainErr *domain.DomainError
+	if _, err := repo.Changes(ctx, 0, 0); !errors.As(err, &domainErr) || domainErr.Code != "CHANGES_EXPIRED" {
+		t.Errorf("expected CHANGES_EXPIRED for a dropped cursor, got %v", err)
+	}
+	if dropped, _ := repo.CompactChanges(ctx, 0); dropped != 0 {
+		t.Errorf("expected keep 0 to disable compaction, dropped %d", dropped)
+	}
+}
@@ -0,0 +1,569 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"solid/internal/domain"
+)
+
+type SyncPolicy int
+
+const (
+	SyncAlways SyncPolicy = iota
+	SyncInterval
+	SyncNever
+)
+
+func ParseSyncPolicy(value string) (SyncPolicy, bool) {
+	switch value {
+	case "always":
+		return SyncAlways, true
+	case "interval":
+		return SyncInterval, true
+	case "never":
+		return SyncNever, true
+	default:
+		return SyncAlways, false
+	}
+}
+
+const (
+	walFileName      = "books.wal"
+	snapshotFileName = "books.snapshot"
+)
+
+type FileConfig struct {
+	Dir           string
+	Sync          SyncPolicy
+	SyncInterval  time.Duration
+	SnapshotEvery int
+}
+
+func DefaultFileConfig(dir string) FileConfig {
+	return FileConfig{
+		Dir:           dir,
+		Sync:          SyncAlways,
+		SyncInterval:  time.Second,
+		SnapshotEvery: 1000,
+	}
+}
+
+type fileSnapshot struct {
+	LSN    uint64                  `json:"lsn"`
+	Seq    uint64                  `json:"seq"`
+	Books  []*domain.Book          `json:"books"`
+	Merges []*domain.Merge         `json:"merges"`
+	Outbox []*domain.OutboxMessage `json:"outbox"`
+	Log    []*domain.Change        `json:"log"`
+
+	History []domain.AuditEntry `json:"history,omitempty"`
+}
+
+type FileBookRepository struct {
+	mem    *InMemoryBookRepository
+	config FileConfig
+
+	wal     *os.File
+	walSize int64
+	lsn     uint64
+	records int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewFileBookRepository(config FileConfig) (*FileBookRepository, error) {
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &FileBookRepository{
+		mem:    NewInMemoryBookRepository(),
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := r.loadSnapshot(); err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+
+	wal, err := os.OpenFile(filepath.Join(config.Dir, walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	size, records, err := replayWAL(wal, r.replay)
+	if err != nil {
+		wal.Close()
+		return nil, fmt.Errorf("replay wal: %w", err)
+	}
+	r.wal = wal
+	r.walSize = size
+	r.records = records
+
+	if config.Sync == SyncInterval && config.SyncInterval > 0 {
+		go r.syncLoop()
+	} else {
+		close(r.done)
+	}
+	return r, nil
+}
+
+func (r *FileBookRepository) Create(ctx context.Context, book *domain.Book) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		return nil, r.mem.Create(ctx, book)
+	})
+}
+
+func (r *FileBookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	return r.mem.FindByID(ctx, id)
+}
+
+func (r *FileBookRepository) FindByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	return r.mem.FindByISBN(ctx, isbn)
+}
+
+func (r *FileBookRepository) FindAll(ctx context.Context) ([]*domain.Book, error) {
+	return r.mem.FindAll(ctx)
+}
+
+func (r *FileBookRepository) FindMatching(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error) {
+	return r.mem.FindMatching(ctx, filter)
+}
+
+func (r *FileBookRepository) Update(ctx context.Context, book *domain.Book) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		return nil, r.mem.Update(ctx, book)
+	})
+}
+
+func (r *FileBookRepository) Delete(ctx context.Context, id string) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		return nil, r.mem.Delete(ctx, id)
+	})
+}
+
+func (r *FileBookRepository) FindDeleted(ctx context.Context) ([]*domain.Book, error) {
+	return r.mem.FindDeleted(ctx)
+}
+
+func (r *FileBookRepository) Restore(ctx context.Context, id string) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		return nil, r.mem.Restore(ctx, id)
+	})
+}
+
+func (r *FileBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged := 0
+	err := r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		var err error
+		purged, err = r.mem.Purge(ctx, deletedBefore)
+		return nil, err
+	})
+	return purged, err
+}
+
+func (r *FileBookRepository) SaveMerge(ctx context.Context, merge *domain.Merge) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		if err := r.mem.SaveMerge(ctx, merge); err != nil {
+			return nil, err
+		}
+		mergeCopy := *merge
+		return []walEntry{{Op: walMerge, Merge: &mergeCopy}}, nil
+	})
+}
+
+func (r *FileBookRepository) FindMerge(ctx context.Context, sourceID string) (*domain.Merge, error) {
+	return r.mem.FindMerge(ctx, sourceID)
+}
+
+func (r *FileBookRepository) FindMergesByTarget(ctx context.Context, targetID string) ([]*domain.Merge, error) {
+	return r.mem.FindMergesByTarget(ctx, targetID)
+}
+
+func (r *FileBookRepository) Changes(ctx context.Context, since uint64, limit int) ([]*domain.Change, error) {
+	return r.mem.Changes(ctx, since, limit)
+}
+
+func (r *FileBookRepository) LatestSequence(ctx context.Context) (uint64, error) {
+	return r.mem.LatestSequence(ctx)
+}
+
+// CompactChanges trims the change log kept in memory and in the next
+// snapshot; the WAL is left alone because it is truncated by that snapshot.
+func (r *FileBookRepository) CompactChanges(ctx context.Context, keep int) (int, error) {
+	return r.mem.CompactChanges(ctx, keep)
+}
+
+func (r *FileBookRepository) PendingOutbox(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	return r.mem.PendingOutbox(ctx, now, limit)
+}
+
+func (r *FileBookRepository) MarkDelivered(ctx context.Context, id string) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		if err := r.mem.MarkDelivered(ctx, id); err != nil {
+			return nil, err
+		}
+		return []walEntry{{Op: walOutboxDelivered, MessageID: id}}, nil
+	})
+}
+
+func (r *FileBookRepository) MarkFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		if err := r.mem.MarkFailed(ctx, id, reason, nextAttemptAt); err != nil {
+			return nil, err
+		}
+		return []walEntry{{Op: walOutboxPut, Message: r.mem.outboxMessage(id)}}, nil
+	})
+}
+
+func (r *FileBookRepository) MarkDead(ctx context.Context, id string, reason string) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		if err := r.mem.MarkDead(ctx, id, reason); err != nil {
+			return nil, err
+		}
+		return []walEntry{{Op: walOutboxPut, Message: r.mem.outboxMessage(id)}}, nil
+	})
+}
+
+func (r *FileBookRepository) DeadOutbox(ctx context.Context) ([]*domain.OutboxMessage, error) {
+	return r.mem.DeadOutbox(ctx)
+}
+
+type walBatchKey struct{}
+
+type walBatch struct {
+	owner   *FileBookRepository
+	entries []walEntry
+}
+
+func (r *FileBookRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if batch, ok := ctx.Value(walBatchKey{}).(*walBatch); ok && batch.owner == r {
+		return fn(ctx)
+	}
+
+	return r.mem.WithinTx(ctx, func(ctx context.Context) error {
+		batch := &walBatch{owner: r}
+		if err := fn(context.WithValue(ctx, walBatchKey{}, batch)); err != nil {
+			return err
+		}
+		return r.commit(batch.entries)
+	})
+}
+
+func (r *FileBookRepository) Snapshot(ctx context.Context) error {
+	return r.mem.WithinTx(ctx, func(ctx context.Context) error {
+		return r.writeSnapshot()
+	})
+}
+
+func (r *FileBookRepository) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+		if err = r.Snapshot(context.Background()); err != nil {
+			r.wal.Close()
+			return
+		}
+		err = r.wal.Close()
+	})
+	return err
+}
+
+func (r *FileBookRepository) mutate(ctx context.Context, fn func(ctx context.Context) ([]walEntry, error)) error {
+	return r.WithinTx(ctx, func(ctx context.Context) error {
+		batch := ctx.Value(walBatchKey{}).(*walBatch)
+		seq, outboxLen := r.mem.position()
+
+		entries, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		batch.entries = append(batch.entries, r.mem.entriesSince(seq, outboxLen)...)
+		batch.entries = append(batch.entries, entries...)
+		return nil
+	})
+}
+
+func (r *FileBookRepository) commit(entries []walEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	record := walRecord{LSN: r.lsn + 1, Entries: entries}
+	data, err := encodeWALRecord(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.wal.Write(data); err != nil {
+		r.wal.Truncate(r.walSize)
+		r.wal.Seek(r.walSize, 0)
+		return fmt.Errorf("append wal: %w", err)
+	}
+	if r.config.Sync == SyncAlways {
+		if err := r.wal.Sync(); err != nil {
+			r.wal.Truncate(r.walSize)
+			r.wal.Seek(r.walSize, 0)
+			return fmt.Errorf("sync wal: %w", err)
+		}
+	}
+
+	r.lsn = record.LSN
+	r.walSize += int64(len(data))
+	r.records++
+
+	// The record is durable in the WAL, so a failed snapshot does not fail
+	// the write; it is retried after the next commit.
+	if r.config.SnapshotEvery > 0 && r.records >= r.config.SnapshotEvery {
+		if err := r.writeSnapshot(); err != nil {
+			log.Printf("snapshot after %d wal records failed: %v", r.records, err)
+		}
+	}
+	return nil
+}
+
+func (r *FileBookRepository) replay(record walRecord) {
+	if record.LSN <= r.lsn {
+		return
+	}
+	r.mem.apply(record.Entries)
+	r.lsn = record.LSN
+}
+
+func (r *FileBookRepository) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(r.config.Dir, snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snapshot fileSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	r.mem.restoreSnapshot(&snapshot)
+	r.lsn = snapshot.LSN
+	return nil
+}
+
+func (r *FileBookRepository) writeSnapshot() error {
+	snapshot := r.mem.fileSnapshot()
+	snapshot.LSN = r.lsn
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.config.Dir, snapshotFileName)
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if dir, err := os.Open(r.config.Dir); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	if err := r.wal.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := r.wal.Seek(0, 0); err != nil {
+		return err
+	}
+	r.walSize = 0
+	r.records = 0
+	return nil
+}
+
+func (r *FileBookRepository) syncLoop() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.wal.Sync()
+		}
+	}
+}
+
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (r *InMemoryBookRepository) position() (uint64, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.seq, len(r.outbox)
+}
+
+func (r *InMemoryBookRepository) entriesSince(seq uint64, outboxLen int) []walEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	start := sort.Search(len(r.log), func(i int) bool {
+		return r.log[i].Sequence > seq
+	})
+
+	entries := make([]walEntry, 0)
+	for _, change := range r.log[start:] {
+		changeCopy := *change
+		entries = append(entries, walEntry{Op: walChange, Change: &changeCopy})
+	}
+	if len(r.outbox) > outboxLen {
+		for _, msg := range r.outbox[outboxLen:] {
+			msgCopy := *msg
+			entries = append(entries, walEntry{Op: walOutboxPut, Message: &msgCopy})
+		}
+	}
+	return entries
+}
+
+func (r *InMemoryBookRepository) outboxMessage(id string) *domain.OutboxMessage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, msg := range r.outbox {
+		if msg.ID == id {
+			msgCopy := *msg
+			return &msgCopy
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryBookRepository) apply(entries []walEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		switch entry.Op {
+		case walChange:
+			r.applyChange(entry.Change)
+		case walMerge:
+			r.putMerge(entry.Merge)
+		case walOutboxPut:
+			r.putOutboxMessage(entry.Message)
+		case walAudit:
+			if entry.Audit != nil {
+				r.history.put(*entry.Audit)
+			}
+		case walOutboxDelivered:
+			for i, msg := range r.outbox {
+				if msg.ID == entry.MessageID {
+					r.outbox = append(r.outbox[:i], r.outbox[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+}
+
+func (r *InMemoryBookRepository) applyChange(change *domain.Change) {
+	if existing, exists := r.books[change.BookID]; exists && !existing.IsDeleted() {
+		delete(r.isbn, existing.ISBN)
+	}
+
+	if change.Operation == domain.ChangePurge {
+		delete(r.books, change.BookID)
+	} else {
+		bookCopy := *change.Book
+		r.books[change.BookID] = &bookCopy
+		if !bookCopy.IsDeleted() {
+			r.isbn[bookCopy.ISBN] = bookCopy.ID
+		}
+	}
+
+	if change.Sequence > r.seq {
+		changeCopy := *change
+		r.log = append(r.log, &changeCopy)
+		r.seq = change.Sequence
+	}
+}
+
+func (r *InMemoryBookRepository) putOutboxMessage(message *domain.OutboxMessage) {
+	msgCopy := *message
+	for i, msg := range r.outbox {
+		if msg.ID == message.ID {
+			r.outbox[i] = &msgCopy
+			return
+		}
+	}
+	r.outbox = append(r.outbox, &msgCopy)
+}
+
+func (r *InMemoryBookRepository) fileSnapshot() *fileSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := &fileSnapshot{
+		Seq:    r.seq,
+		Books:  make([]*domain.Book, 0, len(r.books)),
+		Merges: make([]*domain.Merge, 0, len(r.merges)),
+		Outbox: r.outbox,
+		Log:    r.log,
+
+		History: r.history.all(),
+	}
+	for _, book := range r.books {
+		snapshot.Books = append(snapshot.Books, book)
+	}
+	for _, merge := range r.merges {
+		snapshot.Merges = append(snapshot.Merges, merge)
+	}
+	return snapshot
+}
+
+func (r *InMemoryBookRepository) restoreSnapshot(snapshot *fileSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, book := range snapshot.Books {
+		r.books[book.ID] = book
+		if !book.IsDeleted() {
+			r.isbn[book.ISBN] = book.ID
+		}
+	}
+	for _, merge := range snapshot.Merges {
+		r.merges[merge.SourceID] = merge
+	}
+	r.outbox = snapshot.Outbox
+	r.log = snapshot.Log
+	r.seq = snapshot.Seq
+	for _, entry := range snapshot.History {
+		r.history.put(entry)
+	}
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"solid/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openFileRepository(t *testing.T, config FileConfig) *FileBookRepository {
+	t.Helper()
+	repo, err := NewFileBookRepository(config)
+	if err != nil {
+		t.Fatalf("unexpected error opening repository: %v", err)
+	}
+	return repo
+}
+
+func TestFileBookRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("replays the log on startup", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		repo := openFileRepository(t, config)
+		kept := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		removed := &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"}
+		repo.Create(ctx, kept)
+		repo.Create(ctx, removed)
+		kept.Title = "Clean Coder"
+		repo.Update(ctx, kept)
+		repo.Delete(ctx, removed.ID)
+		repo.SaveMerge(ctx, &domain.Merge{SourceID: removed.ID, TargetID: kept.ID, MergedAt: time.Now()})
+
+		reopened := openFileRepository(t, config)
+
+		book, err := reopened.FindByISBN(ctx, "0132350882")
+		if err != nil || book.ID != kept.ID || book.Title != "Clean Coder" {
+			t.Errorf("expected updated book after replay, got %+v, %v", book, err)
+		}
+		if _, err := reopened.FindByID(ctx, removed.ID); err != domain.ErrBookNotFound {
+			t.Errorf("expected deleted book to stay deleted, got %v", err)
+		}
+		if merge, err := reopened.FindMerge(ctx, removed.ID); err != nil || merge.TargetID != kept.ID {
+			t.Errorf("expected merge after replay, got %+v, %v", merge, err)
+		}
+		changes, _ := reopened.Changes(ctx, 0, 0)
+		if len(changes) != 4 || changes[3].Sequence != 4 {
+			t.Errorf("expected 4 changes with preserved sequences, got %d", len(changes))
+		}
+		pending, _ := reopened.PendingOutbox(ctx, time.Now().Add(time.Hour), 0)
+		if len(pending) != 4 {
+			t.Errorf("expected 4 pending outbox messages, got %d", len(pending))
+		}
+
+		reopened.Create(ctx, &domain.Book{Title: "Clean Architecture", Author: "Robert Martin", ISBN: "0134494164"})
+		changes, _ = reopened.Changes(ctx, 0, 0)
+		if last := changes[len(changes)-1]; last.Sequence != 5 {
+			t.Errorf("expected sequence to continue at 5, got %d", last.Sequence)
+		}
+	})
+
+	t.Run("tolerates a torn final record", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		repo := openFileRepository(t, config)
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		repo.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"})
+
+		path := filepath.Join(config.Dir, walFileName)
+		info, _ := os.Stat(path)
+		os.Truncate(path, info.Size()-5)
+
+		reopened := openFileRepository(t, config)
+
+		books, _ := reopened.FindAll(ctx)
+		if len(books) != 1 || books[0].ISBN != "0132350882" {
+			t.Fatalf("expected only the intact record to be replayed, got %d books", len(books))
+		}
+		if err := reopened.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"}); err != nil {
+			t.Fatalf("unexpected error writing after recovery: %v", err)
+		}
+		if books, _ := openFileRepository(t, config).FindAll(ctx); len(books) != 2 {
+			t.Errorf("expected 2 books after writing past the torn record, got %d", len(books))
+		}
+	})
+
+	t.Run("truncates a corrupt final record", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		repo := openFileRepository(t, config)
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		repo.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"})
+
+		path := filepath.Join(config.Dir, walFileName)
+		data, _ := os.ReadFile(path)
+		data[len(data)-3] ^= 0xff
+		os.WriteFile(path, data, 0o644)
+
+		books, _ := openFileRepository(t, config).FindAll(ctx)
+		if len(books) != 1 || books[0].ISBN != "0132350882" {
+			t.Errorf("expected only the intact record to be replayed, got %d books", len(books))
+		}
+	})
+
+	t.Run("refuses to drop records after a corrupt one", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		repo := openFileRepository(t, config)
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		repo.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"})
+
+		path := filepath.Join(config.Dir, walFileName)
+		data, _ := os.ReadFile(path)
+		data[walHeaderSize+2] ^= 0xff
+		os.WriteFile(path, data, 0o644)
+
+		_, err := NewFileBookRepository(config)
+
+		if !errors.Is(err, errCorruptRecord) || !strings.Contains(err.Error(), "byte offset 0") {
+			t.Errorf("expected a corruption error at offset 0, got %v", err)
+		}
+		if after, _ := os.ReadFile(path); len(after) != len(data) {
+			t.Errorf("expected the wal to be left alone, got %d of %d bytes", len(after), len(data))
+		}
+	})
+
+	t.Run("refuses to drop records after a corrupt length", func(t *testing.T) {
+		for name, length := range map[string]uint32{"oversized": maxWALRecordSize + 1, "past the end": 1 << 20} {
+			t.Run(name, func(t *testing.T) {
+				config := DefaultFileConfig(t.TempDir())
+				repo := openFileRepository(t, config)
+				repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+				repo.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"})
+
+				path := filepath.Join(config.Dir, walFileName)
+				data, _ := os.ReadFile(path)
+				binary.BigEndian.PutUint32(data[0:4], length)
+				os.WriteFile(path, data, 0o644)
+
+				_, err := NewFileBookRepository(config)
+
+				if !errors.Is(err, errCorruptRecord) || !strings.Contains(err.Error(), "byte offset 0") {
+					t.Errorf("expected a corruption error at offset 0, got %v", err)
+				}
+				if after, _ := os.ReadFile(path); len(after) != len(data) {
+					t.Errorf("expected the wal to be left alone, got %d of %d bytes", len(after), len(data))
+				}
+			})
+		}
+	})
+
+	t.Run("compacts into snapshots", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		config.SnapshotEvery = 2
+		repo := openFileRepository(t, config)
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo.Create(ctx, book)
+		book.Title = "Clean Coder"
+		repo.Update(ctx, book)
+		repo.Delete(ctx, book.ID)
+
+		if _, err := os.Stat(filepath.Join(config.Dir, snapshotFileName)); err != nil {
+			t.Fatalf("expected snapshot file, got %v", err)
+		}
+		if repo.records != 1 {
+			t.Errorf("expected wal to be truncated after snapshot, got %d records", repo.records)
+		}
+
+		reopened := openFileRepository(t, config)
+
+		deleted, _ := reopened.FindDeleted(ctx)
+		if len(deleted) != 1 || deleted[0].Title != "Clean Coder" {
+			t.Errorf("expected tombstone from snapshot plus wal, got %+v", deleted)
+		}
+	})
+
+	t.Run("does not persist rolled back transactions", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		repo := openFileRepository(t, config)
+		failure := errors.New("boom")
+
+		repo.WithinTx(ctx, func(ctx context.Context) error {
+			repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+			return failure
+		})
+
+		if books, _ := openFileRepository(t, config).FindAll(ctx); len(books) != 0 {
+			t.Errorf("expected no books after rollback, got %d", len(books))
+		}
+	})
+
+	t.Run("persists history with the change", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		repo := openFileRepository(t, config)
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo.WithinTx(ctx, func(ctx context.Context) error {
+			repo.Create(ctx, book)
+			return repo.Append(ctx, &domain.AuditEntry{BookID: book.ID, Action: domain.ActionCreated, Actor: "alice"})
+		})
+		repo.Append(ctx, &domain.AuditEntry{BookID: book.ID, Action: domain.ActionUpdated, Changes: []domain.FieldChange{{Field: "title", From: "A", To: "B"}}})
+		repo.Snapshot(ctx)
+		repo.Append(ctx, &domain.AuditEntry{BookID: book.ID, Action: domain.ActionDeleted})
+
+		reopened := openFileRepository(t, config)
+
+		entries, err := reopened.FindByBook(ctx, book.ID)
+		if err != nil || len(entries) != 3 {
+			t.Fatalf("expected 3 entries after reopening, got %d (%v)", len(entries), err)
+		}
+		if entries[0].Actor != "alice" || entries[2].Version != 3 || len(entries[1].Changes) != 1 {
+			t.Errorf("unexpected entries %+v", entries)
+		}
+		next := &domain.AuditEntry{BookID: book.ID, Action: domain.ActionRestored}
+		reopened.Append(ctx, next)
+		if next.Version != 4 {
+			t.Errorf("expected versions to continue at 4, got %d", next.Version)
+		}
+	})
+
+	t.Run("close writes a final snapshot", func(t *testing.T) {
+		config := DefaultFileConfig(t.TempDir())
+		config.Sync = SyncInterval
+		config.SyncInterval = time.Millisecond
+		repo := openFileRepository(t, config)
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+
+		if err := repo.Close(); err != nil {
+			t.Fatalf("unexpected error closing: %v", err)
+		}
+
+		info, err := os.Stat(filepath.Join(config.Dir, walFileName))
+		if err != nil || info.Size() != 0 {
+			t.Errorf("expected empty wal after close, got %v, %v", info, err)
+		}
+		if books, _ := openFileRepository(t, config).FindAll(ctx); len(books) != 1 {
+			t.Errorf("expected book from snapshot, got %d", len(books))
+		}
+	})
+}
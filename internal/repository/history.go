@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"solid/internal/domain"
+)
+
+type InMemoryHistoryRepository struct {
+	mu      sync.RWMutex
+	entries map[string][]domain.AuditEntry
+}
+
+func NewInMemoryHistoryRepository() *InMemoryHistoryRepository {
+	return &InMemoryHistoryRepository{
+		entries: make(map[string][]domain.AuditEntry),
+	}
+}
+
+func (r *InMemoryHistoryRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.Version = len(r.entries[entry.BookID]) + 1
+
+	entryCopy := *entry
+	entryCopy.Changes = append([]domain.FieldChange(nil), entry.Changes...)
+	r.entries[entry.BookID] = append(r.entries[entry.BookID], entryCopy)
+	return nil
+}
+
+func (r *InMemoryHistoryRepository) FindByBook(ctx context.Context, bookID string) ([]*domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, exists := r.entries[bookID]
+	if !exists {
+		return nil, domain.ErrBookNotFound
+	}
+
+	entries := make([]*domain.AuditEntry, 0, len(stored))
+	for i := range stored {
+		entries = append(entries, copyEntry(&stored[i]))
+	}
+	return entries, nil
+}
+
+func (r *InMemoryHistoryRepository) FindVersion(ctx context.Context, bookID string, version int) (*domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, exists := r.entries[bookID]
+	if !exists {
+		return nil, domain.ErrBookNotFound
+	}
+	if version < 1 || version > len(stored) {
+		return nil, domain.ErrVersionNotFound
+	}
+	return copyEntry(&stored[version-1]), nil
+}
+
+// all returns every entry, each book's in version order, for snapshots.
+func (r *InMemoryHistoryRepository) all() []domain.AuditEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]domain.AuditEntry, 0, len(r.entries))
+	for _, stored := range r.entries {
+		entries = append(entries, stored...)
+	}
+	return entries
+}
+
+// put stores an entry that already has its version, as replayed from disk.
+func (r *InMemoryHistoryRepository) put(entry domain.AuditEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[entry.BookID] = append(r.entries[entry.BookID], entry)
+}
+
+// snapshot copies the per-book slice headers; entries are append-only, so
+// restoring them drops whatever was appended since.
+func (r *InMemoryHistoryRepository) snapshot() map[string][]domain.AuditEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make(map[string][]domain.AuditEntry, len(r.entries))
+	for bookID, stored := range r.entries {
+		entries[bookID] = stored[:len(stored):len(stored)]
+	}
+	return entries
+}
+
+func (r *InMemoryHistoryRepository) restore(entries map[string][]domain.AuditEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = entries
+}
+
+// Append records the audit entry under the book repository's transaction,
+// so it is rolled back together with the change it describes.
+func (r *InMemoryBookRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	defer r.acquire(ctx, true)()
+	return r.history.Append(ctx, entry)
+}
+
+func (r *InMemoryBookRepository) FindByBook(ctx context.Context, bookID string) ([]*domain.AuditEntry, error) {
+	defer r.acquire(ctx, false)()
+	return r.history.FindByBook(ctx, bookID)
+}
+
+func (r *InMemoryBookRepository) FindVersion(ctx context.Context, bookID string, version int) (*domain.AuditEntry, error) {
+	defer r.acquire(ctx, false)()
+	return r.history.FindVersion(ctx, bookID, version)
+}
+
+// Append writes the audit entry into the same WAL record as the change it
+// describes, so a committed change always has its history.
+func (r *FileBookRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	return r.mutate(ctx, func(ctx context.Context) ([]walEntry, error) {
+		if err := r.mem.Append(ctx, entry); err != nil {
+			return nil, err
+		}
+		return []walEntry{{Op: walAudit, Audit: copyEntry(entry)}}, nil
+	})
+}
+
+func (r *FileBookRepository) FindByBook(ctx context.Context, bookID string) ([]*domain.AuditEntry, error) {
+	return r.mem.FindByBook(ctx, bookID)
+}
+
+func (r *FileBookRepository) FindVersion(ctx context.Context, bookID string, version int) (*domain.AuditEntry, error) {
+	return r.mem.FindVersion(ctx, bookID, version)
+}
+
+func copyEntry(entry *domain.AuditEntry) *domain.AuditEntry {
+	entryCopy := *entry
+	entryCopy.Changes = append([]domain.FieldChange(nil), entry.Changes...)
+	return &entryCopy
+}
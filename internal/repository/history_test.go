@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"solid/internal/domain"
+	"testing"
+)
+
+func TestInMemoryHistoryRepository_Append(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryHistoryRepository()
+
+	first := &domain.AuditEntry{BookID: "book-1", Action: domain.ActionCreated}
+	second := &domain.AuditEntry{BookID: "book-1", Action: domain.ActionUpdated}
+	other := &domain.AuditEntry{BookID: "book-2", Action: domain.ActionCreated}
+	repo.Append(ctx, first)
+	repo.Append(ctx, second)
+	repo.Append(ctx, other)
+
+	if first.Version != 1 || second.Version != 2 || other.Version != 1 {
+		t.Errorf("expected per-book versions 1, 2, 1, got %d, %d, %d", first.Version, second.Version, other.Version)
+	}
+
+	entries, err := repo.FindByBook(ctx, "book-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	entries[0].Action = "tampered"
+	stored, _ := repo.FindVersion(ctx, "book-1", 1)
+	if stored.Action != domain.ActionCreated {
+		t.Error("expected stored entries to be immutable")
+	}
+}
+
+func TestInMemoryHistoryRepository_FindVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryHistoryRepository()
+	repo.Append(ctx, &domain.AuditEntry{BookID: "book-1", Action: domain.ActionCreated})
+
+	t.Run("unknown book", func(t *testing.T) {
+		_, err := repo.FindVersion(ctx, "missing", 1)
+
+		if err != domain.ErrBookNotFound {
+			t.Errorf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := repo.FindVersion(ctx, "book-1", 2)
+
+		if err != domain.ErrVersionNotFound {
+			t.Errorf("expected ErrVersionNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"solid/internal/domain"
+)
+
+func (r *InMemoryBookRepository) PendingOutbox(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	messages := make([]*domain.OutboxMessage, 0)
+	for _, msg := range r.outbox {
+		if limit > 0 && len(messages) >= limit {
+			break
+		}
+		if msg.DeadAt != nil || msg.NextAttemptAt.After(now) {
+			continue
+		}
+		msgCopy := *msg
+		messages = append(messages, &msgCopy)
+	}
+	return messages, nil
+}
+
+func (r *InMemoryBookRepository) MarkDelivered(ctx context.Context, id string) error {
+	defer r.acquire(ctx, true)()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, msg := range r.outbox {
+		if msg.ID == id {
+			r.outbox = append(r.outbox[:i], r.outbox[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrOutboxMessageNotFound
+}
+
+func (r *InMemoryBookRepository) MarkFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	defer r.acquire(ctx, true)()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, msg := range r.outbox {
+		if msg.ID == id {
+			msg.Attempts++
+			msg.LastError = reason
+			msg.NextAttemptAt = nextAttemptAt
+			return nil
+		}
+	}
+	return domain.ErrOutboxMessageNotFound
+}
+
+// MarkDead keeps a message that ran out of attempts as a dead letter so it
+// stays inspectable without being picked up by PendingOutbox again.
+func (r *InMemoryBookRepository) MarkDead(ctx context.Context, id string, reason string) error {
+	defer r.acquire(ctx, true)()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, msg := range r.outbox {
+		if msg.ID == id {
+			now := time.Now()
+			msg.Attempts++
+			msg.LastError = reason
+			msg.DeadAt = &now
+			return nil
+		}
+	}
+	return domain.ErrOutboxMessageNotFound
+}
+
+func (r *InMemoryBookRepository) DeadOutbox(ctx context.Context) ([]*domain.OutboxMessage, error) {
+	defer r.acquire(ctx, false)()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	messages := make([]*domain.OutboxMessage, 0)
+	for _, msg := range r.outbox {
+		if msg.DeadAt != nil {
+			msgCopy := *msg
+			messages = append(messages, &msgCopy)
+		}
+	}
+	return messages, nil
+}
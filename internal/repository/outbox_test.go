@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"solid/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestInMemoryBookRepository_Outbox(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes are recorded with the book change", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo.Create(ctx, book)
+		book.Title = "Clean Coder"
+		repo.Update(ctx, book)
+		repo.Delete(ctx, book.ID)
+
+		pending, err := repo.PendingOutbox(ctx, time.Now(), 0)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{domain.EventBookCreated, domain.EventBookUpdated, domain.EventBookDeleted}
+		if len(pending) != len(want) {
+			t.Fatalf("expected %d messages, got %d", len(want), len(pending))
+		}
+		for i, msg := range pending {
+			if msg.EventType != want[i] || msg.AggregateID != book.ID || msg.ID == "" {
+				t.Errorf("unexpected message %d: %+v", i, msg)
+			}
+		}
+	})
+
+	t.Run("failed writes leave no message", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+
+		pending, _ := repo.PendingOutbox(ctx, time.Now(), 0)
+
+		if len(pending) != 1 {
+			t.Errorf("expected 1 message, got %d", len(pending))
+		}
+	})
+
+	t.Run("dead messages leave the pending list", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		pending, _ := repo.PendingOutbox(ctx, time.Now(), 0)
+
+		if err := repo.MarkDead(ctx, pending[0].ID, "sink unavailable"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if pending, _ := repo.PendingOutbox(ctx, time.Now().Add(time.Hour), 0); len(pending) != 0 {
+			t.Errorf("expected no pending messages, got %d", len(pending))
+		}
+		dead, _ := repo.DeadOutbox(ctx)
+		if len(dead) != 1 || dead[0].LastError != "sink unavailable" {
+			t.Errorf("expected 1 dead letter, got %+v", dead)
+		}
+	})
+
+	t.Run("mark unknown message", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+
+		if err := repo.MarkDelivered(ctx, "missing"); err != domain.ErrOutboxMessageNotFound {
+			t.Errorf("expected ErrOutboxMessageNotFound, got %v", err)
+		}
+		if err := repo.MarkDead(ctx, "missing", ""); err != domain.ErrOutboxMessageNotFound {
+			t.Errorf("expected ErrOutboxMessageNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+
+	"solid/internal/domain"
+)
+
+type txKey struct{}
+
+type inMemorySnapshot struct {
+	books  map[string]*domain.Book
+	isbn   map[string]string
+	merges map[string]*domain.Merge
+	outbox []*domain.OutboxMessage
+	log    []*domain.Change
+	seq    uint64
+
+	history map[string][]domain.AuditEntry
+}
+
+func (r *InMemoryBookRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if r.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	r.txMu.Lock()
+	defer r.txMu.Unlock()
+
+	snapshot := r.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			r.rollback(snapshot)
+			panic(p)
+		}
+		if err != nil {
+			r.rollback(snapshot)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, r))
+}
+
+func (r *InMemoryBookRepository) inTx(ctx context.Context) bool {
+	owner, _ := ctx.Value(txKey{}).(*InMemoryBookRepository)
+	return owner == r
+}
+
+func (r *InMemoryBookRepository) acquire(ctx context.Context, write bool) func() {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+	if write {
+		r.txMu.Lock()
+		return r.txMu.Unlock
+	}
+	r.txMu.RLock()
+	return r.txMu.RUnlock
+}
+
+func (r *InMemoryBookRepository) snapshot() *inMemorySnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s := &inMemorySnapshot{
+		books:  make(map[string]*domain.Book, len(r.books)),
+		isbn:   make(map[string]string, len(r.isbn)),
+		merges: make(map[string]*domain.Merge, len(r.merges)),
+		outbox: make([]*domain.OutboxMessage, 0, len(r.outbox)),
+		log:    r.log,
+		seq:    r.seq,
+
+		history: r.history.snapshot(),
+	}
+	for id, book := range r.books {
+		s.books[id] = book
+	}
+	for isbn, id := range r.isbn {
+		s.isbn[isbn] = id
+	}
+	for id, merge := range r.merges {
+		mergeCopy := *merge
+		s.merges[id] = &mergeCopy
+	}
+	for _, msg := range r.outbox {
+		msgCopy := *msg
+		s.outbox = append(s.outbox, &msgCopy)
+	}
+	return s
+}
+
+func (r *InMemoryBookRepository) rollback(s *inMemorySnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.books = s.books
+	r.isbn = s.isbn
+	r.merges = s.merges
+	r.outbox = s.outbox
+	r.log = s.log[:len(s.log):len(s.log)]
+	r.seq = s.seq
+	r.history.restore(s.history)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"solid/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestInMemoryBookRepository_WithinTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commits on success", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+
+		err := repo.WithinTx(ctx, func(ctx context.Context) error {
+			return repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.FindByISBN(ctx, "0132350882"); err != nil {
+			t.Errorf("expected committed book, got %v", err)
+		}
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		existing := &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"}
+		repo.Create(ctx, existing)
+		failure := errors.New("boom")
+
+		repo.Append(ctx, &domain.AuditEntry{BookID: existing.ID, Action: domain.ActionCreated})
+
+		err := repo.WithinTx(ctx, func(ctx context.Context) error {
+			repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+			repo.Append(ctx, &domain.AuditEntry{BookID: existing.ID, Action: domain.ActionUpdated})
+			updated := *existing
+			updated.Title = "Refactoring 2nd Edition"
+			repo.Update(ctx, &updated)
+			repo.Delete(ctx, existing.ID)
+			return failure
+		})
+
+		if err != failure {
+			t.Fatalf("expected tx error, got %v", err)
+		}
+		if _, err := repo.FindByISBN(ctx, "0132350882"); err != domain.ErrBookNotFound {
+			t.Errorf("expected created book to be rolled back, got %v", err)
+		}
+		book, err := repo.FindByID(ctx, existing.ID)
+		if err != nil || book.Title != "Refactoring" {
+			t.Errorf("expected original book to survive, got %+v, %v", book, err)
+		}
+		changes, _ := repo.Changes(ctx, 0, 0)
+		if len(changes) != 1 {
+			t.Errorf("expected change log to be rolled back to 1 entry, got %d", len(changes))
+		}
+		if entries, _ := repo.FindByBook(ctx, existing.ID); len(entries) != 1 {
+			t.Errorf("expected history to be rolled back to 1 entry, got %d", len(entries))
+		}
+		pending, _ := repo.PendingOutbox(ctx, time.Now().Add(time.Hour), 0)
+		if len(pending) != 1 {
+			t.Errorf("expected outbox to be rolled back to 1 message, got %d", len(pending))
+		}
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+
+		func() {
+			defer func() { recover() }()
+			repo.WithinTx(ctx, func(ctx context.Context) error {
+				repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+				panic("boom")
+			})
+		}()
+
+		if books, _ := repo.FindAll(ctx); len(books) != 0 {
+			t.Errorf("expected no books after panic, got %d", len(books))
+		}
+	})
+
+	t.Run("nested calls join the outer transaction", func(t *testing.T) {
+		repo := NewInMemoryBookRepository()
+		failure := errors.New("boom")
+
+		repo.WithinTx(ctx, func(ctx context.Context) error {
+			repo.WithinTx(ctx, func(ctx context.Context) error {
+				return repo.Create(ctx, &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+			})
+			return failure
+		})
+
+		if books, _ := repo.FindAll(ctx); len(books) != 0 {
+			t.Errorf("expected nested write to be rolled back, got %d books", len(books))
+		}
+	})
+}
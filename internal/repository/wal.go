@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+
+	"solid/internal/domain"
+)
+
+const (
+	walChange          = "change"
+	walMerge           = "merge"
+	walOutboxPut       = "outbox_put"
+	walOutboxDelivered = "outbox_delivered"
+	walAudit           = "audit"
+)
+
+const (
+	walHeaderSize    = 8
+	maxWALRecordSize = 64 << 20
+)
+
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// errTornRecord is a final record cut short by a crash mid-write.
+	errTornRecord = errors.New("torn wal record")
+	// errCorruptRecord is a complete record whose length, checksum or
+	// payload is invalid.
+	errCorruptRecord = errors.New("corrupt wal record")
+)
+
+type walEntry struct {
+	Op        string                `json:"op"`
+	Change    *domain.Change        `json:"change,omitempty"`
+	Merge     *domain.Merge         `json:"merge,omitempty"`
+	Message   *domain.OutboxMessage `json:"message,omitempty"`
+	MessageID string                `json:"message_id,omitempty"`
+	Audit     *domain.AuditEntry    `json:"audit,omitempty"`
+}
+
+type walRecord struct {
+	LSN     uint64     `json:"lsn"`
+	Entries []walEntry `json:"entries"`
+}
+
+func encodeWALRecord(record walRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, walHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(payload, walTable))
+	copy(buf[walHeaderSize:], payload)
+	return buf, nil
+}
+
+// readWALRecord reads the next record and its size on disk. For a corrupt
+// record the size is the one its header declares.
+func readWALRecord(r io.Reader) (walRecord, int64, error) {
+	var record walRecord
+
+	header := make([]byte, walHeaderSize)
+	if n, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF && n == 0 {
+			return record, 0, io.EOF
+		}
+		return record, 0, errTornRecord
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	size := int64(walHeaderSize) + int64(length)
+	if length > maxWALRecordSize {
+		return record, size, errCorruptRecord
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return record, size, errTornRecord
+	}
+	if crc32.Checksum(payload, walTable) != checksum {
+		return record, size, errCorruptRecord
+	}
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, size, errCorruptRecord
+	}
+	return record, size, nil
+}
+
+// replayWAL applies every record of the log. A bad record that runs to the
+// end of the file is the torn tail of an interrupted write and is truncated
+// away; a bad record followed by more data is corruption, which is reported
+// instead of discarding the valid records after it. So is a record whose
+// header claims more than the file holds, when that length is impossible or
+// a valid record still follows: a torn write only cuts off the end.
+func replayWAL(file *os.File, apply func(walRecord)) (int64, int, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	records := 0
+	for {
+		record, size, err := readWALRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err == errCorruptRecord && offset+size < info.Size() {
+			return 0, 0, fmt.Errorf("%s: record %d at byte offset %d is corrupt and followed by %d more bytes; "+
+				"restore the file from a backup, or truncate it to %d bytes to keep only the records before it: %w",
+				file.Name(), records+1, offset, info.Size()-offset-size, offset, err)
+		}
+		if err != nil && size > 0 {
+			corrupt := size-walHeaderSize > maxWALRecordSize
+			if !corrupt {
+				var scanErr error
+				if corrupt, scanErr = followedByRecord(file, offset+walHeaderSize, info.Size()); scanErr != nil {
+					return 0, 0, scanErr
+				}
+			}
+			if corrupt {
+				return 0, 0, fmt.Errorf("%s: record %d at byte offset %d declares %d bytes but only %d follow it, and they are not a torn write; "+
+					"restore the file from a backup, or truncate it to %d bytes to keep only the records before it: %w",
+					file.Name(), records+1, offset, size-walHeaderSize, info.Size()-offset-walHeaderSize, offset, errCorruptRecord)
+			}
+		}
+		if err != nil {
+			log.Printf("%s: dropping torn final record at byte offset %d (%d bytes)", file.Name(), offset, info.Size()-offset)
+			if err := file.Truncate(offset); err != nil {
+				return 0, 0, err
+			}
+			break
+		}
+		apply(record)
+		offset += size
+		records++
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	return offset, records, nil
+}
+
+// walPayloadPrefix starts every encoded record payload.
+var walPayloadPrefix = []byte(`{"lsn":`)
+
+// followedByRecord reports whether a complete, valid record starts anywhere
+// in file between from and end. Only offsets where a payload could begin
+// are tried.
+func followedByRecord(file *os.File, from, end int64) (bool, error) {
+	data := make([]byte, end-from)
+	if _, err := file.ReadAt(data, from); err != nil {
+		return false, err
+	}
+
+	for i := 0; ; i++ {
+		next := bytes.Index(data[i:], walPayloadPrefix)
+		if next < 0 {
+			return false, nil
+		}
+		i += next
+		if i < walHeaderSize {
+			continue
+		}
+		if _, _, err := readWALRecord(bytes.NewReader(data[i-walHeaderSize:])); err == nil {
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"solid/internal/domain"
+)
+
+type InMemoryWebhookRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*domain.Subscription
+	deliveries    map[string]*domain.Delivery
+}
+
+func NewInMemoryWebhookRepository() *InMemoryWebhookRepository {
+	return &InMemoryWebhookRepository{
+		subscriptions: make(map[string]*domain.Subscription),
+		deliveries:    make(map[string]*domain.Delivery),
+	}
+}
+
+func (r *InMemoryWebhookRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[sub.ID] = copySubscription(sub)
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) FindSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, exists := r.subscriptions[id]
+	if !exists {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	return copySubscription(sub), nil
+}
+
+func (r *InMemoryWebhookRepository) FindSubscriptions(ctx context.Context) ([]*domain.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := make([]*domain.Subscription, 0, len(r.subscriptions))
+	for _, sub := range r.subscriptions {
+		subs = append(subs, copySubscription(sub))
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+	})
+	return subs, nil
+}
+
+func (r *InMemoryWebhookRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subscriptions[sub.ID]; !exists {
+		return domain.ErrSubscriptionNotFound
+	}
+	r.subscriptions[sub.ID] = copySubscription(sub)
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subscriptions[id]; !exists {
+		return domain.ErrSubscriptionNotFound
+	}
+	delete(r.subscriptions, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.SubscriptionID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subscriptions[delivery.SubscriptionID]; !exists {
+		return domain.ErrSubscriptionNotFound
+	}
+	r.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) FindDelivery(ctx context.Context, id string) (*domain.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, exists := r.deliveries[id]
+	if !exists {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	return copyDelivery(delivery), nil
+}
+
+func (r *InMemoryWebhookRepository) FindDeliveries(ctx context.Context, subscriptionID string) ([]*domain.Delivery, error) {
+	return r.findDeliveries(0, func(d *domain.Delivery) bool {
+		return d.SubscriptionID == subscriptionID
+	}), nil
+}
+
+func (r *InMemoryWebhookRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.Delivery, error) {
+	return r.findDeliveries(limit, func(d *domain.Delivery) bool {
+		return d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now)
+	}), nil
+}
+
+func (r *InMemoryWebhookRepository) FindDeadDeliveries(ctx context.Context) ([]*domain.Delivery, error) {
+	return r.findDeliveries(0, func(d *domain.Delivery) bool {
+		return d.Status == domain.DeliveryDead
+	}), nil
+}
+
+func (r *InMemoryWebhookRepository) PruneDeliveries(ctx context.Context, finishedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pruned := 0
+	for id, delivery := range r.deliveries {
+		if finishedAt, finished := delivery.FinishedAt(); finished && finishedAt.Before(finishedBefore) {
+			delete(r.deliveries, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+func (r *InMemoryWebhookRepository) findDeliveries(limit int, match func(*domain.Delivery) bool) []*domain.Delivery {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]*domain.Delivery, 0)
+	for _, delivery := range r.deliveries {
+		if match(delivery) {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries
+}
+
+func copySubscription(sub *domain.Subscription) *domain.Subscription {
+	subCopy := *sub
+	subCopy.Events = append([]string(nil), sub.Events...)
+	return &subCopy
+}
+
+func copyDelivery(delivery *domain.Delivery) *domain.Delivery {
+	deliveryCopy := *delivery
+	deliveryCopy.Attempts = append([]domain.DeliveryAttempt(nil), delivery.Attempts...)
+	return &deliveryCopy
+}
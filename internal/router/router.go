@@ -0,0 +1,104 @@
+package router
+
+import (
+	"net/http"
+
+	"solid/internal/handler"
+	"solid/internal/middleware"
+	"solid/internal/render"
+
+	"github.com/gorilla/mux"
+)
+
+type Handlers struct {
+	Book         *handler.BookHandler
+	Webhook      *handler.WebhookHandler
+	ChangeStream *handler.ChangeStreamHandler
+	GraphQL      *handler.GraphQLHandler
+	OpenAPI      *handler.OpenAPIHandler
+}
+
+type Config struct {
+	Idempotency *middleware.IdempotencyStore
+	Development bool
+	// Unversioned describes the deprecation of the unversioned paths
+	// (/books instead of /v1/books), which are served by the latest version.
+	Unversioned middleware.Deprecation
+	// MaxBodySize caps request bodies in bytes; zero means
+	// middleware.DefaultMaxBodySize.
+	MaxBodySize int64
+}
+
+var (
+	versions      = []string{"1"}
+	latestVersion = "1"
+)
+
+func New(handlers Handlers, config Config) *mux.Router {
+	router := mux.NewRouter()
+
+	router.HandleFunc("/health", healthCheck).Methods(http.MethodGet)
+	router.HandleFunc("/openapi.json", handlers.OpenAPI.Spec).Methods(http.MethodGet)
+	router.HandleFunc("/docs", handlers.OpenAPI.Docs).Methods(http.MethodGet)
+	router.HandleFunc("/graphql", handlers.GraphQL.Query).Methods(http.MethodPost)
+	if config.Development {
+		router.HandleFunc("/graphql", handlers.GraphQL.GraphiQL).Methods(http.MethodGet)
+	}
+
+	// The subrouter has no PathPrefix matcher of its own: mux lets an
+	// inherited prefix match hide method mismatches, turning 405s into 404s.
+	v1 := router.NewRoute().Subrouter()
+	v1.Use(middleware.RequireVersion("1"))
+	// The event stream negotiates text/event-stream itself; every other
+	// route renders through the codec registry.
+	v1.HandleFunc("/v1/books/changes", handlers.ChangeStream.Stream).
+		Methods(http.MethodGet).
+		HeadersRegexp("Accept", "text/event-stream")
+	rendered := v1.NewRoute().Subrouter()
+	rendered.Use(middleware.Negotiate(render.Default))
+	registerV1(rendered, handlers)
+
+	router.NotFoundHandler = middleware.VersionFallback(router, versions, latestVersion, config.Unversioned)
+
+	router.Use(middleware.Recovery)
+	router.Use(middleware.RequestID)
+	router.Use(middleware.Logger)
+	router.Use(middleware.MaxBodySize(config.MaxBodySize))
+	router.Use(middleware.Idempotency(config.Idempotency))
+
+	return router
+}
+
+func registerV1(api *mux.Router, handlers Handlers) {
+	api.HandleFunc("/v1/books", handlers.Book.Create).Methods(http.MethodPost)
+	api.HandleFunc("/v1/books", handlers.Book.List).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/batch", handlers.Book.Batch).Methods(http.MethodPost)
+	api.HandleFunc("/v1/books/suggest", handlers.Book.Suggest).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/duplicates", handlers.Book.Duplicates).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/trash", handlers.Book.Trash).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/changes", handlers.Book.Changes).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/{id}", handlers.Book.GetByID).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/{id}", handlers.Book.Update).Methods(http.MethodPut)
+	api.HandleFunc("/v1/books/{id}", handlers.Book.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/v1/books/{id}/merge", handlers.Book.Merge).Methods(http.MethodPost)
+	api.HandleFunc("/v1/books/{id}/merges", handlers.Book.Merges).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/{id}/restore", handlers.Book.Restore).Methods(http.MethodPost)
+	api.HandleFunc("/v1/books/{id}/history", handlers.Book.History).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/{id}/history/{version}", handlers.Book.Version).Methods(http.MethodGet)
+	api.HandleFunc("/v1/books/{id}/history/{version}/revert", handlers.Book.Revert).Methods(http.MethodPost)
+
+	api.HandleFunc("/v1/webhooks", handlers.Webhook.Create).Methods(http.MethodPost)
+	api.HandleFunc("/v1/webhooks", handlers.Webhook.List).Methods(http.MethodGet)
+	api.HandleFunc("/v1/webhooks/dead-letters", handlers.Webhook.DeadLetters).Methods(http.MethodGet)
+	api.HandleFunc("/v1/webhooks/{id}", handlers.Webhook.GetByID).Methods(http.MethodGet)
+	api.HandleFunc("/v1/webhooks/{id}", handlers.Webhook.Update).Methods(http.MethodPut)
+	api.HandleFunc("/v1/webhooks/{id}", handlers.Webhook.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/v1/webhooks/{id}/deliveries", handlers.Webhook.Deliveries).Methods(http.MethodGet)
+	api.HandleFunc("/v1/webhooks/{id}/deliveries/{deliveryID}/redeliver", handlers.Webhook.Redeliver).Methods(http.MethodPost)
+}
+
+func healthCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status":"healthy"}`))
+}
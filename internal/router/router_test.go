@@ -0,0 +1,68 @@
+package router_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"solid/api/openapi"
+	"solid/internal/router/routertest"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRoutesAreDocumented(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec, &spec); err != nil {
+		t.Fatalf("invalid openapi document: %v", err)
+	}
+
+	routed := make(map[string]bool)
+	err := routertest.New(t).Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			// The route holding the v1 subrouter has no methods of its own.
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			routed[strings.ToLower(method)+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error walking routes: %v", err)
+	}
+
+	// Paths with their own servers entry live at the root; all others are
+	// relative to the /v1 server.
+	documented := make(map[string]bool)
+	for path, item := range spec.Paths {
+		prefix := "/v1"
+		if _, ok := item["servers"]; ok {
+			prefix = ""
+		}
+		for method := range item {
+			if method == "parameters" || method == "servers" {
+				continue
+			}
+			documented[method+" "+prefix+path] = true
+		}
+	}
+
+	for route := range routed {
+		if !documented[route] {
+			t.Errorf("route %s is not documented", route)
+		}
+	}
+	for route := range documented {
+		if !routed[route] {
+			t.Errorf("documented operation %s has no route", route)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package routertest wires the full API router over in-memory repositories
+// for tests that exercise handlers and middleware through real requests.
+package routertest
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+	"solid/internal/event"
+	"solid/internal/gql"
+	"solid/internal/handler"
+	"solid/internal/middleware"
+	"solid/internal/outbox"
+	"solid/internal/repository"
+	"solid/internal/router"
+	"solid/internal/service"
+	"solid/internal/stream"
+
+	"github.com/gorilla/mux"
+)
+
+// MaxBodySize is the request body limit of the test router.
+const MaxBodySize = 64 << 10
+
+// DeprecatedAt and SunsetAt are announced on unversioned paths.
+var (
+	DeprecatedAt = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	SunsetAt     = time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// New returns a router serving every API route from fresh in-memory
+// repositories.
+func New(t testing.TB) *mux.Router {
+	t.Helper()
+
+	bookRepository := repository.NewInMemoryBookRepository()
+	eventBus := event.NewBus()
+	bookService := service.NewBookService(
+		bookRepository,
+		service.WithHistory(repository.NewInMemoryHistoryRepository()),
+		service.WithPublisher(eventBus),
+		service.WithChangeLog(bookRepository),
+	)
+	webhookService := service.NewWebhookService(repository.NewInMemoryWebhookRepository())
+	// Drain the outbox after every change so webhook deliveries exist by the
+	// time the request returns.
+	dispatcher := outbox.NewDispatcher(bookRepository, outbox.DefaultConfig(), webhookService)
+	eventBus.Subscribe(event.AllEvents, func(ctx context.Context, e domain.Event) error {
+		_, err := dispatcher.DispatchPending(ctx)
+		return err
+	})
+
+	config := gql.DefaultConfig()
+	schema, err := gql.NewSchema(bookService, config)
+	if err != nil {
+		t.Fatalf("unexpected error parsing graphql schema: %v", err)
+	}
+	broker, err := stream.NewBroker(bookRepository, stream.DefaultReplaySize, stream.DefaultClientBuffer)
+	if err != nil {
+		t.Fatalf("unexpected error starting change stream: %v", err)
+	}
+
+	return router.New(
+		router.Handlers{
+			Book:         handler.NewBookHandler(bookService),
+			Webhook:      handler.NewWebhookHandler(webhookService),
+			ChangeStream: handler.NewChangeStreamHandler(broker),
+			GraphQL:      handler.NewGraphQLHandler(schema, config.MaxComplexity),
+			OpenAPI:      handler.NewOpenAPIHandler(),
+		},
+		router.Config{
+			Idempotency: middleware.NewIdempotencyStore(time.Hour),
+			Unversioned: middleware.Deprecation{At: DeprecatedAt, Sunset: SunsetAt},
+			MaxBodySize: MaxBodySize,
+		},
+	)
+}
+
+// NewServer serves New over HTTP until the test ends.
+func NewServer(t testing.TB) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(New(t))
+	t.Cleanup(server.Close)
+	return server
+}
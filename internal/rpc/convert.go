@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"time"
+
+	bookv1 "solid/api/proto/book/v1"
+	"solid/internal/domain"
+	"solid/internal/service"
+	"solid/internal/suggest"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoBook(book *domain.Book) *bookv1.Book {
+	if book == nil {
+		return nil
+	}
+	return &bookv1.Book{
+		Id:        book.ID,
+		Title:     book.Title,
+		Author:    book.Author,
+		Isbn:      book.ISBN,
+		CreatedAt: toTimestamp(book.CreatedAt),
+		UpdatedAt: toTimestamp(book.UpdatedAt),
+		DeletedAt: toOptionalTimestamp(book.DeletedAt),
+	}
+}
+
+func toProtoBooks(books []*domain.Book) []*bookv1.Book {
+	result := make([]*bookv1.Book, len(books))
+	for i, book := range books {
+		result[i] = toProtoBook(book)
+	}
+	return result
+}
+
+func toProtoChange(change *domain.Change) *bookv1.Change {
+	return &bookv1.Change{
+		Sequence:  change.Sequence,
+		Operation: change.Operation,
+		BookId:    change.BookID,
+		Book:      toProtoBook(change.Book),
+		Timestamp: toTimestamp(change.Timestamp),
+	}
+}
+
+func toProtoMerge(merge *domain.Merge) *bookv1.Merge {
+	return &bookv1.Merge{
+		SourceId: merge.SourceID,
+		TargetId: merge.TargetID,
+		Fields:   merge.Fields,
+		MergedAt: toTimestamp(merge.MergedAt),
+	}
+}
+
+func toProtoAuditEntry(entry *domain.AuditEntry) *bookv1.AuditEntry {
+	changes := make([]*bookv1.FieldChange, len(entry.Changes))
+	for i, change := range entry.Changes {
+		changes[i] = &bookv1.FieldChange{Field: change.Field, From: change.From, To: change.To}
+	}
+	return &bookv1.AuditEntry{
+		BookId:    entry.BookID,
+		Version:   int32(entry.Version),
+		Action:    entry.Action,
+		Actor:     entry.Actor,
+		RequestId: entry.RequestID,
+		Timestamp: toTimestamp(entry.Timestamp),
+		Changes:   changes,
+		Snapshot:  toProtoBook(&entry.Snapshot),
+	}
+}
+
+func toProtoSuggestions(suggestions []suggest.Suggestion) []*bookv1.Suggestion {
+	result := make([]*bookv1.Suggestion, len(suggestions))
+	for i, suggestion := range suggestions {
+		result[i] = &bookv1.Suggestion{Text: suggestion.Text, BookId: suggestion.BookID}
+	}
+	return result
+}
+
+func toProtoDuplicateGroups(groups []service.DuplicateGroup) []*bookv1.DuplicateGroup {
+	result := make([]*bookv1.DuplicateGroup, len(groups))
+	for i, group := range groups {
+		books := make([]*bookv1.DuplicateCandidate, len(group.Books))
+		for j, candidate := range group.Books {
+			books[j] = &bookv1.DuplicateCandidate{
+				BookId: candidate.BookID,
+				Title:  candidate.Title,
+				Author: candidate.Author,
+				Score:  candidate.Score,
+			}
+		}
+		result[i] = &bookv1.DuplicateGroup{Books: books}
+	}
+	return result
+}
+
+func toProtoBatchResult(index int, result service.BatchResult) *bookv1.BatchResult {
+	resp := &bookv1.BatchResult{Index: int32(index), Book: toProtoBook(result.Book)}
+	if result.Err != nil {
+		resp.Error = result.Err.Error()
+		resp.Code = errorCode(result.Err)
+	}
+	return resp
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toOptionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"solid/internal/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "solid"
+
+// reasonCodes maps domain errors by code where the HTTP status says too
+// little: a 409 may be a taken ISBN, a merged book or a rolled back batch,
+// and a gRPC client reacts differently to each.
+var reasonCodes = map[string]codes.Code{
+	domain.ErrBookAlreadyExists.Code: codes.FailedPrecondition,
+	domain.ErrPossibleDuplicate.Code: codes.FailedPrecondition,
+	domain.ErrBookMerged.Code:        codes.FailedPrecondition,
+	domain.ErrBatchAborted.Code:      codes.Aborted,
+	domain.ErrChangesExpired.Code:    codes.OutOfRange,
+}
+
+// createCodes overrides reasonCodes for CreateBook: only a create can fail
+// because the book already exists.
+var createCodes = map[string]codes.Code{
+	domain.ErrBookAlreadyExists.Code: codes.AlreadyExists,
+	domain.ErrPossibleDuplicate.Code: codes.AlreadyExists,
+}
+
+// statusCodes is the fallback for domain errors without a reason code.
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusGone:                codes.OutOfRange,
+	http.StatusFailedDependency:    codes.Aborted,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+}
+
+func toStatus(err error) error {
+	return statusWith(err, nil)
+}
+
+func toCreateStatus(err error) error {
+	return statusWith(err, createCodes)
+}
+
+func statusWith(err error, overrides map[string]codes.Code) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code, ok := overrides[domainErr.Code]
+	if !ok {
+		code, ok = reasonCodes[domainErr.Code]
+	}
+	if !ok {
+		code, ok = statusCodes[domainErr.StatusCode]
+	}
+	if !ok {
+		code = codes.Internal
+	}
+
+	info := &errdetails.ErrorInfo{Reason: domainErr.Code, Domain: errorDomain}
+	if domainErr.Details != nil {
+		if details, err := json.Marshal(domainErr.Details); err == nil {
+			info.Metadata = map[string]string{"details": string(details)}
+		}
+	}
+
+	st, detailErr := status.New(code, domainErr.Error()).WithDetails(info)
+	if detailErr != nil {
+		return status.Error(code, domainErr.Error())
+	}
+	return st.Err()
+}
+
+func errorCode(err error) string {
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"solid/internal/domain"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	RequestIDMetadata = "x-request-id"
+	ActorMetadata     = "x-actor"
+)
+
+func RecoveryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("panic recovered: %v", p)
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func RequestIDInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := firstValue(md, RequestIDMetadata)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID))
+
+	ctx = domain.WithRequestID(ctx, requestID)
+	if actor := firstValue(md, ActorMetadata); actor != "" {
+		ctx = domain.WithActor(ctx, actor)
+	}
+
+	return handler(ctx, req)
+}
+
+// TimeoutInterceptor bounds every unary call by timeout, keeping a shorter
+// deadline set by the client.
+func TimeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+func LoggerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	log.Printf(
+		"grpc %s %s %s request_id=%s",
+		info.FullMethod,
+		status.Code(err),
+		time.Since(start),
+		domain.RequestIDFromContext(ctx),
+	)
+	return resp, err
+}
+
+func StreamRecoveryInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("panic recovered: %v", p)
+			err = status.Error(codes.Internal, "internal server error")
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func StreamLoggerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+
+	log.Printf("grpc %s %s %s", info.FullMethod, status.Code(err), time.Since(start))
+	return err
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
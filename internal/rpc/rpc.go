@@ -0,0 +1,19 @@
+package rpc
+
+import (
+	bookv1 "solid/api/proto/book/v1"
+	"solid/internal/handler"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+func NewServer(bookServer *BookServer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(RecoveryInterceptor, RequestIDInterceptor, LoggerInterceptor, TimeoutInterceptor(handler.RequestTimeout)),
+		grpc.ChainStreamInterceptor(StreamRecoveryInterceptor, StreamLoggerInterceptor),
+	)
+	bookv1.RegisterBookServiceServer(server, bookServer)
+	reflection.Register(server)
+	return server
+}
@@ -0,0 +1,185 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	bookv1 "solid/api/proto/book/v1"
+	"solid/internal/domain"
+	"solid/internal/service"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type BookServer struct {
+	bookv1.UnimplementedBookServiceServer
+	service *service.BookService
+}
+
+func NewBookServer(service *service.BookService) *BookServer {
+	return &BookServer{service: service}
+}
+
+func (s *BookServer) CreateBook(ctx context.Context, req *bookv1.CreateBookRequest) (*bookv1.Book, error) {
+	book, _, err := s.service.CreateBook(ctx, req.GetTitle(), req.GetAuthor(), req.GetIsbn(), service.CreateBookOptions{Force: req.GetForce()})
+	if err != nil {
+		return nil, toCreateStatus(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *BookServer) GetBook(ctx context.Context, req *bookv1.GetBookRequest) (*bookv1.Book, error) {
+	book, err := s.service.GetBook(ctx, req.GetId())
+	if errors.Is(err, domain.ErrBookNotFound) {
+		if targetID, aliasErr := s.service.ResolveAlias(ctx, req.GetId()); aliasErr == nil {
+			book, err = s.service.GetBook(ctx, targetID)
+		}
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *BookServer) ListBooks(ctx context.Context, req *bookv1.ListBooksRequest) (*bookv1.ListBooksResponse, error) {
+	books, err := s.service.ListBooks(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &bookv1.ListBooksResponse{Books: toProtoBooks(books)}, nil
+}
+
+func (s *BookServer) UpdateBook(ctx context.Context, req *bookv1.UpdateBookRequest) (*bookv1.Book, error) {
+	book, err := s.service.UpdateBook(ctx, req.GetId(), req.GetTitle(), req.GetAuthor(), req.GetIsbn())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *BookServer) DeleteBook(ctx context.Context, req *bookv1.DeleteBookRequest) (*emptypb.Empty, error) {
+	if err := s.service.DeleteBook(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *BookServer) ExecuteBatch(ctx context.Context, req *bookv1.ExecuteBatchRequest) (*bookv1.ExecuteBatchResponse, error) {
+	ops := make([]service.BatchOperation, len(req.GetOperations()))
+	for i, op := range req.GetOperations() {
+		ops[i] = service.BatchOperation{
+			Op:     op.GetOp(),
+			ID:     op.GetId(),
+			Title:  op.GetTitle(),
+			Author: op.GetAuthor(),
+			ISBN:   op.GetIsbn(),
+			Force:  op.GetForce(),
+		}
+	}
+
+	results, err := s.service.ExecuteBatch(ctx, ops, req.GetAtomic())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bookv1.ExecuteBatchResponse{Results: make([]*bookv1.BatchResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = toProtoBatchResult(i, result)
+	}
+	return resp, nil
+}
+
+func (s *BookServer) SuggestBooks(ctx context.Context, req *bookv1.SuggestBooksRequest) (*bookv1.SuggestBooksResponse, error) {
+	suggestions, err := s.service.SuggestBooks(ctx, req.GetField(), req.GetPrefix(), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &bookv1.SuggestBooksResponse{Suggestions: toProtoSuggestions(suggestions)}, nil
+}
+
+func (s *BookServer) ListDuplicates(ctx context.Context, req *bookv1.ListDuplicatesRequest) (*bookv1.ListDuplicatesResponse, error) {
+	groups, err := s.service.DuplicateReport(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &bookv1.ListDuplicatesResponse{Groups: toProtoDuplicateGroups(groups)}, nil
+}
+
+func (s *BookServer) ListChanges(ctx context.Context, req *bookv1.ListChangesRequest) (*bookv1.ListChangesResponse, error) {
+	page, err := s.service.ListChanges(ctx, req.GetSince(), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	changes := make([]*bookv1.Change, len(page.Changes))
+	for i, change := range page.Changes {
+		changes[i] = toProtoChange(change)
+	}
+	return &bookv1.ListChangesResponse{Changes: changes, NextSince: page.NextSince, HasMore: page.HasMore}, nil
+}
+
+func (s *BookServer) MergeBooks(ctx context.Context, req *bookv1.MergeBooksRequest) (*bookv1.Book, error) {
+	book, err := s.service.MergeBooks(ctx, req.GetTargetId(), req.GetSourceId(), req.GetFields())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *BookServer) ListMerges(ctx context.Context, req *bookv1.ListMergesRequest) (*bookv1.ListMergesResponse, error) {
+	merges, err := s.service.ListMerges(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bookv1.ListMergesResponse{Merges: make([]*bookv1.Merge, len(merges))}
+	for i, merge := range merges {
+		resp.Merges[i] = toProtoMerge(merge)
+	}
+	return resp, nil
+}
+
+func (s *BookServer) ListTrash(ctx context.Context, req *bookv1.ListTrashRequest) (*bookv1.ListBooksResponse, error) {
+	books, err := s.service.ListTrash(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &bookv1.ListBooksResponse{Books: toProtoBooks(books)}, nil
+}
+
+func (s *BookServer) RestoreBook(ctx context.Context, req *bookv1.RestoreBookRequest) (*bookv1.Book, error) {
+	book, err := s.service.RestoreBook(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *BookServer) GetBookHistory(ctx context.Context, req *bookv1.GetBookHistoryRequest) (*bookv1.GetBookHistoryResponse, error) {
+	entries, err := s.service.BookHistory(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bookv1.GetBookHistoryResponse{Entries: make([]*bookv1.AuditEntry, len(entries))}
+	for i, entry := range entries {
+		resp.Entries[i] = toProtoAuditEntry(entry)
+	}
+	return resp, nil
+}
+
+func (s *BookServer) GetBookVersion(ctx context.Context, req *bookv1.GetBookVersionRequest) (*bookv1.AuditEntry, error) {
+	entry, err := s.service.BookVersion(ctx, req.GetId(), int(req.GetVersion()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoAuditEntry(entry), nil
+}
+
+func (s *BookServer) RevertBook(ctx context.Context, req *bookv1.RevertBookRequest) (*bookv1.Book, error) {
+	book, err := s.service.RevertBook(ctx, req.GetId(), int(req.GetVersion()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoBook(book), nil
+}
@@ -0,0 +1,170 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	bookv1 "solid/api/proto/book/v1"
+	"solid/internal/repository"
+	"solid/internal/rpc"
+	"solid/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T) bookv1.BookServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	bookService := service.NewBookService(
+		repository.NewInMemoryBookRepository(),
+		service.WithHistory(repository.NewInMemoryHistoryRepository()),
+	)
+	server := rpc.NewServer(rpc.NewBookServer(bookService))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error dialing: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return bookv1.NewBookServiceClient(conn)
+}
+
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestBookServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("create, get and update", func(t *testing.T) {
+		client := newClient(t)
+
+		created, err := client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "Clean Code", Author: "Robert Martin", Isbn: "0132350882"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := client.UpdateBook(ctx, &bookv1.UpdateBookRequest{Id: created.Id, Title: "Clean Coder"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		book, err := client.GetBook(ctx, &bookv1.GetBookRequest{Id: created.Id})
+		if err != nil || book.Title != "Clean Coder" || book.Author != "Robert Martin" {
+			t.Errorf("expected updated book, got %+v, %v", book, err)
+		}
+		history, _ := client.GetBookHistory(ctx, &bookv1.GetBookHistoryRequest{Id: created.Id})
+		if len(history.GetEntries()) != 2 {
+			t.Errorf("expected 2 history entries, got %d", len(history.GetEntries()))
+		}
+	})
+
+	t.Run("maps domain errors to status codes", func(t *testing.T) {
+		client := newClient(t)
+
+		_, err := client.GetBook(ctx, &bookv1.GetBookRequest{Id: "missing"})
+		if status.Code(err) != codes.NotFound || errorReason(err) != "BOOK_NOT_FOUND" {
+			t.Errorf("expected NotFound with BOOK_NOT_FOUND, got %v", err)
+		}
+
+		_, err = client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "", Author: "Robert Martin", Isbn: "0132350882"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument, got %v", err)
+		}
+
+		_, err = client.ExecuteBatch(ctx, &bookv1.ExecuteBatchRequest{})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for empty batch, got %v", err)
+		}
+	})
+
+	t.Run("duplicate warning carries details", func(t *testing.T) {
+		client := newClient(t)
+		client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "Clean Code", Author: "Robert Martin", Isbn: "0132350882"})
+
+		_, err := client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "Clean Code", Author: "Robert C. Martin", Isbn: "9780132350884"})
+
+		if status.Code(err) != codes.AlreadyExists || errorReason(err) != "POSSIBLE_DUPLICATE" {
+			t.Errorf("expected AlreadyExists with POSSIBLE_DUPLICATE, got %v", err)
+		}
+	})
+
+	t.Run("only a duplicate create is AlreadyExists", func(t *testing.T) {
+		client := newClient(t)
+		client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "Clean Code", Author: "Robert Martin", Isbn: "0132350882"})
+		other, _ := client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "Refactoring", Author: "Martin Fowler", Isbn: "0201485672"})
+
+		_, err := client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "Code Complete", Author: "Steve McConnell", Isbn: "0132350882"})
+		if status.Code(err) != codes.AlreadyExists || errorReason(err) != "BOOK_ALREADY_EXISTS" {
+			t.Errorf("expected AlreadyExists with BOOK_ALREADY_EXISTS, got %v", err)
+		}
+
+		_, err = client.UpdateBook(ctx, &bookv1.UpdateBookRequest{Id: other.Id, Isbn: "0132350882"})
+		if status.Code(err) != codes.FailedPrecondition || errorReason(err) != "BOOK_ALREADY_EXISTS" {
+			t.Errorf("expected FailedPrecondition with BOOK_ALREADY_EXISTS, got %v", err)
+		}
+	})
+
+	t.Run("echoes request id", func(t *testing.T) {
+		client := newClient(t)
+		var header metadata.MD
+
+		client.ListBooks(
+			metadata.AppendToOutgoingContext(ctx, rpc.RequestIDMetadata, "req-123"),
+			&bookv1.ListBooksRequest{},
+			grpc.Header(&header),
+		)
+
+		if got := header.Get(rpc.RequestIDMetadata); len(got) != 1 || got[0] != "req-123" {
+			t.Errorf("expected request id header, got %v", got)
+		}
+	})
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	interceptor := rpc.TimeoutInterceptor(time.Second)
+	deadline := func(ctx context.Context, req any) (any, error) {
+		deadline, _ := ctx.Deadline()
+		return deadline, nil
+	}
+
+	t.Run("applies the timeout", func(t *testing.T) {
+		resp, _ := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, deadline)
+
+		if until := time.Until(resp.(time.Time)); until <= 0 || until > time.Second {
+			t.Errorf("expected a deadline within 1s, got %s", until)
+		}
+	})
+
+	t.Run("keeps a shorter client deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		want, _ := ctx.Deadline()
+
+		resp, _ := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, deadline)
+
+		if !resp.(time.Time).Equal(want) {
+			t.Errorf("expected the client deadline %s, got %s", want, resp)
+		}
+	})
+}
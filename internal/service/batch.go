@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"solid/internal/domain"
+)
+
+const MaxBatchSize = 100
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+type BatchOperation struct {
+	Op     string
+	ID     string
+	Title  string
+	Author string
+	ISBN   string
+	Force  bool
+}
+
+type BatchResult struct {
+	Book *domain.Book
+	Err  error
+}
+
+func (s *BookService) ExecuteBatch(ctx context.Context, ops []BatchOperation, atomic bool) ([]BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, domain.ErrInvalidInput.WithMessage("operations cannot be empty")
+	}
+	if len(ops) > MaxBatchSize {
+		return nil, domain.ErrInvalidInput.WithMessage(fmt.Sprintf("batch exceeds maximum of %d operations", MaxBatchSize))
+	}
+	if atomic {
+		return s.executeAtomicBatch(ctx, ops)
+	}
+
+	results := make([]BatchResult, len(ops))
+	for i, op := range ops {
+		if err := ctx.Err(); err != nil {
+			results[i] = BatchResult{Err: err}
+			continue
+		}
+		results[i] = s.executeOperation(ctx, op)
+	}
+	return results, nil
+}
+
+// executeAtomicBatch checks the creates for duplicates before opening the
+// transaction, like CreateBook, so the scan never runs under its lock. They
+// are checked against the catalog only, not against each other.
+func (s *BookService) executeAtomicBatch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	if !s.supportsTx() {
+		return nil, domain.ErrNotSupported.WithMessage("atomic batches require repository transaction support")
+	}
+
+	results := make([]BatchResult, len(ops))
+	prepared := make([]*domain.Book, len(ops))
+	for i, op := range ops {
+		if op.Op != BatchCreate {
+			continue
+		}
+		book, _, err := s.prepareBook(ctx, op.Title, op.Author, op.ISBN, CreateBookOptions{Force: op.Force})
+		if err != nil {
+			return abortBatch(results, i, err), nil
+		}
+		prepared[i] = book
+	}
+
+	failed := -1
+	_, err := withinTx(ctx, s, func(ctx context.Context) (struct{}, error) {
+		for i, op := range ops {
+			if err := ctx.Err(); err != nil {
+				results[i] = BatchResult{Err: err}
+				failed = i
+				return struct{}{}, err
+			}
+			if prepared[i] != nil {
+				results[i] = BatchResult{Book: prepared[i], Err: s.insertBook(ctx, prepared[i])}
+			} else {
+				results[i] = s.executeOperation(ctx, op)
+			}
+			if results[i].Err != nil {
+				failed = i
+				return struct{}{}, results[i].Err
+			}
+		}
+		return struct{}{}, nil
+	})
+	if err == nil {
+		return results, nil
+	}
+	if failed < 0 {
+		return nil, err
+	}
+	return abortBatch(results, failed, results[failed].Err), nil
+}
+
+// abortBatch reports err for the failed operation and ErrBatchAborted for
+// every other one.
+func abortBatch(results []BatchResult, failed int, err error) []BatchResult {
+	for i := range results {
+		results[i] = BatchResult{Err: domain.ErrBatchAborted}
+	}
+	results[failed] = BatchResult{Err: err}
+	return results
+}
+
+func (s *BookService) executeOperation(ctx context.Context, op BatchOperation) BatchResult {
+	switch op.Op {
+	case BatchCreate:
+		book, _, err := s.CreateBook(ctx, op.Title, op.Author, op.ISBN, CreateBookOptions{Force: op.Force})
+		return BatchResult{Book: book, Err: err}
+	case BatchUpdate:
+		book, err := s.UpdateBook(ctx, op.ID, op.Title, op.Author, op.ISBN)
+		return BatchResult{Book: book, Err: err}
+	case BatchDelete:
+		return BatchResult{Err: s.DeleteBook(ctx, op.ID)}
+	default:
+		return BatchResult{Err: domain.ErrInvalidInput.WithMessage("unknown operation: " + op.Op)}
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+)
+
+func TestBookService_ExecuteBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("independent operations", func(t *testing.T) {
+		repo := &mocks.BookRepository{
+			CreateFunc: func(ctx context.Context, book *domain.Book) error {
+				book.ID = "new-id"
+				return nil
+			},
+			DeleteFunc: func(ctx context.Context, id string) error {
+				return domain.ErrBookNotFound
+			},
+		}
+		service := NewBookService(repo)
+
+		results, err := service.ExecuteBatch(ctx, []BatchOperation{
+			{Op: BatchCreate, Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"},
+			{Op: BatchDelete, ID: "missing"},
+			{Op: "rename", ID: "new-id"},
+		}, false)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("expected 3 results, got %d", len(results))
+		}
+		if results[0].Err != nil || results[0].Book.ID != "new-id" {
+			t.Errorf("expected create to succeed, got %+v", results[0])
+		}
+		if results[1].Err != domain.ErrBookNotFound {
+			t.Errorf("expected ErrBookNotFound, got %v", results[1].Err)
+		}
+		if domain.GetStatusCode(results[2].Err) != 400 {
+			t.Errorf("expected invalid input for unknown op, got %v", results[2].Err)
+		}
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{})
+
+		_, err := service.ExecuteBatch(ctx, nil, false)
+
+		if domain.GetStatusCode(err) != 400 {
+			t.Errorf("expected invalid input error, got %v", err)
+		}
+	})
+
+	t.Run("too many operations", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{})
+
+		_, err := service.ExecuteBatch(ctx, make([]BatchOperation, MaxBatchSize+1), false)
+
+		if domain.GetStatusCode(err) != 400 {
+			t.Errorf("expected invalid input error, got %v", err)
+		}
+	})
+
+	t.Run("atomic requires transactions", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{})
+
+		_, err := service.ExecuteBatch(ctx, []BatchOperation{{Op: BatchDelete, ID: "x"}}, true)
+
+		if domain.GetStatusCode(err) != 501 {
+			t.Errorf("expected not supported error, got %v", err)
+		}
+	})
+	t.Run("atomic rolls back on failure", func(t *testing.T) {
+		repo := &mocks.TransactionalBookRepository{BookRepository: mocks.BookRepository{
+			CreateFunc: func(ctx context.Context, book *domain.Book) error {
+				book.ID = "new-id"
+				return nil
+			},
+			DeleteFunc: func(ctx context.Context, id string) error {
+				return domain.ErrBookNotFound
+			},
+		}}
+		recorder := &mocks.EventRecorder{}
+		service := NewBookService(repo, WithPublisher(recorder))
+
+		results, err := service.ExecuteBatch(ctx, []BatchOperation{
+			{Op: BatchCreate, Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"},
+			{Op: BatchDelete, ID: "missing"},
+			{Op: BatchCreate, Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"},
+		}, true)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.Rollbacks != 1 || repo.Commits != 0 {
+			t.Errorf("expected a single rollback, got %d commits and %d rollbacks", repo.Commits, repo.Rollbacks)
+		}
+		if results[1].Err != domain.ErrBookNotFound {
+			t.Errorf("expected failing operation to report its error, got %v", results[1].Err)
+		}
+		for _, i := range []int{0, 2} {
+			if results[i].Err != domain.ErrBatchAborted {
+				t.Errorf("expected operation %d to be aborted, got %v", i, results[i].Err)
+			}
+		}
+		recorder.AssertEmitted(t)
+	})
+
+	t.Run("atomic commits once", func(t *testing.T) {
+		repo := &mocks.TransactionalBookRepository{BookRepository: mocks.BookRepository{
+			CreateFunc: func(ctx context.Context, book *domain.Book) error {
+				book.ID = "new-id"
+				return nil
+			},
+		}}
+		recorder := &mocks.EventRecorder{}
+		service := NewBookService(repo, WithPublisher(recorder))
+
+		results, err := service.ExecuteBatch(ctx, []BatchOperation{
+			{Op: BatchCreate, Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"},
+			{Op: BatchDelete, ID: "new-id"},
+		}, true)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.Commits != 1 || repo.Rollbacks != 0 {
+			t.Errorf("expected a single commit, got %d commits and %d rollbacks", repo.Commits, repo.Rollbacks)
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				t.Errorf("expected operation %d to succeed, got %v", i, result.Err)
+			}
+		}
+		recorder.AssertEmitted(t, domain.EventBookCreated, domain.EventBookDeleted)
+	})
+}
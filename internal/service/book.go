@@ -3,65 +3,225 @@ package service
 import (
 	"context"
 	"solid/internal/domain"
+	"solid/internal/suggest"
 	"time"
 )
 
 type BookService struct {
-	repository domain.BookRepository
+	repository  domain.BookRepository
+	history     domain.HistoryRepository
+	publisher   domain.EventPublisher
+	changes     domain.ChangeLog
+	suggestions *suggest.Index
 }
 
-func NewBookService(repository domain.BookRepository) *BookService {
-	return &BookService{
-		repository: repository,
+func NewBookService(repository domain.BookRepository, opts ...Option) *BookService {
+	s := &BookService{
+		repository:  repository,
+		suggestions: suggest.NewIndex(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateBookOptions tune how CreateBook treats a new book.
+type CreateBookOptions struct {
+	// Force creates the book even when it looks like a duplicate of an
+	// existing one.
+	Force bool
 }
 
-func (s *BookService) CreateBook(ctx context.Context, title, author, isbn string) (*domain.Book, error) {
+// CreateBook creates a book, rejecting likely duplicates with
+// ErrPossibleDuplicate unless opts.Force is set. A forced create returns
+// the duplicate candidates it let through. The fuzzy duplicate scan runs
+// before the transaction, so it never holds the repository's write lock;
+// inside it the repository only enforces exact ISBN conflicts.
+func (s *BookService) CreateBook(ctx context.Context, title, author, isbn string, opts CreateBookOptions) (*domain.Book, []DuplicateCandidate, error) {
+	book, candidates, err := s.prepareBook(ctx, title, author, isbn, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = withinTx(ctx, s, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.insertBook(ctx, book)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return book, candidates, nil
+}
+
+// prepareBook validates a new book and scans the catalog for duplicates of
+// it, without writing anything.
+func (s *BookService) prepareBook(ctx context.Context, title, author, isbn string, opts CreateBookOptions) (*domain.Book, []DuplicateCandidate, error) {
 	book, err := domain.NewBook(title, author, isbn)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	candidates, err := s.FindDuplicates(ctx, book.Title, book.Author, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(candidates) > 0 && !opts.Force {
+		return nil, nil, domain.ErrPossibleDuplicate.WithDetails(candidates)
+	}
+	return book, candidates, nil
+}
+
+func (s *BookService) insertBook(ctx context.Context, book *domain.Book) error {
 	if err := s.repository.Create(ctx, book); err != nil {
-		return nil, err
+		return err
+	}
+	if err := s.record(ctx, domain.ActionCreated, nil, book); err != nil {
+		return err
 	}
 
-	return book, nil
+	s.index(ctx, book)
+	s.publish(ctx, domain.BookCreated{Book: *book, At: book.CreatedAt})
+	return nil
 }
 
 func (s *BookService) GetBook(ctx context.Context, id string) (*domain.Book, error) {
 	return s.repository.FindByID(ctx, id)
 }
 
+func (s *BookService) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	return s.repository.FindByISBN(ctx, domain.NormalizeISBN(isbn))
+}
+
 func (s *BookService) ListBooks(ctx context.Context) ([]*domain.Book, error) {
 	return s.repository.FindAll(ctx)
 }
 
+// FindBooks returns the books that match filter, or every book when filter
+// is nil. Repositories that cannot filter are filtered in memory.
+func (s *BookService) FindBooks(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error) {
+	if filter == nil {
+		return s.repository.FindAll(ctx)
+	}
+	if repo, ok := s.repository.(domain.FilterableRepository); ok {
+		return repo.FindMatching(ctx, filter)
+	}
+
+	books, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matching := make([]*domain.Book, 0, len(books))
+	for _, book := range books {
+		if filter.Match(book) {
+			matching = append(matching, book)
+		}
+	}
+	return matching, nil
+}
+
 func (s *BookService) UpdateBook(ctx context.Context, id, title, author, isbn string) (*domain.Book, error) {
+	return withinTx(ctx, s, func(ctx context.Context) (*domain.Book, error) {
+		return s.updateBook(ctx, domain.ActionUpdated, id, title, author, isbn)
+	})
+}
+
+func (s *BookService) updateBook(ctx context.Context, action, id, title, author, isbn string) (*domain.Book, error) {
 	book, err := s.repository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if title != "" {
-		book.Title = title
-	}
-	if author != "" {
-		book.Author = author
-	}
-	if isbn != "" {
-		book.ISBN = isbn
+	before := *book
+	if err := book.Update(title, author, isbn); err != nil {
+		return nil, err
 	}
 
-	book.UpdatedAt = time.Now()
-
 	if err := s.repository.Update(ctx, book); err != nil {
 		return nil, err
 	}
+	if err := s.record(ctx, action, &before, book); err != nil {
+		return nil, err
+	}
 
+	s.index(ctx, book)
+	s.publish(ctx, domain.BookUpdated{Book: *book, Changes: domain.Diff(&before, book), At: book.UpdatedAt})
 	return book, nil
 }
 
 func (s *BookService) DeleteBook(ctx context.Context, id string) error {
-	return s.repository.Delete(ctx, id)
+	_, err := withinTx(ctx, s, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.deleteBook(ctx, domain.ActionDeleted, id)
+	})
+	return err
+}
+
+func (s *BookService) deleteBook(ctx context.Context, action, id string) error {
+	var before *domain.Book
+	if s.history != nil {
+		book, err := s.repository.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		before = book
+	}
+
+	if err := s.repository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if before != nil {
+		after := *before
+		deletedAt := time.Now()
+		after.DeletedAt = &deletedAt
+		if err := s.record(ctx, action, before, &after); err != nil {
+			return err
+		}
+	}
+
+	s.unindex(ctx, id)
+	s.publish(ctx, domain.BookDeleted{BookID: id, At: time.Now()})
+	return nil
+}
+
+func (s *BookService) publish(ctx context.Context, event domain.Event) {
+	if s.publisher == nil {
+		return
+	}
+	s.afterCommit(ctx, func(ctx context.Context) error {
+		s.publisher.Publish(ctx, event)
+		return nil
+	})
+}
+
+func (s *BookService) index(ctx context.Context, book *domain.Book) {
+	s.afterCommit(ctx, func(ctx context.Context) error {
+		s.suggestions.Put(book)
+		return nil
+	})
+}
+
+func (s *BookService) unindex(ctx context.Context, id string) {
+	s.afterCommit(ctx, func(ctx context.Context) error {
+		s.suggestions.Remove(id)
+		return nil
+	})
+}
+
+// RebuildSuggestions reloads the suggestion index from the repository.
+// Suggestions are served from the previous contents until it finishes, and
+// writes made meanwhile are kept, so it can run in the background.
+func (s *BookService) RebuildSuggestions(ctx context.Context) error {
+	return s.suggestions.Rebuild(func() ([]*domain.Book, error) {
+		return s.repository.FindAll(ctx)
+	})
+}
+
+func (s *BookService) SuggestBooks(ctx context.Context, field, prefix string, limit int) ([]suggest.Suggestion, error) {
+	f, ok := suggest.ParseField(field)
+	if !ok {
+		return nil, domain.ErrInvalidInput.WithMessage("field must be title or author")
+	}
+	if len(prefix) > domain.MaxTitleLength {
+		return nil, domain.ErrInvalidInput.WithMessage("prefix exceeds maximum length")
+	}
+
+	return s.suggestions.Suggest(f, prefix, limit), nil
 }
@@ -20,7 +20,7 @@ func TestBookService_CreateBook(t *testing.T) {
 		}
 		service := NewBookService(repo)
 
-		book, err := service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882")
+		book, _, err := service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882", CreateBookOptions{})
 
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
@@ -34,7 +34,7 @@ func TestBookService_CreateBook(t *testing.T) {
 		repo := &mocks.BookRepository{}
 		service := NewBookService(repo)
 
-		_, err := service.CreateBook(ctx, "", "Robert Martin", "0132350882")
+		_, _, err := service.CreateBook(ctx, "", "Robert Martin", "0132350882", CreateBookOptions{})
 
 		if err == nil {
 			t.Error("expected validation error")
@@ -50,7 +50,7 @@ func TestBookService_CreateBook(t *testing.T) {
 		}
 		service := NewBookService(repo)
 
-		_, err := service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882")
+		_, _, err := service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882", CreateBookOptions{})
 
 		if err != repoErr {
 			t.Errorf("expected repository error, got %v", err)
@@ -198,3 +198,62 @@ func TestBookService_DeleteBook(t *testing.T) {
 		}
 	})
 }
+
+func TestBookService_SuggestBooks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reflects writes", func(t *testing.T) {
+		repo := &mocks.BookRepository{
+			CreateFunc: func(ctx context.Context, book *domain.Book) error {
+				book.ID = "test-id"
+				return nil
+			},
+		}
+		service := NewBookService(repo)
+		service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882", CreateBookOptions{})
+
+		suggestions, err := service.SuggestBooks(ctx, "author", "rob", 5)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(suggestions) != 1 || suggestions[0].BookID != "test-id" {
+			t.Errorf("expected suggestion for test-id, got %+v", suggestions)
+		}
+
+		service.DeleteBook(ctx, "test-id")
+
+		suggestions, _ = service.SuggestBooks(ctx, "author", "rob", 5)
+		if len(suggestions) != 0 {
+			t.Errorf("expected no suggestions after delete, got %+v", suggestions)
+		}
+	})
+
+	t.Run("rebuilds from repository", func(t *testing.T) {
+		repo := &mocks.BookRepository{
+			FindAllFunc: func(ctx context.Context) ([]*domain.Book, error) {
+				return []*domain.Book{{ID: "stored-id", Title: "Refactoring", Author: "Martin Fowler"}}, nil
+			},
+		}
+		service := NewBookService(repo)
+
+		if err := service.RebuildSuggestions(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		suggestions, _ := service.SuggestBooks(ctx, "title", "ref", 5)
+		if len(suggestions) != 1 || suggestions[0].BookID != "stored-id" {
+			t.Errorf("expected suggestion for stored-id, got %+v", suggestions)
+		}
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{})
+
+		_, err := service.SuggestBooks(ctx, "isbn", "978", 5)
+
+		if domain.GetStatusCode(err) != 400 {
+			t.Errorf("expected invalid input error, got %v", err)
+		}
+	})
+}
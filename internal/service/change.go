@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"solid/internal/domain"
+)
+
+const (
+	DefaultChangeLimit = 100
+	MaxChangeLimit     = 1000
+)
+
+func (s *BookService) ListChanges(ctx context.Context, since uint64, limit int) (*domain.ChangePage, error) {
+	if s.changes == nil {
+		return nil, domain.ErrNotSupported.WithMessage("change feed is not available")
+	}
+	if limit <= 0 {
+		limit = DefaultChangeLimit
+	}
+	if limit > MaxChangeLimit {
+		limit = MaxChangeLimit
+	}
+
+	changes, err := s.changes.Changes(ctx, since, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ChangePage{Changes: changes, NextSince: since}
+	if len(changes) > limit {
+		page.Changes = changes[:limit]
+		page.HasMore = true
+	}
+	if len(page.Changes) > 0 {
+		page.NextSince = page.Changes[len(page.Changes)-1].Sequence
+	}
+	return page, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+)
+
+func TestBookService_ListChanges(t *testing.T) {
+	ctx := context.Background()
+	changeLog := &mocks.ChangeLog{
+		ChangesFunc: func(ctx context.Context, since uint64, limit int) ([]*domain.Change, error) {
+			changes := make([]*domain.Change, 0)
+			for seq := since + 1; seq <= 5 && len(changes) < limit; seq++ {
+				changes = append(changes, &domain.Change{Sequence: seq})
+			}
+			return changes, nil
+		},
+	}
+
+	t.Run("paginates", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{}, WithChangeLog(changeLog))
+
+		page, err := service.ListChanges(ctx, 0, 2)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Changes) != 2 || !page.HasMore || page.NextSince != 2 {
+			t.Errorf("unexpected page %+v", page)
+		}
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{}, WithChangeLog(changeLog))
+
+		page, _ := service.ListChanges(ctx, 4, 2)
+
+		if len(page.Changes) != 1 || page.HasMore || page.NextSince != 5 {
+			t.Errorf("unexpected page %+v", page)
+		}
+	})
+
+	t.Run("caught up keeps cursor", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{}, WithChangeLog(changeLog))
+
+		page, _ := service.ListChanges(ctx, 5, 0)
+
+		if len(page.Changes) != 0 || page.NextSince != 5 {
+			t.Errorf("unexpected page %+v", page)
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		service := NewBookService(&mocks.BookRepository{})
+
+		_, err := service.ListChanges(ctx, 0, 0)
+
+		if domain.GetStatusCode(err) != 501 {
+			t.Errorf("expected not supported error, got %v", err)
+		}
+	})
+}
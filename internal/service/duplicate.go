@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"solid/internal/domain"
+)
+
+const duplicateThreshold = 0.85
+
+var leadingArticles = map[string]bool{"the": true, "a": true, "an": true}
+
+type DuplicateCandidate struct {
+	BookID string  `json:"book_id"`
+	Title  string  `json:"title"`
+	Author string  `json:"author"`
+	Score  float64 `json:"score"`
+}
+
+type DuplicateGroup struct {
+	Books []DuplicateCandidate `json:"books"`
+}
+
+func (s *BookService) FindDuplicates(ctx context.Context, title, author, excludeID string) ([]DuplicateCandidate, error) {
+	books, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]fingerprint, len(books))
+	for i, book := range books {
+		fingerprints[i] = newFingerprint(book.Title, book.Author)
+	}
+	return candidatesFor(newFingerprint(title, author), excludeID, books, fingerprints), nil
+}
+
+// FindDuplicatesOf finds the duplicate candidates of each of books, keyed by
+// book ID, loading and fingerprinting the catalog once for all of them.
+func (s *BookService) FindDuplicatesOf(ctx context.Context, books []*domain.Book) (map[string][]DuplicateCandidate, error) {
+	catalog, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]fingerprint, len(catalog))
+	byID := make(map[string]fingerprint, len(catalog))
+	for i, book := range catalog {
+		fingerprints[i] = newFingerprint(book.Title, book.Author)
+		byID[book.ID] = fingerprints[i]
+	}
+
+	duplicates := make(map[string][]DuplicateCandidate, len(books))
+	for _, book := range books {
+		target, ok := byID[book.ID]
+		if !ok {
+			target = newFingerprint(book.Title, book.Author)
+		}
+		duplicates[book.ID] = candidatesFor(target, book.ID, catalog, fingerprints)
+	}
+	return duplicates, nil
+}
+
+func candidatesFor(target fingerprint, excludeID string, books []*domain.Book, fingerprints []fingerprint) []DuplicateCandidate {
+	candidates := make([]DuplicateCandidate, 0)
+	for i, book := range books {
+		if book.ID == excludeID {
+			continue
+		}
+		if score := target.similarity(fingerprints[i]); score >= duplicateThreshold {
+			candidates = append(candidates, toCandidate(book, score))
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// DuplicateReport groups likely duplicates across the catalog. Only books
+// that share a blocking key are compared, so pairs differing both in the
+// start of the title and in the author's last name are not reported.
+func (s *BookService) DuplicateReport(ctx context.Context) ([]DuplicateGroup, error) {
+	books, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		return books[i].CreatedAt.Before(books[j].CreatedAt)
+	})
+
+	fingerprints := make([]fingerprint, len(books))
+	blocks := make(map[string][]int)
+	for i, book := range books {
+		fingerprints[i] = newFingerprint(book.Title, book.Author)
+		for _, key := range fingerprints[i].blockingKeys() {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	grouped := make([]bool, len(books))
+	compared := make([]int, len(books))
+	groups := make([]DuplicateGroup, 0)
+	for i := range books {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if grouped[i] {
+			continue
+		}
+		matches := make(map[int]float64)
+		for _, key := range fingerprints[i].blockingKeys() {
+			for _, j := range blocks[key] {
+				if j <= i || grouped[j] || compared[j] == i+1 {
+					continue
+				}
+				compared[j] = i + 1
+				if score := fingerprints[i].similarity(fingerprints[j]); score >= duplicateThreshold {
+					matches[j] = score
+				}
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		group := DuplicateGroup{Books: []DuplicateCandidate{toCandidate(books[i], 1)}}
+		for _, j := range slices.Sorted(maps.Keys(matches)) {
+			group.Books = append(group.Books, toCandidate(books[j], matches[j]))
+			grouped[j] = true
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func toCandidate(book *domain.Book, score float64) DuplicateCandidate {
+	return DuplicateCandidate{
+		BookID: book.ID,
+		Title:  book.Title,
+		Author: book.Author,
+		Score:  score,
+	}
+}
+
+type fingerprint struct {
+	title  []rune
+	author []rune
+}
+
+func newFingerprint(title, author string) fingerprint {
+	return fingerprint{
+		title:  []rune(normalizeForMatch(title, true)),
+		author: []rune(normalizeForMatch(author, false)),
+	}
+}
+
+const blockingPrefix = 4
+
+// blockingKeys returns the keys a fingerprint is compared under: the first
+// runes of its title and its author's last name.
+func (f fingerprint) blockingKeys() []string {
+	keys := make([]string, 0, 2)
+	if len(f.title) > 0 {
+		keys = append(keys, "t:"+string(f.title[:min(len(f.title), blockingPrefix)]))
+	}
+	if author := strings.Fields(string(f.author)); len(author) > 0 {
+		keys = append(keys, "a:"+author[len(author)-1])
+	}
+	return keys
+}
+
+func (f fingerprint) similarity(other fingerprint) float64 {
+	return 0.6*ratio(f.title, other.title) + 0.4*ratio(f.author, other.author)
+}
+
+func normalizeForMatch(s string, stripArticle bool) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if stripArticle && len(words) > 1 && leadingArticles[words[0]] {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func ratio(a, b []rune) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBookService_CreateBook_Duplicates(t *testing.T) {
+	ctx := context.Background()
+	existing := &domain.Book{
+		ID:     "existing-id",
+		Title:  "Clean Code",
+		Author: "Robert C. Martin",
+		ISBN:   "0132350882",
+	}
+	newRepo := func() *mocks.BookRepository {
+		return &mocks.BookRepository{
+			FindAllFunc: func(ctx context.Context) ([]*domain.Book, error) {
+				return []*domain.Book{existing}, nil
+			},
+		}
+	}
+
+	t.Run("rejects near duplicate", func(t *testing.T) {
+		service := NewBookService(newRepo())
+
+		_, _, err := service.CreateBook(ctx, "The Clean Code", "Robert C Martin", "9780132350884", CreateBookOptions{})
+
+		var domainErr *domain.DomainError
+		if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrPossibleDuplicate.Code {
+			t.Fatalf("expected ErrPossibleDuplicate, got %v", err)
+		}
+		candidates, ok := domainErr.Details.([]DuplicateCandidate)
+		if !ok || len(candidates) != 1 || candidates[0].BookID != "existing-id" {
+			t.Errorf("expected existing-id as candidate, got %+v", domainErr.Details)
+		}
+	})
+
+	t.Run("force overrides", func(t *testing.T) {
+		repo := newRepo()
+		scans := 0
+		repo.FindAllFunc = func(ctx context.Context) ([]*domain.Book, error) {
+			scans++
+			return []*domain.Book{existing}, nil
+		}
+		service := NewBookService(repo)
+
+		_, candidates, err := service.CreateBook(ctx, "Clean Code", "Robert Martin", "9780132350884", CreateBookOptions{Force: true})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(candidates) != 1 || candidates[0].BookID != "existing-id" || scans != 1 {
+			t.Errorf("expected existing-id from a single scan, got %+v after %d scans", candidates, scans)
+		}
+	})
+
+	t.Run("scans outside the transaction", func(t *testing.T) {
+		repo := &txProbe{}
+		repo.FindAllFunc = func(ctx context.Context) ([]*domain.Book, error) {
+			if repo.inTx {
+				t.Error("expected the duplicate scan to run before the transaction")
+			}
+			return []*domain.Book{existing}, nil
+		}
+		service := NewBookService(repo)
+
+		_, _, err := service.CreateBook(ctx, "Refactoring", "Martin Fowler", "9780134757599", CreateBookOptions{})
+
+		if err != nil || repo.Commits != 1 {
+			t.Errorf("expected 1 commit, got %d (%v)", repo.Commits, err)
+		}
+	})
+
+	t.Run("distinct book passes", func(t *testing.T) {
+		service := NewBookService(newRepo())
+
+		_, _, err := service.CreateBook(ctx, "Refactoring", "Martin Fowler", "9780134757599", CreateBookOptions{})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+// txProbe reports whether a call happens inside WithinTx.
+type txProbe struct {
+	mocks.TransactionalBookRepository
+	inTx bool
+}
+
+func (p *txProbe) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	p.inTx = true
+	defer func() { p.inTx = false }()
+	return p.TransactionalBookRepository.WithinTx(ctx, fn)
+}
+
+func TestBookService_DuplicateReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	books := []*domain.Book{
+		{ID: "1", Title: "Clean Code", Author: "Robert Martin", CreatedAt: now},
+		{ID: "2", Title: "Refactoring", Author: "Martin Fowler", CreatedAt: now.Add(time.Second)},
+		{ID: "3", Title: "Clean Code!", Author: "Robert  Martin", CreatedAt: now.Add(2 * time.Second)},
+		{ID: "4", Title: "Claen Code", Author: "Robert Martin", CreatedAt: now.Add(3 * time.Second)},
+	}
+	repo := &mocks.BookRepository{
+		FindAllFunc: func(ctx context.Context) ([]*domain.Book, error) {
+			return books, nil
+		},
+	}
+	service := NewBookService(repo)
+
+	groups, err := service.DuplicateReport(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(groups))
+	}
+	if ids := groupIDs(groups[0]); ids != "1,3,4" {
+		t.Errorf("expected books 1,3,4 in creation order, got %s", ids)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := service.DuplicateReport(cancelled); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func groupIDs(group DuplicateGroup) string {
+	ids := make([]string, 0, len(group.Books))
+	for _, book := range group.Books {
+		ids = append(ids, book.BookID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"clean code", "claen code", 2},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBookService_FindDuplicatesOf(t *testing.T) {
+	ctx := context.Background()
+	books := []*domain.Book{
+		{ID: "a", Title: "Clean Code", Author: "Robert C. Martin"},
+		{ID: "b", Title: "The Clean Code", Author: "Robert Martin"},
+		{ID: "c", Title: "Refactoring", Author: "Martin Fowler"},
+	}
+	scans := 0
+	service := NewBookService(&mocks.BookRepository{
+		FindAllFunc: func(ctx context.Context) ([]*domain.Book, error) {
+			scans++
+			return books, nil
+		},
+	})
+
+	duplicates, err := service.FindDuplicatesOf(ctx, books)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scans != 1 {
+		t.Errorf("expected one catalog scan, got %d", scans)
+	}
+	if len(duplicates["a"]) != 1 || duplicates["a"][0].BookID != "b" || len(duplicates["b"]) != 1 || duplicates["b"][0].BookID != "a" {
+		t.Errorf("expected a and b to be duplicates of each other, got %+v", duplicates)
+	}
+	if candidates, ok := duplicates["c"]; !ok || len(candidates) != 0 {
+		t.Errorf("expected no candidates for c, got %+v", candidates)
+	}
+}
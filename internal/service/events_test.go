@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+)
+
+func TestBookService_Events(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("emits events after successful writes", func(t *testing.T) {
+		stored := &domain.Book{ID: "test-id", Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo := &mocks.BookRepository{
+			CreateFunc: func(ctx context.Context, book *domain.Book) error {
+				book.ID = "test-id"
+				return nil
+			},
+			FindByIDFunc: func(ctx context.Context, id string) (*domain.Book, error) {
+				book := *stored
+				return &book, nil
+			},
+		}
+		recorder := &mocks.EventRecorder{}
+		service := NewBookService(repo, WithPublisher(recorder))
+
+		service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882", CreateBookOptions{})
+		service.UpdateBook(ctx, "test-id", "", "Uncle Bob", "")
+		service.DeleteBook(ctx, "test-id")
+
+		recorder.AssertEmitted(t, domain.EventBookCreated, domain.EventBookUpdated, domain.EventBookDeleted)
+		updated := recorder.Events()[1].(domain.BookUpdated)
+		if len(updated.Changes) != 1 || updated.Changes[0].Field != "author" {
+			t.Errorf("expected author change, got %+v", updated.Changes)
+		}
+	})
+
+	t.Run("no event when the repository fails", func(t *testing.T) {
+		repo := &mocks.BookRepository{
+			CreateFunc: func(ctx context.Context, book *domain.Book) error {
+				return errors.New("database error")
+			},
+		}
+		recorder := &mocks.EventRecorder{}
+		service := NewBookService(repo, WithPublisher(recorder))
+
+		service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882", CreateBookOptions{})
+
+		recorder.AssertEmitted(t)
+	})
+}
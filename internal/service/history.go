@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"solid/internal/domain"
+)
+
+func (s *BookService) record(ctx context.Context, action string, before, after *domain.Book) error {
+	if s.history == nil {
+		return nil
+	}
+	return s.history.Append(ctx, domain.NewAuditEntry(ctx, action, before, after))
+}
+
+func (s *BookService) BookHistory(ctx context.Context, id string) ([]*domain.AuditEntry, error) {
+	if s.history == nil {
+		return nil, domain.ErrBookNotFound
+	}
+	return s.history.FindByBook(ctx, id)
+}
+
+func (s *BookService) BookVersion(ctx context.Context, id string, version int) (*domain.AuditEntry, error) {
+	if s.history == nil {
+		return nil, domain.ErrBookNotFound
+	}
+	return s.history.FindVersion(ctx, id, version)
+}
+
+func (s *BookService) RevertBook(ctx context.Context, id string, version int) (*domain.Book, error) {
+	entry, err := s.BookVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := entry.Snapshot
+	return withinTx(ctx, s, func(ctx context.Context) (*domain.Book, error) {
+		return s.updateBook(ctx, domain.ActionReverted, id, snapshot.Title, snapshot.Author, snapshot.ISBN)
+	})
+}
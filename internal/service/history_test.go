@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+)
+
+func TestBookService_History(t *testing.T) {
+	ctx := domain.WithRequestID(domain.WithActor(context.Background(), "alice"), "req-1")
+
+	newService := func(entries *[]*domain.AuditEntry) *BookService {
+		stored := &domain.Book{ID: "test-id", Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+		repo := &mocks.BookRepository{
+			CreateFunc: func(ctx context.Context, book *domain.Book) error {
+				book.ID = "test-id"
+				return nil
+			},
+			FindByIDFunc: func(ctx context.Context, id string) (*domain.Book, error) {
+				book := *stored
+				return &book, nil
+			},
+			UpdateFunc: func(ctx context.Context, book *domain.Book) error {
+				*stored = *book
+				return nil
+			},
+		}
+		history := &mocks.HistoryRepository{
+			AppendFunc: func(ctx context.Context, entry *domain.AuditEntry) error {
+				entry.Version = len(*entries) + 1
+				*entries = append(*entries, entry)
+				return nil
+			},
+			FindVersionFunc: func(ctx context.Context, bookID string, version int) (*domain.AuditEntry, error) {
+				if version > len(*entries) {
+					return nil, domain.ErrVersionNotFound
+				}
+				return (*entries)[version-1], nil
+			},
+		}
+		return NewBookService(repo, WithHistory(history))
+	}
+
+	t.Run("records create with actor and request ID", func(t *testing.T) {
+		var entries []*domain.AuditEntry
+		service := newService(&entries)
+
+		service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882", CreateBookOptions{})
+
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+		entry := entries[0]
+		if entry.Action != domain.ActionCreated || entry.Actor != "alice" || entry.RequestID != "req-1" {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		if len(entry.Changes) != 3 {
+			t.Errorf("expected 3 changed fields, got %+v", entry.Changes)
+		}
+	})
+
+	t.Run("records field level diff on update", func(t *testing.T) {
+		var entries []*domain.AuditEntry
+		service := newService(&entries)
+
+		service.UpdateBook(ctx, "test-id", "Clean Coder", "", "")
+
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+		changes := entries[0].Changes
+		if len(changes) != 1 || changes[0].Field != "title" || changes[0].From != "Clean Code" || changes[0].To != "Clean Coder" {
+			t.Errorf("unexpected changes %+v", changes)
+		}
+	})
+
+	t.Run("revert goes through update validation", func(t *testing.T) {
+		var entries []*domain.AuditEntry
+		service := newService(&entries)
+		service.UpdateBook(ctx, "test-id", "Clean Coder", "", "")
+		service.UpdateBook(ctx, "test-id", "The Clean Coder", "", "")
+
+		book, err := service.RevertBook(ctx, "test-id", 1)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if book.Title != "Clean Coder" {
+			t.Errorf("expected title Clean Coder, got %s", book.Title)
+		}
+		if last := entries[len(entries)-1]; last.Action != domain.ActionReverted {
+			t.Errorf("expected reverted entry, got %s", last.Action)
+		}
+	})
+
+	t.Run("revert to unknown version", func(t *testing.T) {
+		var entries []*domain.AuditEntry
+		service := newService(&entries)
+
+		_, err := service.RevertBook(ctx, "test-id", 5)
+
+		if err != domain.ErrVersionNotFound {
+			t.Errorf("expected ErrVersionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("failing history fails the change", func(t *testing.T) {
+		failure := errors.New("disk full")
+		service := NewBookService(&mocks.BookRepository{}, WithHistory(&mocks.HistoryRepository{
+			AppendFunc: func(ctx context.Context, entry *domain.AuditEntry) error {
+				return failure
+			},
+		}))
+
+		_, _, err := service.CreateBook(ctx, "Clean Code", "Robert Martin", "0132350882", CreateBookOptions{})
+
+		if !errors.Is(err, failure) {
+			t.Errorf("expected the history error, got %v", err)
+		}
+	})
+}
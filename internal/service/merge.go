@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"solid/internal/domain"
+)
+
+func (s *BookService) MergeBooks(ctx context.Context, targetID, sourceID string, fields map[string]string) (*domain.Book, error) {
+	return withinTx(ctx, s, func(ctx context.Context) (*domain.Book, error) {
+		return s.mergeBooks(ctx, targetID, sourceID, fields)
+	})
+}
+
+func (s *BookService) mergeBooks(ctx context.Context, targetID, sourceID string, fields map[string]string) (*domain.Book, error) {
+	merge, err := domain.NewMerge(sourceID, targetID, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.repository.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.repository.FindByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *target
+	merge.Apply(target, source)
+
+	if err := s.repository.Delete(ctx, source.ID); err != nil {
+		return nil, err
+	}
+	if err := s.repository.Update(ctx, target); err != nil {
+		return nil, err
+	}
+	if err := s.repository.SaveMerge(ctx, merge); err != nil {
+		return nil, err
+	}
+
+	deletedSource := *source
+	deletedSource.DeletedAt = &merge.MergedAt
+	if err := s.record(ctx, domain.ActionMerged, source, &deletedSource); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, domain.ActionMerged, &before, target); err != nil {
+		return nil, err
+	}
+
+	s.unindex(ctx, source.ID)
+	s.index(ctx, target)
+	s.publish(ctx, domain.BookDeleted{BookID: source.ID, At: merge.MergedAt})
+	s.publish(ctx, domain.BookUpdated{Book: *target, Changes: domain.Diff(&before, target), At: merge.MergedAt})
+	return target, nil
+}
+
+func (s *BookService) ResolveAlias(ctx context.Context, id string) (string, error) {
+	merge, err := s.repository.FindMerge(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return merge.TargetID, nil
+}
+
+func (s *BookService) ListMerges(ctx context.Context, targetID string) ([]*domain.Merge, error) {
+	if _, err := s.repository.FindByID(ctx, targetID); err != nil {
+		return nil, err
+	}
+	return s.repository.FindMergesByTarget(ctx, targetID)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+)
+
+func TestBookService_MergeBooks(t *testing.T) {
+	ctx := context.Background()
+
+	newRepo := func(saved *[]*domain.Merge, deleted *[]string) *mocks.BookRepository {
+		books := map[string]domain.Book{
+			"target-id": {ID: "target-id", Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"},
+			"source-id": {ID: "source-id", Title: "Clean Code: A Handbook", Author: "Robert C. Martin", ISBN: "9780132350884"},
+		}
+		return &mocks.BookRepository{
+			FindByIDFunc: func(ctx context.Context, id string) (*domain.Book, error) {
+				book, exists := books[id]
+				if !exists {
+					return nil, domain.ErrBookNotFound
+				}
+				return &book, nil
+			},
+			DeleteFunc: func(ctx context.Context, id string) error {
+				*deleted = append(*deleted, id)
+				return nil
+			},
+			SaveMergeFunc: func(ctx context.Context, merge *domain.Merge) error {
+				*saved = append(*saved, merge)
+				return nil
+			},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var saved []*domain.Merge
+		var deleted []string
+		service := NewBookService(newRepo(&saved, &deleted))
+
+		book, err := service.MergeBooks(ctx, "target-id", "source-id", map[string]string{"title": "source"})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if book.Title != "Clean Code: A Handbook" {
+			t.Errorf("expected source title, got %s", book.Title)
+		}
+		if book.ISBN != "0132350882" {
+			t.Errorf("expected target ISBN, got %s", book.ISBN)
+		}
+		if len(deleted) != 1 || deleted[0] != "source-id" {
+			t.Errorf("expected source to be deleted, got %v", deleted)
+		}
+		if len(saved) != 1 || saved[0].SourceID != "source-id" || saved[0].TargetID != "target-id" {
+			t.Errorf("expected merge to be recorded, got %+v", saved)
+		}
+	})
+
+	t.Run("invalid resolution", func(t *testing.T) {
+		var saved []*domain.Merge
+		var deleted []string
+		service := NewBookService(newRepo(&saved, &deleted))
+
+		_, err := service.MergeBooks(ctx, "target-id", "source-id", map[string]string{"title": "both"})
+
+		if domain.GetStatusCode(err) != 400 {
+			t.Errorf("expected invalid input error, got %v", err)
+		}
+		if len(deleted) != 0 {
+			t.Error("expected nothing to be deleted")
+		}
+	})
+
+	t.Run("merge into itself", func(t *testing.T) {
+		var saved []*domain.Merge
+		var deleted []string
+		service := NewBookService(newRepo(&saved, &deleted))
+
+		_, err := service.MergeBooks(ctx, "target-id", "target-id", nil)
+
+		if domain.GetStatusCode(err) != 400 {
+			t.Errorf("expected invalid input error, got %v", err)
+		}
+	})
+
+	t.Run("source not found", func(t *testing.T) {
+		var saved []*domain.Merge
+		var deleted []string
+		service := NewBookService(newRepo(&saved, &deleted))
+
+		_, err := service.MergeBooks(ctx, "target-id", "missing", nil)
+
+		if err != domain.ErrBookNotFound {
+			t.Errorf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+}
+
+func TestBookService_ResolveAlias(t *testing.T) {
+	ctx := context.Background()
+	repo := &mocks.BookRepository{
+		FindMergeFunc: func(ctx context.Context, sourceID string) (*domain.Merge, error) {
+			if sourceID == "old-id" {
+				return &domain.Merge{SourceID: "old-id", TargetID: "new-id"}, nil
+			}
+			return nil, domain.ErrBookNotFound
+		},
+	}
+	service := NewBookService(repo)
+
+	targetID, err := service.ResolveAlias(ctx, "old-id")
+
+	if err != nil || targetID != "new-id" {
+		t.Errorf("expected new-id, got %s (%v)", targetID, err)
+	}
+
+	if _, err := service.ResolveAlias(ctx, "unknown"); err != domain.ErrBookNotFound {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+import "solid/internal/domain"
+
+type Option func(*BookService)
+
+// WithHistory records an audit entry for every change. Entries are written
+// inside the change's transaction, so pass the book repository itself when
+// it implements HistoryRepository to have them committed or rolled back
+// together.
+func WithHistory(history domain.HistoryRepository) Option {
+	return func(s *BookService) {
+		s.history = history
+	}
+}
+
+func WithPublisher(publisher domain.EventPublisher) Option {
+	return func(s *BookService) {
+		s.publisher = publisher
+	}
+}
+
+func WithChangeLog(changes domain.ChangeLog) Option {
+	return func(s *BookService) {
+		s.changes = changes
+	}
+}
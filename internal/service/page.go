@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+
+	"solid/internal/domain"
+)
+
+const (
+	DefaultBookLimit = 100
+	MaxBookLimit     = 1000
+)
+
+// ListBooksPage returns books ordered by creation time. The cursor is
+// opaque to callers and encodes the position of the last book returned, so
+// deleting that book does not skip or repeat any of the following ones. A
+// non-nil filter restricts the books paged through.
+func (s *BookService) ListBooksPage(ctx context.Context, filter domain.BookFilter, cursor string, limit int) (*domain.BookPage, error) {
+	if limit <= 0 {
+		limit = DefaultBookLimit
+	}
+	if limit > MaxBookLimit {
+		limit = MaxBookLimit
+	}
+
+	var after *pageCursor
+	if cursor != "" {
+		decoded, err := decodePageCursor(cursor)
+		if err != nil {
+			return nil, domain.ErrInvalidInput.WithMessage("invalid cursor")
+		}
+		after = decoded
+	}
+
+	books, err := s.FindBooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(books, func(i, j int) bool {
+		return pageCursorOf(books[i]).before(pageCursorOf(books[j]))
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(books), func(i int) bool {
+			return after.before(pageCursorOf(books[i]))
+		})
+	}
+	end := start + limit
+	if end > len(books) {
+		end = len(books)
+	}
+
+	page := &domain.BookPage{Books: books[start:end], HasMore: end < len(books)}
+	if page.HasMore {
+		page.NextCursor = pageCursorOf(books[end-1]).encode()
+	}
+	return page, nil
+}
+
+// BookCursor is the cursor that makes ListBooksPage continue after book.
+func BookCursor(book *domain.Book) string {
+	return pageCursorOf(book).encode()
+}
+
+type pageCursor struct {
+	createdAt time.Time
+	id        string
+}
+
+func pageCursorOf(book *domain.Book) pageCursor {
+	return pageCursor{createdAt: book.CreatedAt, id: book.ID}
+}
+
+func (c pageCursor) before(other pageCursor) bool {
+	if c.createdAt.Equal(other.createdAt) {
+		return c.id < other.id
+	}
+	return c.createdAt.Before(other.createdAt)
+}
+
+func (c pageCursor) encode() string {
+	raw := c.createdAt.UTC().Format(time.RFC3339Nano) + "|" + c.id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePageCursor(cursor string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, domain.ErrInvalidInput
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return &pageCursor{createdAt: parsed, id: id}, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+	"time"
+)
+
+func TestBookService_ListBooksPage(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	books := []*domain.Book{
+		{ID: "c", CreatedAt: base.Add(2 * time.Second)},
+		{ID: "a", CreatedAt: base},
+		{ID: "d", CreatedAt: base.Add(2 * time.Second)},
+		{ID: "b", CreatedAt: base.Add(time.Second)},
+	}
+	repo := &mocks.BookRepository{
+		FindAllFunc: func(ctx context.Context) ([]*domain.Book, error) {
+			live := make([]*domain.Book, 0, len(books))
+			for _, book := range books {
+				if !book.IsDeleted() {
+					bookCopy := *book
+					live = append(live, &bookCopy)
+				}
+			}
+			return live, nil
+		},
+	}
+	ids := func(page *domain.BookPage) string {
+		out := ""
+		for _, book := range page.Books {
+			out += book.ID
+		}
+		return out
+	}
+
+	t.Run("walks pages in creation order", func(t *testing.T) {
+		service := NewBookService(repo)
+
+		first, err := service.ListBooksPage(ctx, nil, "", 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ids(first) != "abc" || !first.HasMore || first.NextCursor == "" {
+			t.Fatalf("unexpected first page %+v", first)
+		}
+
+		second, err := service.ListBooksPage(ctx, nil, first.NextCursor, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ids(second) != "d" || second.HasMore || second.NextCursor != "" {
+			t.Errorf("unexpected second page %+v", second)
+		}
+	})
+
+	t.Run("cursor survives deletion", func(t *testing.T) {
+		service := NewBookService(repo)
+
+		first, _ := service.ListBooksPage(ctx, nil, "", 2)
+		deletedAt := time.Now()
+		books[3].DeletedAt = &deletedAt
+		defer func() { books[3].DeletedAt = nil }()
+
+		second, err := service.ListBooksPage(ctx, nil, first.NextCursor, 2)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ids(second) != "cd" {
+			t.Errorf("expected cd, got %s", ids(second))
+		}
+	})
+
+	t.Run("filters repositories that cannot", func(t *testing.T) {
+		service := NewBookService(repo)
+		notB := matchFunc(func(book *domain.Book) bool { return book.ID != "b" })
+
+		first, err := service.ListBooksPage(ctx, notB, "", 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		second, _ := service.ListBooksPage(ctx, notB, first.NextCursor, 2)
+
+		if ids(first) != "ac" || ids(second) != "d" || second.HasMore {
+			t.Errorf("expected ac then d, got %s then %s", ids(first), ids(second))
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		service := NewBookService(repo)
+
+		_, err := service.ListBooksPage(ctx, nil, "not a cursor", 2)
+
+		var domainErr *domain.DomainError
+		if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrInvalidInput.Code {
+			t.Errorf("expected invalid input, got %v", err)
+		}
+	})
+}
+
+type matchFunc func(book *domain.Book) bool
+
+func (f matchFunc) Match(book *domain.Book) bool { return f(book) }
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"solid/internal/domain"
+)
+
+func (s *BookService) ListTrash(ctx context.Context) ([]*domain.Book, error) {
+	return s.repository.FindDeleted(ctx)
+}
+
+func (s *BookService) RestoreBook(ctx context.Context, id string) (*domain.Book, error) {
+	return withinTx(ctx, s, func(ctx context.Context) (*domain.Book, error) {
+		return s.restoreBook(ctx, id)
+	})
+}
+
+func (s *BookService) restoreBook(ctx context.Context, id string) (*domain.Book, error) {
+	if err := s.repository.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	book, err := s.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := book
+	if s.history != nil {
+		if entries, err := s.history.FindByBook(ctx, id); err == nil && len(entries) > 0 {
+			before = &entries[len(entries)-1].Snapshot
+		}
+		if err := s.record(ctx, domain.ActionRestored, before, book); err != nil {
+			return nil, err
+		}
+	}
+
+	s.index(ctx, book)
+	s.publish(ctx, domain.BookUpdated{Book: *book, Changes: domain.Diff(before, book), At: time.Now()})
+	return book, nil
+}
+
+func (s *BookService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	return s.repository.Purge(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+	"time"
+)
+
+func TestBookService_RestoreBook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		repo := &mocks.BookRepository{
+			FindByIDFunc: func(ctx context.Context, id string) (*domain.Book, error) {
+				return &domain.Book{ID: id, Title: "Clean Code", Author: "Robert Martin"}, nil
+			},
+		}
+		service := NewBookService(repo)
+
+		book, err := service.RestoreBook(ctx, "test-id")
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if book.ID != "test-id" {
+			t.Errorf("expected book test-id, got %s", book.ID)
+		}
+		if got, _ := service.SuggestBooks(ctx, "title", "clean", 5); len(got) != 1 {
+			t.Errorf("expected restored book to be suggested, got %+v", got)
+		}
+	})
+
+	t.Run("isbn conflict", func(t *testing.T) {
+		repo := &mocks.BookRepository{
+			RestoreFunc: func(ctx context.Context, id string) error {
+				return domain.ErrBookAlreadyExists
+			},
+		}
+		service := NewBookService(repo)
+
+		_, err := service.RestoreBook(ctx, "test-id")
+
+		if err != domain.ErrBookAlreadyExists {
+			t.Errorf("expected ErrBookAlreadyExists, got %v", err)
+		}
+	})
+}
+
+func TestBookService_PurgeTrash(t *testing.T) {
+	ctx := context.Background()
+	var cutoff time.Time
+	repo := &mocks.BookRepository{
+		PurgeFunc: func(ctx context.Context, deletedBefore time.Time) (int, error) {
+			cutoff = deletedBefore
+			return 3, nil
+		},
+	}
+	service := NewBookService(repo)
+
+	purged, err := service.PurgeTrash(ctx, 24*time.Hour)
+
+	if err != nil || purged != 3 {
+		t.Errorf("expected 3 purged, got %d (%v)", purged, err)
+	}
+	if age := time.Since(cutoff); age < 24*time.Hour || age > 25*time.Hour {
+		t.Errorf("expected cutoff 24h ago, got %s", age)
+	}
+}
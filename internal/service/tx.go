@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"solid/internal/domain"
+)
+
+type afterCommitKey struct{}
+
+type afterCommitHooks struct {
+	hooks []func(ctx context.Context) error
+}
+
+// withinTx runs fn in a repository transaction when the repository supports
+// one. Its error only ever reports that the write did not commit: hooks
+// registered with afterCommit run once it has, and cannot fail it.
+func withinTx[T any](ctx context.Context, s *BookService, fn func(ctx context.Context) (T, error)) (T, error) {
+	if _, nested := ctx.Value(afterCommitKey{}).(*afterCommitHooks); nested {
+		return fn(ctx)
+	}
+
+	pending := &afterCommitHooks{}
+	txCtx := context.WithValue(ctx, afterCommitKey{}, pending)
+
+	var result T
+	run := func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	}
+
+	var err error
+	if tx, ok := s.repository.(domain.Transactor); ok {
+		err = tx.WithinTx(txCtx, run)
+	} else {
+		err = run(txCtx)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	for _, hook := range pending.hooks {
+		runHook(ctx, hook)
+	}
+	return result, nil
+}
+
+// afterCommit defers hook until the surrounding transaction commits. Hooks
+// only notify other components of a committed write: a failing hook is
+// logged and never turns that write into an error.
+func (s *BookService) afterCommit(ctx context.Context, hook func(ctx context.Context) error) {
+	if pending, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+		pending.hooks = append(pending.hooks, hook)
+		return
+	}
+	runHook(ctx, hook)
+}
+
+func runHook(ctx context.Context, hook func(ctx context.Context) error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("after-commit hook panicked: %v", p)
+		}
+	}()
+
+	if err := hook(ctx); err != nil {
+		log.Printf("after-commit hook failed: %v", err)
+	}
+}
+
+func (s *BookService) supportsTx() bool {
+	_, ok := s.repository.(domain.Transactor)
+	return ok
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"solid/pkg/mocks"
+	"testing"
+)
+
+func TestWithinTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failing hooks do not fail the commit", func(t *testing.T) {
+		repo := &mocks.TransactionalBookRepository{}
+		service := NewBookService(repo)
+		var ran []string
+
+		result, err := withinTx(ctx, service, func(ctx context.Context) (string, error) {
+			service.afterCommit(ctx, func(ctx context.Context) error {
+				ran = append(ran, "failing")
+				return errors.New("notification failed")
+			})
+			service.afterCommit(ctx, func(ctx context.Context) error {
+				ran = append(ran, "panicking")
+				panic("boom")
+			})
+			service.afterCommit(ctx, func(ctx context.Context) error {
+				ran = append(ran, "second")
+				return nil
+			})
+			return "committed", nil
+		})
+
+		if err != nil || result != "committed" {
+			t.Fatalf("expected the committed result, got %q (%v)", result, err)
+		}
+		if repo.Commits != 1 {
+			t.Errorf("expected 1 commit, got %d", repo.Commits)
+		}
+		if len(ran) != 3 || ran[2] != "second" {
+			t.Errorf("expected every hook to run, got %v", ran)
+		}
+	})
+
+	t.Run("commit failure skips hooks", func(t *testing.T) {
+		repo := &mocks.TransactionalBookRepository{}
+		service := NewBookService(repo)
+		failure := errors.New("write failed")
+		ran := false
+
+		_, err := withinTx(ctx, service, func(ctx context.Context) (string, error) {
+			service.afterCommit(ctx, func(ctx context.Context) error {
+				ran = true
+				return nil
+			})
+			return "", failure
+		})
+
+		if err != failure || repo.Rollbacks != 1 {
+			t.Errorf("expected the write error and a rollback, got %v (%d rollbacks)", err, repo.Rollbacks)
+		}
+		if ran {
+			t.Error("expected hooks not to run after a rollback")
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"solid/internal/domain"
+	"solid/pkg/mocks"
+	"testing"
+)
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns the secret once", func(t *testing.T) {
+		var stored *domain.Subscription
+		repo := &mocks.WebhookRepository{
+			CreateSubscriptionFunc: func(ctx context.Context, sub *domain.Subscription) error {
+				stored = sub
+				return nil
+			},
+			FindSubscriptionFunc: func(ctx context.Context, id string) (*domain.Subscription, error) {
+				subCopy := *stored
+				return &subCopy, nil
+			},
+		}
+		service := NewWebhookService(repo)
+
+		sub, err := service.CreateSubscription(ctx, "https://example.com/hook", []string{"*"}, "s3cret")
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sub.Secret != "s3cret" {
+			t.Errorf("expected secret on creation, got %q", sub.Secret)
+		}
+		if found, _ := service.GetSubscription(ctx, sub.ID); found.Secret != "" {
+			t.Errorf("expected secret to be redacted, got %q", found.Secret)
+		}
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		service := NewWebhookService(&mocks.WebhookRepository{
+			CreateSubscriptionFunc: func(ctx context.Context, sub *domain.Subscription) error {
+				t.Error("expected nothing to be stored")
+				return nil
+			},
+		})
+
+		_, err := service.CreateSubscription(ctx, "/hook", []string{"*"}, "")
+
+		if domain.GetStatusCode(err) != 400 {
+			t.Errorf("expected invalid input error, got %v", err)
+		}
+	})
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	ctx := context.Background()
+	book := &domain.Book{ID: "book-1", Title: "Clean Code"}
+	msg, _ := domain.NewOutboxMessage(domain.EventBookCreated, book)
+
+	newRepo := func(saved map[string]*domain.Delivery) *mocks.WebhookRepository {
+		return &mocks.WebhookRepository{
+			FindSubscriptionsFunc: func(ctx context.Context) ([]*domain.Subscription, error) {
+				return []*domain.Subscription{
+					{ID: "created", Events: []string{domain.EventBookCreated}, Active: true},
+					{ID: "deleted", Events: []string{domain.EventBookDeleted}, Active: true},
+					{ID: "inactive", Events: []string{"*"}, Active: false},
+				}, nil
+			},
+			SaveDeliveryFunc: func(ctx context.Context, delivery *domain.Delivery) error {
+				saved[delivery.ID] = delivery
+				return nil
+			},
+			FindDeliveryFunc: func(ctx context.Context, id string) (*domain.Delivery, error) {
+				if delivery, exists := saved[id]; exists {
+					return delivery, nil
+				}
+				return nil, domain.ErrDeliveryNotFound
+			},
+		}
+	}
+
+	t.Run("queues matching subscriptions", func(t *testing.T) {
+		saved := make(map[string]*domain.Delivery)
+		service := NewWebhookService(newRepo(saved))
+
+		if err := service.Deliver(ctx, msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(saved) != 1 {
+			t.Fatalf("expected 1 delivery, got %d", len(saved))
+		}
+		for _, delivery := range saved {
+			if delivery.SubscriptionID != "created" || delivery.EventType != domain.EventBookCreated {
+				t.Errorf("unexpected delivery %+v", delivery)
+			}
+		}
+	})
+
+	t.Run("outbox retries keep the existing delivery", func(t *testing.T) {
+		saved := make(map[string]*domain.Delivery)
+		service := NewWebhookService(newRepo(saved))
+		service.Deliver(ctx, msg)
+		for _, delivery := range saved {
+			delivery.Status = domain.DeliveryDelivered
+		}
+
+		service.Deliver(ctx, msg)
+
+		for _, delivery := range saved {
+			if len(saved) != 1 || delivery.Status != domain.DeliveryDelivered {
+				t.Errorf("expected the delivered delivery to be kept, got %d deliveries (%s)", len(saved), delivery.Status)
+			}
+		}
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	ctx := context.Background()
+	dead := func() *domain.Delivery {
+		return &domain.Delivery{
+			ID:             "delivery-1",
+			SubscriptionID: "sub-1",
+			Status:         domain.DeliveryDead,
+			Attempts:       []domain.DeliveryAttempt{{Error: "timeout"}, {Error: "timeout"}},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var saved *domain.Delivery
+		service := NewWebhookService(&mocks.WebhookRepository{
+			FindDeliveryFunc: func(ctx context.Context, id string) (*domain.Delivery, error) {
+				return dead(), nil
+			},
+			SaveDeliveryFunc: func(ctx context.Context, delivery *domain.Delivery) error {
+				saved = delivery
+				return nil
+			},
+		})
+
+		_, err := service.Redeliver(ctx, "sub-1", "delivery-1")
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if saved.Status != domain.DeliveryPending || saved.RetryFrom != 2 {
+			t.Errorf("expected a pending delivery with a fresh retry budget, got %+v", saved)
+		}
+	})
+
+	t.Run("delivery of another subscription", func(t *testing.T) {
+		service := NewWebhookService(&mocks.WebhookRepository{
+			FindDeliveryFunc: func(ctx context.Context, id string) (*domain.Delivery, error) {
+				return dead(), nil
+			},
+		})
+
+		_, err := service.Redeliver(ctx, "sub-2", "delivery-1")
+
+		if err != domain.ErrDeliveryNotFound {
+			t.Errorf("expected ErrDeliveryNotFound, got %v", err)
+		}
+	})
+}
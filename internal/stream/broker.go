@@ -0,0 +1,224 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"solid/internal/domain"
+	v1 "solid/internal/handler/v1"
+)
+
+const (
+	DefaultReplaySize   = 1000
+	DefaultClientBuffer = 64
+
+	// EventReset tells a client that the events it asked to resume from are
+	// gone. It should list the books again and keep following the stream.
+	EventReset = "reset"
+)
+
+var ErrClosed = errors.New("stream broker is closed")
+
+var changeEvents = map[string]string{
+	domain.ChangeCreate:  domain.EventBookCreated,
+	domain.ChangeUpdate:  domain.EventBookUpdated,
+	domain.ChangeDelete:  domain.EventBookDeleted,
+	domain.ChangeRestore: "book.restored",
+	domain.ChangePurge:   "book.purged",
+}
+
+// Message is one server-sent event. Its ID is the sequence of the change in
+// the persisted change log, so it survives restarts and can be passed as
+// since to the changes feed.
+type Message struct {
+	ID    uint64
+	Event string
+	Data  []byte
+}
+
+type Client struct {
+	messages chan Message
+}
+
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+// Broker streams the change log to connected clients. It does not keep
+// events itself: every published event wakes it up to send the changes
+// committed since the last one it sent, and reconnecting clients are
+// replayed from the log.
+type Broker struct {
+	changes      domain.ChangeLog
+	mu           sync.Mutex
+	replaySize   int
+	clientBuffer int
+	lastID       uint64
+	clients      map[*Client]struct{}
+	closed       bool
+}
+
+func NewBroker(changes domain.ChangeLog, replaySize, clientBuffer int) (*Broker, error) {
+	latest, err := changes.LatestSequence(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &Broker{
+		changes:      changes,
+		replaySize:   replaySize,
+		clientBuffer: clientBuffer,
+		lastID:       latest,
+		clients:      make(map[*Client]struct{}),
+	}, nil
+}
+
+// Publish sends the changes committed since the last call. The event itself
+// is only the signal that something was committed.
+func (b *Broker) Publish(ctx context.Context, e domain.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	changes, err := b.changes.Changes(ctx, b.lastID, 0)
+	if expired(err) {
+		// Compaction overtook the stream: clients reconnect and are reset.
+		for c := range b.clients {
+			b.drop(c)
+		}
+		b.lastID, err = b.changes.LatestSequence(ctx)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		msg, err := newMessage(change)
+		if err != nil {
+			return err
+		}
+		b.lastID = change.Sequence
+
+		for c := range b.clients {
+			select {
+			case c.messages <- msg:
+			default:
+				b.drop(c)
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a client. With resume set, it also returns the
+// changes after lastEventID, or a single EventReset message when they are
+// no longer retained, more than the replay size, or from another log.
+func (b *Broker) Subscribe(ctx context.Context, lastEventID uint64, resume bool) (*Client, []Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, nil, ErrClosed
+	}
+
+	missed := make([]Message, 0)
+	if resume {
+		var err error
+		if missed, err = b.replay(ctx, lastEventID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c := &Client{messages: make(chan Message, b.clientBuffer)}
+	b.clients[c] = struct{}{}
+	return c, missed, nil
+}
+
+func (b *Broker) replay(ctx context.Context, lastEventID uint64) ([]Message, error) {
+	if lastEventID > b.lastID {
+		return b.reset(lastEventID)
+	}
+
+	changes, err := b.changes.Changes(ctx, lastEventID, b.replaySize+1)
+	if expired(err) {
+		return b.reset(lastEventID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	missed := make([]Message, 0, len(changes))
+	for _, change := range changes {
+		// Later changes reach the client through Publish.
+		if change.Sequence > b.lastID {
+			break
+		}
+		if len(missed) == b.replaySize {
+			return b.reset(lastEventID)
+		}
+		msg, err := newMessage(change)
+		if err != nil {
+			return nil, err
+		}
+		missed = append(missed, msg)
+	}
+	return missed, nil
+}
+
+func (b *Broker) reset(lastEventID uint64) ([]Message, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"code":    domain.ErrChangesExpired.Code,
+		"message": fmt.Sprintf("events after %d cannot be replayed; list the books again and continue from %d", lastEventID, b.lastID),
+		"latest":  b.lastID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []Message{{ID: b.lastID, Event: EventReset, Data: data}}, nil
+}
+
+func (b *Broker) Unsubscribe(c *Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.drop(c)
+}
+
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for c := range b.clients {
+		b.drop(c)
+	}
+}
+
+func (b *Broker) drop(c *Client) {
+	if _, exists := b.clients[c]; !exists {
+		return
+	}
+	delete(b.clients, c)
+	close(c.messages)
+}
+
+func newMessage(change *domain.Change) (Message, error) {
+	data, err := json.Marshal(v1.NewChange(change))
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{ID: change.Sequence, Event: changeEvents[change.Operation], Data: data}, nil
+}
+
+// expired reports whether err is ErrChangesExpired, which the change log
+// returns with its own message and details.
+func expired(err error) bool {
+	var domainErr *domain.DomainError
+	return errors.As(err, &domainErr) && domainErr.Code == domain.ErrChangesExpired.Code
+}
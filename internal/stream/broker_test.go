@@ -0,0 +1,158 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"solid/internal/domain"
+	"solid/internal/repository"
+)
+
+func newBroker(t *testing.T, changes domain.ChangeLog, replaySize, clientBuffer int) *Broker {
+	t.Helper()
+
+	broker, err := NewBroker(changes, replaySize, clientBuffer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return broker
+}
+
+func write(t *testing.T, repo *repository.InMemoryBookRepository, broker *Broker, isbn string) *domain.Book {
+	t.Helper()
+
+	ctx := context.Background()
+	book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: isbn}
+	if err := repo.Create(ctx, book); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := broker.Publish(ctx, domain.BookCreated{Book: *book}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return book
+}
+
+func TestBroker_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("replays changes after Last-Event-ID", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		broker := newBroker(t, repo, 10, 10)
+		book := write(t, repo, broker, "0132350882")
+		repo.Update(ctx, book)
+		repo.Delete(ctx, book.ID)
+		broker.Publish(ctx, domain.BookDeleted{})
+
+		_, missed, err := broker.Subscribe(ctx, 1, true)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(missed) != 2 || missed[0].ID != 2 || missed[0].Event != domain.EventBookUpdated || missed[1].Event != domain.EventBookDeleted {
+			t.Errorf("unexpected replay %+v", missed)
+		}
+	})
+
+	t.Run("ids continue from the persisted log", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		repo.Create(ctx, &domain.Book{Title: "Refactoring", Author: "Martin Fowler", ISBN: "0201485672"})
+		broker := newBroker(t, repo, 10, 10)
+		client, missed, _ := broker.Subscribe(ctx, 0, false)
+
+		write(t, repo, broker, "0132350882")
+
+		msg := <-client.Messages()
+		if len(missed) != 0 || msg.ID != 2 || msg.Event != domain.EventBookCreated {
+			t.Errorf("expected only change 2 live, got %+v then %+v", missed, msg)
+		}
+		var change struct {
+			Sequence uint64 `json:"sequence"`
+		}
+		if err := json.Unmarshal(msg.Data, &change); err != nil || change.Sequence != 2 {
+			t.Errorf("expected the change as data, got %s", msg.Data)
+		}
+	})
+
+	t.Run("resets clients further behind than the replay size", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		broker := newBroker(t, repo, 2, 10)
+		for _, isbn := range []string{"0132350882", "0201485672", "0321125215"} {
+			write(t, repo, broker, isbn)
+		}
+
+		_, missed, _ := broker.Subscribe(ctx, 0, true)
+		_, recent, _ := broker.Subscribe(ctx, 1, true)
+
+		if len(missed) != 1 || missed[0].Event != EventReset || missed[0].ID != 3 {
+			t.Errorf("expected a reset to 3, got %+v", missed)
+		}
+		if len(recent) != 2 || recent[0].ID != 2 {
+			t.Errorf("expected changes 2 and 3, got %+v", recent)
+		}
+	})
+
+	t.Run("resets clients behind the compacted log", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		broker := newBroker(t, repo, 10, 10)
+		for _, isbn := range []string{"0132350882", "0201485672", "0321125215"} {
+			write(t, repo, broker, isbn)
+		}
+		repo.CompactChanges(ctx, 1)
+
+		_, missed, _ := broker.Subscribe(ctx, 1, true)
+
+		if len(missed) != 1 || missed[0].Event != EventReset {
+			t.Fatalf("expected a reset, got %+v", missed)
+		}
+		var reset map[string]interface{}
+		json.Unmarshal(missed[0].Data, &reset)
+		if reset["code"] != domain.ErrChangesExpired.Code || reset["latest"] != float64(3) {
+			t.Errorf("unexpected reset %s", missed[0].Data)
+		}
+	})
+
+	t.Run("resets clients ahead of the log", func(t *testing.T) {
+		broker := newBroker(t, repository.NewInMemoryBookRepository(), 10, 10)
+
+		_, missed, _ := broker.Subscribe(ctx, 42, true)
+
+		if len(missed) != 1 || missed[0].Event != EventReset || missed[0].ID != 0 {
+			t.Errorf("expected a reset to 0, got %+v", missed)
+		}
+	})
+
+	t.Run("slow clients are disconnected", func(t *testing.T) {
+		repo := repository.NewInMemoryBookRepository()
+		broker := newBroker(t, repo, 10, 1)
+		slow, _, _ := broker.Subscribe(ctx, 0, false)
+		fast, _, _ := broker.Subscribe(ctx, 0, false)
+
+		write(t, repo, broker, "0132350882")
+		<-fast.Messages()
+		write(t, repo, broker, "0201485672")
+
+		<-slow.Messages()
+		if _, open := <-slow.Messages(); open {
+			t.Error("expected slow client channel to be closed")
+		}
+		if msg := <-fast.Messages(); msg.ID != 2 {
+			t.Errorf("expected fast client to keep receiving, got %+v", msg)
+		}
+	})
+
+	t.Run("close terminates clients", func(t *testing.T) {
+		broker := newBroker(t, repository.NewInMemoryBookRepository(), 10, 10)
+		client, _, _ := broker.Subscribe(ctx, 0, false)
+
+		broker.Close()
+
+		if _, open := <-client.Messages(); open {
+			t.Error("expected client channel to be closed")
+		}
+		if _, _, err := broker.Subscribe(ctx, 0, false); !errors.Is(err, ErrClosed) {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package stream_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+	"solid/internal/handler"
+	"solid/internal/repository"
+	"solid/internal/stream"
+)
+
+func TestChangeStreamHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryBookRepository()
+	broker, err := stream.NewBroker(repo, 10, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	book := &domain.Book{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"}
+	repo.Create(ctx, book)
+	broker.Publish(ctx, domain.BookCreated{Book: *book})
+	server := httptest.NewServer(http.HandlerFunc(handler.NewChangeStreamHandler(broker).Stream))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %s", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	expectLine := func(prefix string) {
+		t.Helper()
+		for {
+			select {
+			case line, open := <-lines:
+				if !open {
+					t.Fatalf("stream closed before %q", prefix)
+				}
+				if strings.HasPrefix(line, prefix) {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("timed out waiting for %q", prefix)
+			}
+		}
+	}
+
+	expectLine("id: 1")
+	expectLine("event: book.created")
+
+	repo.Delete(ctx, book.ID)
+	broker.Publish(ctx, domain.BookDeleted{BookID: book.ID})
+	expectLine("id: 2")
+	expectLine("event: book.deleted")
+
+	broker.Close()
+	for range lines {
+	}
+}
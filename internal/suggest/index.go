@@ -0,0 +1,236 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"solid/internal/domain"
+)
+
+const MaxLimit = 20
+
+type Field string
+
+const (
+	FieldTitle  Field = "title"
+	FieldAuthor Field = "author"
+)
+
+func ParseField(s string) (Field, bool) {
+	switch Field(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FieldTitle:
+		return FieldTitle, true
+	case FieldAuthor:
+		return FieldAuthor, true
+	}
+	return "", false
+}
+
+type Suggestion struct {
+	Text   string `json:"text"`
+	BookID string `json:"book_id"`
+}
+
+type Index struct {
+	mu    sync.RWMutex
+	tries map[Field]*trie
+	books map[string]indexedBook
+
+	// rebuilding serialises Rebuild; pending collects the writes made while
+	// one runs, to be replayed on the new tries.
+	rebuilding sync.Mutex
+	pending    []pendingWrite
+	recording  bool
+}
+
+type pendingWrite struct {
+	book *domain.Book
+	id   string
+}
+
+type indexedBook struct {
+	title  string
+	author string
+	score  int64
+}
+
+func NewIndex() *Index {
+	return &Index{
+		tries: newTries(),
+		books: make(map[string]indexedBook),
+	}
+}
+
+func newTries() map[Field]*trie {
+	return map[Field]*trie{
+		FieldTitle:  newTrie(),
+		FieldAuthor: newTrie(),
+	}
+}
+
+func newIndexedBook(book *domain.Book) indexedBook {
+	return indexedBook{
+		title:  book.Title,
+		author: book.Author,
+		score:  book.UpdatedAt.UnixNano(),
+	}
+}
+
+func (idx *Index) Put(book *domain.Book) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.recording {
+		idx.pending = append(idx.pending, pendingWrite{book: book})
+	}
+	idx.put(book)
+}
+
+func (idx *Index) put(book *domain.Book) {
+	idx.remove(book.ID)
+
+	entry := newIndexedBook(book)
+	idx.books[book.ID] = entry
+	idx.tries[FieldTitle].insert(entry.title, book.ID, entry.score)
+	idx.tries[FieldAuthor].insert(entry.author, book.ID, entry.score)
+}
+
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.recording {
+		idx.pending = append(idx.pending, pendingWrite{id: id})
+	}
+	idx.remove(id)
+}
+
+// Rebuild replaces the contents of the index with the books load returns.
+// The new tries are built in bulk without holding the index lock, so
+// suggestions keep being served from the old contents meanwhile. Puts and
+// removes made after load is called are replayed on the new tries before
+// they are swapped in, so they are not lost.
+func (idx *Index) Rebuild(load func() ([]*domain.Book, error)) error {
+	idx.rebuilding.Lock()
+	defer idx.rebuilding.Unlock()
+
+	idx.mu.Lock()
+	idx.recording = true
+	idx.mu.Unlock()
+
+	books, err := load()
+	if err != nil {
+		idx.mu.Lock()
+		idx.recording, idx.pending = false, nil
+		idx.mu.Unlock()
+		return err
+	}
+
+	tries := newTries()
+	entries := make(map[string]indexedBook, len(books))
+	for _, book := range books {
+		entry := newIndexedBook(book)
+		entries[book.ID] = entry
+		tries[FieldTitle].add(entry.title, book.ID, entry.score)
+		tries[FieldAuthor].add(entry.author, book.ID, entry.score)
+	}
+	for _, t := range tries {
+		t.finish()
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.tries, idx.books = tries, entries
+	for _, write := range idx.pending {
+		if write.book != nil {
+			idx.put(write.book)
+		} else {
+			idx.remove(write.id)
+		}
+	}
+	idx.recording, idx.pending = false, nil
+	return nil
+}
+
+func (idx *Index) remove(id string) {
+	entry, exists := idx.books[id]
+	if !exists {
+		return
+	}
+	idx.tries[FieldTitle].delete(entry.title, id)
+	idx.tries[FieldAuthor].delete(entry.author, id)
+	delete(idx.books, id)
+}
+
+func (idx *Index) Suggest(field Field, prefix string, limit int) []Suggestion {
+	if limit <= 0 || limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	query := []rune(normalize(prefix))
+	if len(query) == 0 {
+		return []Suggestion{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	t, ok := idx.tries[field]
+	if !ok {
+		return []Suggestion{}
+	}
+
+	matches := make(map[*node]int)
+	maxEdits := 0
+	if len(query) >= 3 {
+		maxEdits = 1
+	}
+	t.match(t.root, query, 0, maxEdits, matches)
+
+	type candidate struct {
+		entry
+		edits int
+	}
+	best := make(map[string]candidate)
+	for n, edits := range matches {
+		for _, e := range n.top {
+			if c, seen := best[e.key]; seen && (c.edits < edits || (c.edits == edits && c.score >= e.score)) {
+				continue
+			}
+			best[e.key] = candidate{entry: e, edits: edits}
+		}
+	}
+
+	candidates := make([]candidate, 0, len(best))
+	for _, c := range best {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].edits != candidates[j].edits {
+			return candidates[i].edits < candidates[j].edits
+		}
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].text < candidates[j].text
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	suggestions := make([]Suggestion, 0, len(candidates))
+	for _, c := range candidates {
+		suggestions = append(suggestions, Suggestion{Text: c.text, BookID: c.bookID})
+	}
+	return suggestions
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -0,0 +1,208 @@
+package suggest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+)
+
+func newTestBook(id, title, author string, updatedAt time.Time) *domain.Book {
+	return &domain.Book{
+		ID:        id,
+		Title:     title,
+		Author:    author,
+		ISBN:      "0132350882",
+		UpdatedAt: updatedAt,
+	}
+}
+
+func TestIndex_Suggest(t *testing.T) {
+	now := time.Now()
+	idx := NewIndex()
+	idx.Put(newTestBook("1", "Clean Code", "Robert Martin", now.Add(-2*time.Hour)))
+	idx.Put(newTestBook("2", "Clean Architecture", "Robert Martin", now.Add(-time.Hour)))
+	idx.Put(newTestBook("3", "Domain-Driven Design", "Eric Evans", now))
+
+	t.Run("prefix ranked by recency", func(t *testing.T) {
+		got := idx.Suggest(FieldTitle, "cle", 10)
+
+		if len(got) != 2 {
+			t.Fatalf("expected 2 suggestions, got %d", len(got))
+		}
+		if got[0].BookID != "2" || got[1].BookID != "1" {
+			t.Errorf("expected most recent first, got %+v", got)
+		}
+	})
+
+	t.Run("matches inner words", func(t *testing.T) {
+		got := idx.Suggest(FieldTitle, "desig", 10)
+
+		if len(got) != 1 || got[0].BookID != "3" {
+			t.Errorf("expected Domain-Driven Design, got %+v", got)
+		}
+	})
+
+	t.Run("authors are deduplicated", func(t *testing.T) {
+		got := idx.Suggest(FieldAuthor, "rob", 10)
+
+		if len(got) != 1 {
+			t.Fatalf("expected 1 suggestion, got %+v", got)
+		}
+		if got[0].Text != "Robert Martin" || got[0].BookID != "2" {
+			t.Errorf("unexpected suggestion %+v", got[0])
+		}
+	})
+
+	t.Run("tolerates one typo", func(t *testing.T) {
+		for _, prefix := range []string{"claen", "cleen", "clan", "cllean"} {
+			got := idx.Suggest(FieldTitle, prefix, 10)
+			if len(got) != 2 {
+				t.Errorf("prefix %q: expected 2 suggestions, got %+v", prefix, got)
+			}
+		}
+	})
+
+	t.Run("exact matches rank before typos", func(t *testing.T) {
+		idx := NewIndex()
+		idx.Put(newTestBook("1", "Gone Girl", "Gillian Flynn", now))
+		idx.Put(newTestBook("2", "Go in Action", "William Kennedy", now.Add(-time.Hour)))
+
+		got := idx.Suggest(FieldTitle, "go i", 10)
+
+		if len(got) == 0 || got[0].BookID != "2" {
+			t.Errorf("expected exact match first, got %+v", got)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		got := idx.Suggest(FieldTitle, "c", 1)
+
+		if len(got) != 1 {
+			t.Errorf("expected 1 suggestion, got %d", len(got))
+		}
+	})
+}
+
+func TestIndex_PutAndRemove(t *testing.T) {
+	now := time.Now()
+	idx := NewIndex()
+	idx.Put(newTestBook("1", "Clean Code", "Robert Martin", now))
+
+	idx.Put(newTestBook("1", "Refactoring", "Martin Fowler", now))
+
+	if got := idx.Suggest(FieldTitle, "clean", 10); len(got) != 0 {
+		t.Errorf("expected stale title to be removed, got %+v", got)
+	}
+	if got := idx.Suggest(FieldTitle, "refac", 10); len(got) != 1 {
+		t.Errorf("expected updated title, got %+v", got)
+	}
+
+	idx.Remove("1")
+
+	if got := idx.Suggest(FieldAuthor, "martin", 10); len(got) != 0 {
+		t.Errorf("expected no suggestions after remove, got %+v", got)
+	}
+}
+
+func TestIndex_Rebuild(t *testing.T) {
+	now := time.Now()
+	idx := NewIndex()
+	idx.Put(newTestBook("1", "Clean Code", "Robert Martin", now))
+
+	err := idx.Rebuild(func() ([]*domain.Book, error) {
+		if got := idx.Suggest(FieldTitle, "clean", 5); len(got) != 1 {
+			t.Errorf("expected the old contents while rebuilding, got %+v", got)
+		}
+		idx.Put(newTestBook("3", "Refactoring", "Martin Fowler", now))
+		idx.Remove("2")
+		return []*domain.Book{
+			newTestBook("1", "Clean Code", "Robert Martin", now),
+			newTestBook("2", "Clean Architecture", "Robert Martin", now),
+		}, nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := idx.Suggest(FieldTitle, "clean", 5); len(got) != 1 || got[0].BookID != "1" {
+		t.Errorf("expected the removal during the rebuild to be kept, got %+v", got)
+	}
+	if got := idx.Suggest(FieldTitle, "refac", 5); len(got) != 1 || got[0].BookID != "3" {
+		t.Errorf("expected the put during the rebuild to be kept, got %+v", got)
+	}
+	if got := idx.Suggest(FieldAuthor, "rob", 5); len(got) != 1 {
+		t.Errorf("expected ranked author suggestions after the rebuild, got %+v", got)
+	}
+}
+
+func TestIndex_RebuildMatchesPut(t *testing.T) {
+	books := benchmarkBooks(2000)
+	incremental := NewIndex()
+	for _, book := range books {
+		incremental.Put(book)
+	}
+	bulk := NewIndex()
+	bulk.Rebuild(func() ([]*domain.Book, error) { return books, nil })
+
+	for _, field := range []Field{FieldTitle, FieldAuthor} {
+		for _, prefix := range []string{"b", "book 1", "boak", "about", "topic 4", "auth", "author 19", "autor"} {
+			want := incremental.Suggest(field, prefix, MaxLimit)
+			got := bulk.Suggest(field, prefix, MaxLimit)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("%s %q: bulk build suggests %v, want %v", field, prefix, got, want)
+			}
+		}
+	}
+}
+
+func benchmarkBooks(n int) []*domain.Book {
+	now := time.Now()
+	books := make([]*domain.Book, 0, n)
+	for i := 0; i < n; i++ {
+		books = append(books, newTestBook(
+			fmt.Sprintf("%d", i),
+			fmt.Sprintf("Book %d about topic %d", i, i%500),
+			fmt.Sprintf("Author %d", i%2000),
+			now.Add(time.Duration(i)*time.Second),
+		))
+	}
+	return books
+}
+
+func BenchmarkIndex_Put(b *testing.B) {
+	books := benchmarkBooks(100000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := NewIndex()
+		for _, book := range books {
+			idx.Put(book)
+		}
+	}
+}
+
+func BenchmarkIndex_Suggest(b *testing.B) {
+	idx := NewIndex()
+	for _, book := range benchmarkBooks(100000) {
+		idx.Put(book)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.Suggest(FieldTitle, "boak", 10)
+	}
+}
+
+func BenchmarkIndex_Rebuild(b *testing.B) {
+	books := benchmarkBooks(100000)
+	load := func() ([]*domain.Book, error) { return books, nil }
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := NewIndex().Rebuild(load); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
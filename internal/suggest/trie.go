@@ -0,0 +1,314 @@
+package suggest
+
+import "sort"
+
+type entry struct {
+	key    string
+	text   string
+	bookID string
+	score  int64
+}
+
+type node struct {
+	children []edge
+	values   map[string]*value
+	top      []entry
+}
+
+// edge links a node to a child. Most nodes have one child, so a slice is
+// much cheaper to build than a map.
+type edge struct {
+	r    rune
+	node *node
+}
+
+type value struct {
+	text  string
+	books map[string]int64
+}
+
+func (v *value) entry(key string) entry {
+	e := entry{key: key, text: v.text}
+	for id, score := range v.books {
+		if e.bookID == "" || score > e.score || (score == e.score && id < e.bookID) {
+			e.bookID = id
+			e.score = score
+		}
+	}
+	return e
+}
+
+// chunkSize is how many nodes, first edges and ranked entries a trie
+// allocates at once. A trie has millions of small nodes, and allocating
+// them one by one leaves most of a build to the garbage collector.
+const chunkSize = 4096
+
+type trie struct {
+	root    *node
+	nodes   []node
+	edges   []edge
+	entries []entry
+}
+
+func newTrie() *trie {
+	t := &trie{}
+	t.root = t.newNode()
+	return t
+}
+
+func (t *trie) newNode() *node {
+	if len(t.nodes) == cap(t.nodes) {
+		t.nodes = make([]node, 0, chunkSize)
+	}
+	t.nodes = append(t.nodes, node{})
+	return &t.nodes[len(t.nodes)-1]
+}
+
+func (n *node) next(r rune) (*node, bool) {
+	for _, e := range n.children {
+		if e.r == r {
+			return e.node, true
+		}
+	}
+	return nil, false
+}
+
+// child returns the child of n for r, creating it if needed.
+func (t *trie) child(n *node, r rune) *node {
+	if child, exists := n.next(r); exists {
+		return child
+	}
+	child := t.newNode()
+	if len(n.children) > 0 {
+		n.children = append(n.children, edge{r: r, node: child})
+		return child
+	}
+	if len(t.edges) == cap(t.edges) {
+		t.edges = make([]edge, 0, chunkSize)
+	}
+	t.edges = append(t.edges, edge{r: r, node: child})
+	// Capped at one, so a second child moves the edges out of the chunk.
+	n.children = t.edges[len(t.edges)-1 : len(t.edges) : len(t.edges)]
+	return child
+}
+
+func (n *node) removeChild(r rune) {
+	for i, e := range n.children {
+		if e.r == r {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *trie) insert(text, bookID string, score int64) {
+	key := normalize(text)
+	for _, suffix := range wordSuffixes(key) {
+		path := t.path(suffix, true)
+		leaf := path[len(path)-1]
+		if leaf.values == nil {
+			leaf.values = make(map[string]*value)
+		}
+		v, exists := leaf.values[key]
+		if !exists {
+			v = &value{text: text, books: make(map[string]int64)}
+			leaf.values[key] = v
+		}
+		v.books[bookID] = score
+		e := v.entry(key)
+		for _, n := range path {
+			n.offer(e)
+		}
+	}
+}
+
+// add records text for bookID without ranking it. Once every book is added,
+// finish ranks the whole trie in one pass; that is far cheaper than insert,
+// which re-ranks the path for every suffix of every book.
+func (t *trie) add(text, bookID string, score int64) {
+	key := normalize(text)
+	for _, suffix := range wordSuffixes(key) {
+		leaf := t.leaf(suffix)
+		if leaf.values == nil {
+			leaf.values = make(map[string]*value)
+		}
+		v, exists := leaf.values[key]
+		if !exists {
+			v = &value{text: text, books: make(map[string]int64)}
+			leaf.values[key] = v
+		}
+		v.books[bookID] = score
+	}
+}
+
+func (t *trie) leaf(s string) *node {
+	n := t.root
+	for _, r := range s {
+		n = t.child(n, r)
+	}
+	return n
+}
+
+func (t *trie) finish() {
+	t.finishNode(t.root)
+}
+
+func (t *trie) finishNode(n *node) {
+	for _, e := range n.children {
+		t.finishNode(e.node)
+	}
+	if len(n.values) > 0 || len(n.children) != 1 {
+		n.rebuild()
+		return
+	}
+
+	// Most nodes are links in a chain and rank exactly like their child.
+	// Their copies share chunks, capped so that offer never writes past
+	// its own entries.
+	top := n.children[0].node.top
+	if len(t.entries)+len(top) > cap(t.entries) {
+		t.entries = make([]entry, 0, max(chunkSize, len(top)))
+	}
+	start := len(t.entries)
+	t.entries = append(t.entries, top...)
+	n.top = t.entries[start:len(t.entries):len(t.entries)]
+}
+
+func (t *trie) delete(text, bookID string) {
+	key := normalize(text)
+	for _, suffix := range wordSuffixes(key) {
+		path := t.path(suffix, false)
+		if path == nil {
+			continue
+		}
+		leaf := path[len(path)-1]
+		v, exists := leaf.values[key]
+		if !exists {
+			continue
+		}
+		delete(v.books, bookID)
+		if len(v.books) == 0 {
+			delete(leaf.values, key)
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			path[i].rebuild()
+		}
+		t.prune(suffix, path)
+	}
+}
+
+func (t *trie) path(s string, create bool) []*node {
+	n := t.root
+	path := []*node{n}
+	for _, r := range s {
+		if _, exists := n.next(r); !exists && !create {
+			return nil
+		}
+		n = t.child(n, r)
+		path = append(path, n)
+	}
+	return path
+}
+
+func (t *trie) prune(s string, path []*node) {
+	runes := []rune(s)
+	for i := len(path) - 1; i > 0; i-- {
+		n := path[i]
+		if len(n.children) > 0 || len(n.values) > 0 {
+			return
+		}
+		path[i-1].removeChild(runes[i-1])
+	}
+}
+
+func (t *trie) match(n *node, query []rune, i, edits int, out map[*node]int) {
+	if i == len(query) {
+		if prev, seen := out[n]; !seen || edits < prev {
+			out[n] = edits
+		}
+		return
+	}
+
+	if child, exists := n.next(query[i]); exists {
+		t.match(child, query, i+1, edits, out)
+	}
+	if edits == 0 {
+		return
+	}
+
+	t.match(n, query, i+1, edits-1, out)
+	for _, e := range n.children {
+		r, child := e.r, e.node
+		if r != query[i] {
+			t.match(child, query, i+1, edits-1, out)
+		}
+		t.match(child, query, i, edits-1, out)
+		if i+1 < len(query) && r == query[i+1] {
+			if swapped, exists := child.next(query[i]); exists {
+				t.match(swapped, query, i+2, edits-1, out)
+			}
+		}
+	}
+}
+
+func (n *node) offer(e entry) {
+	for i, existing := range n.top {
+		if existing.key == e.key {
+			n.top = append(n.top[:i], n.top[i+1:]...)
+			break
+		}
+	}
+	pos := sort.Search(len(n.top), func(i int) bool { return ranksBefore(e, n.top[i]) })
+	if pos >= MaxLimit {
+		return
+	}
+	n.top = append(n.top, entry{})
+	copy(n.top[pos+1:], n.top[pos:])
+	n.top[pos] = e
+	if len(n.top) > MaxLimit {
+		n.top = n.top[:MaxLimit]
+	}
+}
+
+func (n *node) rebuild() {
+	candidates := make(map[string]entry)
+	for key, v := range n.values {
+		candidates[key] = v.entry(key)
+	}
+	for _, c := range n.children {
+		for _, e := range c.node.top {
+			if existing, seen := candidates[e.key]; !seen || ranksBefore(e, existing) {
+				candidates[e.key] = e
+			}
+		}
+	}
+
+	n.top = n.top[:0]
+	for _, e := range candidates {
+		n.top = append(n.top, e)
+	}
+	sort.Slice(n.top, func(i, j int) bool { return ranksBefore(n.top[i], n.top[j]) })
+	if len(n.top) > MaxLimit {
+		n.top = n.top[:MaxLimit]
+	}
+}
+
+func ranksBefore(a, b entry) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	return a.key < b.key
+}
+
+func wordSuffixes(key string) []string {
+	if key == "" {
+		return nil
+	}
+	suffixes := []string{key}
+	for i := 0; i < len(key); i++ {
+		if key[i] == ' ' && i+1 < len(key) {
+			suffixes = append(suffixes, key[i+1:])
+		}
+	}
+	return suffixes
+}
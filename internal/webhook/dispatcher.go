@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"solid/internal/domain"
+)
+
+type Config struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	BaseBackoff    time.Duration
+	MaxBackoff     time.Duration
+	RequestTimeout time.Duration
+	// Retention is how long delivered and dead deliveries are kept after
+	// their last attempt; PruneInterval is how often they are dropped.
+	Retention     time.Duration
+	PruneInterval time.Duration
+	// AttemptLog caps the attempts kept per delivery. It never drops
+	// attempts that still count towards MaxAttempts.
+	AttemptLog int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:   time.Second,
+		BatchSize:      50,
+		MaxAttempts:    6,
+		BaseBackoff:    2 * time.Second,
+		MaxBackoff:     10 * time.Minute,
+		RequestTimeout: 10 * time.Second,
+		Retention:      7 * 24 * time.Hour,
+		PruneInterval:  time.Hour,
+		AttemptLog:     20,
+	}
+}
+
+type Dispatcher struct {
+	repository domain.WebhookRepository
+	client     *http.Client
+	config     Config
+	now        func() time.Time
+}
+
+func NewDispatcher(repository domain.WebhookRepository, config Config) *Dispatcher {
+	return &Dispatcher{
+		repository: repository,
+		client:     &http.Client{Timeout: config.RequestTimeout},
+		config:     config,
+		now:        time.Now,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	prune := time.NewTicker(d.config.PruneInterval)
+	defer prune.Stop()
+
+	for {
+		if err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhook dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-prune.C:
+			d.prune(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) prune(ctx context.Context) {
+	pruned, err := d.repository.PruneDeliveries(ctx, d.now().Add(-d.config.Retention))
+	if err != nil {
+		log.Printf("webhook delivery pruning failed: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("pruned %d webhook deliveries", pruned)
+	}
+}
+
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	deliveries, err := d.repository.FindDueDeliveries(ctx, d.now(), d.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		sub, err := d.repository.FindSubscription(ctx, delivery.SubscriptionID)
+		if err != nil {
+			continue
+		}
+
+		if !sub.Active {
+			// Park it without spending an attempt, so it neither fills
+			// every batch nor dies while the subscription is paused.
+			delivery.NextAttemptAt = d.now().Add(d.config.MaxBackoff)
+		} else {
+			d.deliver(ctx, sub, delivery)
+		}
+
+		if err := d.repository.SaveDelivery(ctx, delivery); err != nil {
+			log.Printf("failed to save webhook delivery %s: %v", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, sub *domain.Subscription, delivery *domain.Delivery) {
+	attempt := d.attempt(ctx, sub, delivery)
+	delivery.Attempts = append(delivery.Attempts, attempt)
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = domain.DeliveryDelivered
+	case d.exhausted(delivery):
+		delivery.Status = domain.DeliveryDead
+	default:
+		delivery.NextAttemptAt = d.now().Add(d.backoff(d.failures(delivery)))
+	}
+	delivery.TrimAttempts(max(d.config.AttemptLog, d.config.MaxAttempts))
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, sub *domain.Subscription, delivery *domain.Delivery) domain.DeliveryAttempt {
+	start := d.now()
+	attempt := domain.DeliveryAttempt{At: start}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	// Signed with the wall clock, which is what the receiver checks it
+	// against, rather than the scheduling clock.
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, delivery.Payload))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+
+	resp, err := d.client.Do(req)
+	attempt.Duration = time.Since(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("receiver responded with status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+func (d *Dispatcher) failures(delivery *domain.Delivery) int {
+	failures := 0
+	for i := len(delivery.Attempts) - 1; i >= delivery.RetryFrom && delivery.Attempts[i].Error != ""; i-- {
+		failures++
+	}
+	return failures
+}
+
+func (d *Dispatcher) exhausted(delivery *domain.Delivery) bool {
+	return d.failures(delivery) >= d.config.MaxAttempts
+}
+
+func (d *Dispatcher) backoff(failures int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return delay
+}
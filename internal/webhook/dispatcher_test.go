@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"solid/internal/domain"
+	"solid/internal/repository"
+	"solid/internal/service"
+	"solid/pkg/mocks"
+)
+
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	received []map[string]interface{}
+	invalid  int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if !Verify(rc.secret, body, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), time.Now()) {
+		rc.invalid++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var payload map[string]interface{}
+	json.Unmarshal(body, &payload)
+	rc.received = append(rc.received, payload)
+	w.WriteHeader(rc.status)
+}
+
+func setup(t *testing.T, rc *receiver, events []string) (*service.WebhookService, *Dispatcher, *domain.Subscription) {
+	t.Helper()
+
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	repo := repository.NewInMemoryWebhookRepository()
+	webhooks := service.NewWebhookService(repo)
+	sub, err := webhooks.CreateSubscription(context.Background(), server.URL, events, rc.secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.MaxAttempts = 2
+	return webhooks, NewDispatcher(repo, config), sub
+}
+
+func TestDispatcher_DispatchDue(t *testing.T) {
+	ctx := context.Background()
+	book := &domain.Book{ID: "book-1", Title: "Clean Code"}
+	created, _ := domain.NewOutboxMessage(domain.EventBookCreated, book)
+	deleted, _ := domain.NewOutboxMessage(domain.EventBookDeleted, book)
+
+	t.Run("delivers signed payloads for matching events", func(t *testing.T) {
+		rc := &receiver{secret: "s3cret", status: http.StatusOK}
+		webhooks, dispatcher, sub := setup(t, rc, []string{domain.EventBookCreated})
+
+		webhooks.Deliver(ctx, created)
+		webhooks.Deliver(ctx, deleted)
+		err := dispatcher.DispatchDue(ctx)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rc.invalid != 0 || len(rc.received) != 1 {
+			t.Fatalf("expected 1 valid delivery, got %d (%d invalid)", len(rc.received), rc.invalid)
+		}
+		if rc.received[0]["event"] != domain.EventBookCreated {
+			t.Errorf("unexpected payload %+v", rc.received[0])
+		}
+		deliveries, _ := webhooks.ListDeliveries(ctx, sub.ID)
+		if len(deliveries) != 1 || deliveries[0].Status != domain.DeliveryDelivered || deliveries[0].Attempts[0].StatusCode != http.StatusOK {
+			t.Errorf("unexpected delivery log %+v", deliveries)
+		}
+	})
+
+	t.Run("retries then dead letters", func(t *testing.T) {
+		rc := &receiver{secret: "s3cret", status: http.StatusInternalServerError}
+		webhooks, dispatcher, sub := setup(t, rc, []string{"*"})
+		webhooks.Deliver(ctx, created)
+		now := time.Now()
+		dispatcher.now = func() time.Time { return now }
+		dispatcher.DispatchDue(ctx)
+		dispatcher.DispatchDue(ctx)
+
+		if len(rc.received) != 1 {
+			t.Fatalf("expected backoff to delay the retry, got %d attempts", len(rc.received))
+		}
+
+		dispatcher.now = func() time.Time { return now.Add(time.Hour) }
+		dispatcher.DispatchDue(ctx)
+
+		dead, _ := webhooks.ListDeadLetters(ctx)
+		if len(dead) != 1 || len(dead[0].Attempts) != 2 {
+			t.Fatalf("expected 1 dead letter after 2 attempts, got %+v", dead)
+		}
+
+		rc.status = http.StatusOK
+		if _, err := webhooks.Redeliver(ctx, sub.ID, dead[0].ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		dispatcher.DispatchDue(ctx)
+
+		delivery, _ := webhooks.ListDeliveries(ctx, sub.ID)
+		if delivery[0].Status != domain.DeliveryDelivered {
+			t.Errorf("expected redelivery to succeed, got %s", delivery[0].Status)
+		}
+	})
+
+	t.Run("redelivery resets the retry budget", func(t *testing.T) {
+		rc := &receiver{secret: "s3cret", status: http.StatusInternalServerError}
+		webhooks, dispatcher, sub := setup(t, rc, []string{"*"})
+		webhooks.Deliver(ctx, created)
+		now := time.Now()
+		for i := 0; i < 2; i++ {
+			dispatcher.now = func() time.Time { return now.Add(time.Duration(i) * time.Hour) }
+			dispatcher.DispatchDue(ctx)
+		}
+		dead, _ := webhooks.ListDeadLetters(ctx)
+		if len(dead) != 1 {
+			t.Fatalf("expected a dead letter, got %+v", dead)
+		}
+
+		webhooks.Redeliver(ctx, sub.ID, dead[0].ID)
+		dispatcher.now = func() time.Time { return now.Add(2 * time.Hour) }
+		dispatcher.DispatchDue(ctx)
+
+		delivery, _ := webhooks.ListDeliveries(ctx, sub.ID)
+		if delivery[0].Status != domain.DeliveryPending || len(delivery[0].Attempts) != 3 {
+			t.Errorf("expected a failed redelivery to be retried, got %s after %d attempts", delivery[0].Status, len(delivery[0].Attempts))
+		}
+	})
+
+	t.Run("keeps going when a delivery cannot be saved", func(t *testing.T) {
+		rc := &receiver{secret: "s3cret", status: http.StatusOK}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+		sub, _ := domain.NewSubscription(server.URL, []string{"*"}, rc.secret)
+		first, _ := domain.NewDelivery(sub.ID, created)
+		second, _ := domain.NewDelivery(sub.ID, deleted)
+		var saved []string
+		repo := &mocks.WebhookRepository{
+			FindDueDeliveriesFunc: func(ctx context.Context, now time.Time, limit int) ([]*domain.Delivery, error) {
+				return []*domain.Delivery{first, second}, nil
+			},
+			FindSubscriptionFunc: func(ctx context.Context, id string) (*domain.Subscription, error) {
+				return sub, nil
+			},
+			SaveDeliveryFunc: func(ctx context.Context, delivery *domain.Delivery) error {
+				if delivery.ID == first.ID {
+					return errors.New("disk full")
+				}
+				saved = append(saved, delivery.ID)
+				return nil
+			},
+		}
+
+		err := NewDispatcher(repo, DefaultConfig()).DispatchDue(ctx)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rc.received) != 2 || len(saved) != 1 || saved[0] != second.ID {
+			t.Errorf("expected both sent and the second saved, got %d sent, saved %v", len(rc.received), saved)
+		}
+	})
+
+	t.Run("parks deliveries of subscriptions paused after queueing", func(t *testing.T) {
+		rc := &receiver{secret: "s3cret", status: http.StatusOK}
+		webhooks, dispatcher, sub := setup(t, rc, []string{"*"})
+		webhooks.Deliver(ctx, created)
+		inactive := false
+		webhooks.UpdateSubscription(ctx, sub.ID, "", nil, &inactive)
+
+		dispatcher.DispatchDue(ctx)
+
+		deliveries, _ := webhooks.ListDeliveries(ctx, sub.ID)
+		if len(rc.received) != 0 || len(deliveries[0].Attempts) != 0 || deliveries[0].Status != domain.DeliveryPending {
+			t.Fatalf("expected an untouched pending delivery, got %d sent, %+v", len(rc.received), deliveries[0])
+		}
+		if !deliveries[0].NextAttemptAt.After(time.Now()) {
+			t.Errorf("expected the delivery to be postponed, got %v", deliveries[0].NextAttemptAt)
+		}
+
+		active := true
+		webhooks.UpdateSubscription(ctx, sub.ID, "", nil, &active)
+		dispatcher.now = func() time.Time { return time.Now().Add(time.Hour) }
+		dispatcher.DispatchDue(ctx)
+
+		if len(rc.received) != 1 {
+			t.Errorf("expected the delivery once the subscription is active again, got %d", len(rc.received))
+		}
+	})
+
+	t.Run("inactive subscriptions receive nothing", func(t *testing.T) {
+		rc := &receiver{secret: "s3cret", status: http.StatusOK}
+		webhooks, dispatcher, sub := setup(t, rc, []string{"*"})
+		inactive := false
+		webhooks.UpdateSubscription(ctx, sub.ID, "", nil, &inactive)
+
+		webhooks.Deliver(ctx, created)
+		dispatcher.DispatchDue(ctx)
+
+		if len(rc.received) != 0 {
+			t.Errorf("expected no deliveries, got %d", len(rc.received))
+		}
+	})
+}
+
+func TestDispatcher_prune(t *testing.T) {
+	ctx := context.Background()
+	rc := &receiver{secret: "s3cret", status: http.StatusOK}
+	webhooks, dispatcher, sub := setup(t, rc, []string{"*"})
+	book := &domain.Book{ID: "book-1", Title: "Clean Code"}
+	created, _ := domain.NewOutboxMessage(domain.EventBookCreated, book)
+	deleted, _ := domain.NewOutboxMessage(domain.EventBookDeleted, book)
+	webhooks.Deliver(ctx, created)
+	dispatcher.DispatchDue(ctx)
+	webhooks.Deliver(ctx, deleted)
+
+	dispatcher.now = func() time.Time { return time.Now().Add(dispatcher.config.Retention + time.Minute) }
+	dispatcher.prune(ctx)
+
+	deliveries, _ := webhooks.ListDeliveries(ctx, sub.ID)
+	if len(deliveries) != 1 || deliveries[0].EventType != domain.EventBookDeleted {
+		t.Errorf("expected only the pending delivery to be kept, got %+v", deliveries)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"book.created"}`)
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("s3cret", now.Unix(), body)
+
+	if !Verify("s3cret", body, timestamp, signature, now) {
+		t.Error("expected signature to verify")
+	}
+	if Verify("other", body, timestamp, signature, now) {
+		t.Error("expected signature with wrong secret to fail")
+	}
+	if Verify("s3cret", []byte(`{}`), timestamp, signature, now) {
+		t.Error("expected signature over different body to fail")
+	}
+	if Verify("s3cret", body, strconv.FormatInt(now.Unix()+1, 10), signature, now) {
+		t.Error("expected signature with a different timestamp to fail")
+	}
+	if Verify("s3cret", body, timestamp, signature, now.Add(SignatureTolerance+time.Second)) {
+		t.Error("expected a stale signature to fail")
+	}
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	// SignatureTolerance is how far a signed timestamp may be from the
+	// receiver's clock before Verify rejects it as a replay.
+	SignatureTolerance = 5 * time.Minute
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature of body sent at timestamp (Unix seconds): the
+// HMAC-SHA256 of "<timestamp>.<body>" under secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches body and the timestamp header
+// value, and whether that timestamp is within SignatureTolerance of now.
+func Verify(secret string, body []byte, timestamp, signature string, now time.Time) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, sent, body)), []byte(signature))
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Book is a book as returned by the /v1 API.
+type Book struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Author    string     `json:"author"`
+	ISBN      string     `json:"isbn"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type CreateBookInput struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	ISBN   string `json:"isbn"`
+	Force  bool   `json:"-"`
+}
+
+type UpdateBookInput struct {
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+	ISBN   string `json:"isbn,omitempty"`
+}
+
+type ListOptions struct {
+	PageSize int
+	After    string
+	// Filter is a filter expression such as "author eq 'Robert Martin'".
+	Filter string
+}
+
+type BookPage struct {
+	Books      []*Book
+	NextCursor string
+}
+
+// CreateBook sends one Idempotency-Key for all attempts, so a retry after a
+// lost response returns the original book instead of creating a second one.
+func (c *Client) CreateBook(ctx context.Context, input CreateBookInput) (*Book, error) {
+	path := "/v1/books"
+	if input.Force {
+		path += "?force=true"
+	}
+
+	var book Book
+	_, err := c.do(ctx, request{
+		method:         http.MethodPost,
+		path:           path,
+		body:           input,
+		idempotencyKey: uuid.New().String(),
+	}, &book)
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (c *Client) GetBook(ctx context.Context, id string) (*Book, error) {
+	var book Book
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/books/" + url.PathEscape(id)}, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// UpdateBook sends one Idempotency-Key for all attempts, so a retry after a
+// lost response does not apply the update twice.
+func (c *Client) UpdateBook(ctx context.Context, id string, input UpdateBookInput) (*Book, error) {
+	var book Book
+	_, err := c.do(ctx, request{
+		method:         http.MethodPut,
+		path:           "/v1/books/" + url.PathEscape(id),
+		body:           input,
+		idempotencyKey: uuid.New().String(),
+	}, &book)
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (c *Client) DeleteBook(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/v1/books/" + url.PathEscape(id)}, nil)
+	return err
+}
+
+func (c *Client) ListBooksPage(ctx context.Context, opts ListOptions) (*BookPage, error) {
+	query := url.Values{}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	query.Set("limit", strconv.Itoa(pageSize))
+	if opts.After != "" {
+		query.Set("after", opts.After)
+	}
+	if opts.Filter != "" {
+		query.Set("filter", opts.Filter)
+	}
+
+	var books []*Book
+	header, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/books?" + query.Encode()}, &books)
+	if err != nil {
+		return nil, err
+	}
+	return &BookPage{Books: books, NextCursor: nextCursor(strings.Join(header.Values("Link"), ","))}, nil
+}
+
+// ListBooks iterates over every book, fetching pages lazily. Iteration stops
+// after the first error, which is yielded with a nil book.
+func (c *Client) ListBooks(ctx context.Context, opts ListOptions) iter.Seq2[*Book, error] {
+	return func(yield func(*Book, error) bool) {
+		for {
+			page, err := c.ListBooksPage(ctx, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, book := range page.Books {
+				if !yield(book, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			opts.After = page.NextCursor
+		}
+	}
+}
+
+func nextCursor(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, found := strings.Cut(strings.TrimSpace(part), ";")
+		if !found || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		next, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return ""
+		}
+		return next.Query().Get("after")
+	}
+	return ""
+}
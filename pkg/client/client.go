@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	}
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      RetryPolicy
+	actor      string
+	token      string
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithActor sets the X-Actor header recorded in the book history.
+func WithActor(actor string) Option {
+	return func(c *Client) {
+		c.actor = actor
+	}
+}
+
+// WithToken sends the token as a bearer credential, for deployments that
+// put the API behind an authenticating proxy.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retry:      DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type request struct {
+	method         string
+	path           string
+	body           interface{}
+	idempotencyKey string
+}
+
+// do sends the request, retrying 5xx and 429 responses and transport errors
+// with exponential backoff. The body is encoded once so every attempt sends
+// the same bytes and, for POST and PUT, the same Idempotency-Key.
+func (c *Client) do(ctx context.Context, req request, out interface{}) (http.Header, error) {
+	var payload []byte
+	if req.body != nil {
+		encoded, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, err
+		}
+		payload = encoded
+	}
+
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt, lastErr)); err != nil {
+				return nil, err
+			}
+		}
+
+		header, err := c.send(ctx, req, payload, out)
+		if err == nil {
+			return header, nil
+		}
+		if !retryable(err) || ctx.Err() != nil {
+			return nil, unwrapResponse(err)
+		}
+		lastErr = err
+	}
+	return nil, unwrapResponse(lastErr)
+}
+
+func (c *Client) send(ctx context.Context, req request, payload []byte, out interface{}) (http.Header, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
+	if c.actor != "" {
+		httpReq.Header.Set("X-Actor", c.actor)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &transportError{err: err}
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, &responseError{
+			err:        decodeError(resp.StatusCode, body),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return nil, err
+		}
+	}
+	return resp.Header, nil
+}
+
+func (c *Client) backoff(attempt int, lastErr error) time.Duration {
+	if respErr, ok := lastErr.(*responseError); ok && respErr.retryAfter > 0 {
+		return respErr.retryAfter
+	}
+
+	delay := c.retry.InitialBackoff << (attempt - 1)
+	if delay <= 0 || (c.retry.MaxBackoff > 0 && delay > c.retry.MaxBackoff) {
+		delay = c.retry.MaxBackoff
+	}
+	return delay
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+type responseError struct {
+	err        *Error
+	retryAfter time.Duration
+}
+
+func (e *responseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *responseError) Unwrap() error {
+	return e.err
+}
+
+func unwrapResponse(err error) error {
+	if respErr, ok := err.(*responseError); ok {
+		return respErr.err
+	}
+	return err
+}
+
+func retryable(err error) bool {
+	switch e := err.(type) {
+	case *transportError:
+		return true
+	case *responseError:
+		return e.err.StatusCode >= http.StatusInternalServerError || e.err.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solid/internal/router/routertest"
+	"solid/pkg/client"
+)
+
+var fastRetry = client.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+
+	var h http.Handler = routertest.New(t)
+	if wrap != nil {
+		h = wrap(h)
+	}
+
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// failFirst answers the first n requests with status without reaching next.
+func failFirst(n int32, status int, calls *int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(calls, 1) <= n {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				w.Write([]byte(`{"error":"try again","code":"UNAVAILABLE"}`))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func expectCode(t *testing.T, err error, code string, status int) *client.Error {
+	t.Helper()
+
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	if apiErr.Code != code || apiErr.StatusCode != status {
+		t.Fatalf("expected %s (%d), got %s (%d)", code, status, apiErr.Code, apiErr.StatusCode)
+	}
+	return apiErr
+}
+
+func TestClient_Books(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t, nil)
+	c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+
+	created, err := c.CreateBook(ctx, client.CreateBookInput{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID == "" || created.Title != "Clean Code" {
+		t.Fatalf("unexpected book %+v", created)
+	}
+
+	fetched, err := c.GetBook(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched.ISBN != "0132350882" {
+		t.Errorf("expected isbn 0132350882, got %s", fetched.ISBN)
+	}
+
+	updated, err := c.UpdateBook(ctx, created.ID, client.UpdateBookInput{Title: "Clean Code, 2nd Edition"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Title != "Clean Code, 2nd Edition" || updated.Author != "Robert Martin" {
+		t.Errorf("unexpected book %+v", updated)
+	}
+
+	if err := c.DeleteBook(ctx, created.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = c.GetBook(ctx, created.ID)
+	expectCode(t, err, "BOOK_NOT_FOUND", http.StatusNotFound)
+}
+
+func TestClient_ListBooks(t *testing.T) {
+	ctx := context.Background()
+	server := newServer(t, nil)
+	c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+
+	isbns := []string{"0132350882", "0201485672", "0137081073", "0134494164", "0321125215"}
+	for i, isbn := range isbns {
+		_, err := c.CreateBook(ctx, client.CreateBookInput{Title: "Volume " + strconv.Itoa(i), Author: "Author " + strconv.Itoa(i), ISBN: isbn, Force: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var titles []string
+	for book, err := range c.ListBooks(ctx, client.ListOptions{PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		titles = append(titles, book.Title)
+	}
+	if len(titles) != len(isbns) {
+		t.Fatalf("expected %d books, got %v", len(isbns), titles)
+	}
+	for i, title := range titles {
+		if title != "Volume "+strconv.Itoa(i) {
+			t.Errorf("expected creation order, got %v", titles)
+			break
+		}
+	}
+
+	page, err := c.ListBooksPage(ctx, client.ListOptions{PageSize: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Books) != 3 || page.NextCursor == "" {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	for _, err := range c.ListBooks(ctx, client.ListOptions{After: "bogus"}) {
+		expectCode(t, err, "INVALID_INPUT", http.StatusBadRequest)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("decodes details", func(t *testing.T) {
+		server := newServer(t, nil)
+		c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+		c.CreateBook(ctx, client.CreateBookInput{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+
+		_, err := c.CreateBook(ctx, client.CreateBookInput{Title: "Clean Code", Author: "Robert Martin", ISBN: "9780132350884"})
+
+		apiErr := expectCode(t, err, client.CodePossibleDuplicate, http.StatusConflict)
+		var candidates []map[string]interface{}
+		if err := json.Unmarshal(apiErr.Details, &candidates); err != nil || len(candidates) != 1 {
+			t.Errorf("expected one candidate, got %s", apiErr.Details)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		var calls int32
+		server := newServer(t, failFirst(0, 0, &calls))
+		c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+
+		_, err := c.CreateBook(ctx, client.CreateBookInput{Title: "Clean Code"})
+
+		expectCode(t, err, "INVALID_INPUT", http.StatusBadRequest)
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("non-JSON error body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		c := client.New(server.URL, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))
+
+		_, err := c.GetBook(ctx, "id")
+
+		expectCode(t, err, "HTTP_500", http.StatusInternalServerError)
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	ctx := context.Background()
+
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		t.Run("retries "+strconv.Itoa(status), func(t *testing.T) {
+			var calls int32
+			server := newServer(t, failFirst(2, status, &calls))
+			c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+
+			_, err := c.CreateBook(ctx, client.CreateBookInput{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls != 3 {
+				t.Errorf("expected 3 calls, got %d", calls)
+			}
+		})
+	}
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls int32
+		server := newServer(t, failFirst(10, http.StatusBadGateway, &calls))
+		c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+
+		_, err := c.GetBook(ctx, "id")
+
+		expectCode(t, err, "UNAVAILABLE", http.StatusBadGateway)
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("create is not duplicated when the response is lost", func(t *testing.T) {
+		var calls int32
+		server := newServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost && atomic.AddInt32(&calls, 1) == 1 {
+					next.ServeHTTP(httptest.NewRecorder(), r)
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+		c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+
+		book, err := c.CreateBook(ctx, client.CreateBookInput{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		count := 0
+		for listed, err := range c.ListBooks(ctx, client.ListOptions{}) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if listed.ID != book.ID {
+				t.Errorf("unexpected book %+v", listed)
+			}
+			count++
+		}
+		if count != 1 {
+			t.Errorf("expected 1 book, got %d", count)
+		}
+	})
+
+	t.Run("update is not replayed when the response is lost", func(t *testing.T) {
+		var calls int32
+		var keys []string
+		var replayed string
+		server := newServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					next.ServeHTTP(w, r)
+					return
+				}
+				keys = append(keys, r.Header.Get("Idempotency-Key"))
+				if atomic.AddInt32(&calls, 1) == 1 {
+					next.ServeHTTP(httptest.NewRecorder(), r)
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				rec := httptest.NewRecorder()
+				next.ServeHTTP(rec, r)
+				replayed = rec.Header().Get("Idempotency-Replayed")
+				for key, values := range rec.Header() {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(rec.Code)
+				w.Write(rec.Body.Bytes())
+			})
+		})
+		c := client.New(server.URL, client.WithRetryPolicy(fastRetry))
+		created, _ := c.CreateBook(ctx, client.CreateBookInput{Title: "Clean Code", Author: "Robert Martin", ISBN: "0132350882"})
+
+		updated, err := c.UpdateBook(ctx, created.ID, client.UpdateBookInput{Title: "Clean Code, 2nd Edition"})
+
+		if err != nil || updated.Title != "Clean Code, 2nd Edition" {
+			t.Fatalf("unexpected result %+v (%v)", updated, err)
+		}
+		if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+			t.Errorf("expected the same Idempotency-Key on both attempts, got %q", keys)
+		}
+		if replayed != "true" {
+			t.Errorf("expected the retry to be replayed, got %q", replayed)
+		}
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		var calls int32
+		server := newServer(t, failFirst(10, http.StatusServiceUnavailable, &calls))
+		c := client.New(server.URL, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Minute}))
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.GetBook(ctx, "id")
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Error codes returned by the API that callers commonly branch on. The full
+// list is the ErrorCode enum of the OpenAPI document.
+const (
+	CodeBookNotFound      = "BOOK_NOT_FOUND"
+	CodeBookAlreadyExists = "BOOK_ALREADY_EXISTS"
+	CodeInvalidInput      = "INVALID_INPUT"
+	CodePossibleDuplicate = "POSSIBLE_DUPLICATE"
+)
+
+// Error is an error response from the API. Use errors.As to inspect it.
+type Error struct {
+	Code       string
+	Message    string
+	StatusCode int
+	// Details holds the raw "details" member of the response, if any.
+	Details json.RawMessage
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// decodeError turns an error response into an *Error. Bodies that are not
+// API errors get an HTTP_<status> code.
+func decodeError(status int, body []byte) *Error {
+	var payload struct {
+		Error   string          `json:"error"`
+		Code    string          `json:"code"`
+		Details json.RawMessage `json:"details"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Error == "" {
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		return &Error{Code: "HTTP_" + strconv.Itoa(status), Message: message, StatusCode: status}
+	}
+
+	apiErr := &Error{Code: payload.Code, Message: payload.Error, StatusCode: status}
+	if len(payload.Details) > 0 && string(payload.Details) != "null" {
+		apiErr.Details = payload.Details
+	}
+	return apiErr
+}
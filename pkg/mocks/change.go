@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"context"
+	"solid/internal/domain"
+)
+
+type ChangeLog struct {
+	ChangesFunc        func(ctx context.Context, since uint64, limit int) ([]*domain.Change, error)
+	LatestSequenceFunc func(ctx context.Context) (uint64, error)
+}
+
+func (m *ChangeLog) Changes(ctx context.Context, since uint64, limit int) ([]*domain.Change, error) {
+	if m.ChangesFunc != nil {
+		return m.ChangesFunc(ctx, since, limit)
+	}
+	return []*domain.Change{}, nil
+}
+
+func (m *ChangeLog) LatestSequence(ctx context.Context) (uint64, error) {
+	if m.LatestSequenceFunc != nil {
+		return m.LatestSequenceFunc(ctx)
+	}
+	return 0, nil
+}
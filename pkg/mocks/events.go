@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+	"solid/internal/domain"
+	"sync"
+	"testing"
+)
+
+type EventRecorder struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (r *EventRecorder) Publish(ctx context.Context, event domain.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *EventRecorder) Events() []domain.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]domain.Event, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+func (r *EventRecorder) Names() []string {
+	events := r.Events()
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, e.EventName())
+	}
+	return names
+}
+
+func (r *EventRecorder) AssertEmitted(t testing.TB, names ...string) {
+	t.Helper()
+
+	got := r.Names()
+	if len(got) != len(names) {
+		t.Fatalf("expected events %v, got %v", names, got)
+	}
+	for i := range names {
+		if got[i] != names[i] {
+			t.Fatalf("expected events %v, got %v", names, got)
+		}
+	}
+}
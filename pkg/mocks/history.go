@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+	"solid/internal/domain"
+)
+
+type HistoryRepository struct {
+	AppendFunc      func(ctx context.Context, entry *domain.AuditEntry) error
+	FindByBookFunc  func(ctx context.Context, bookID string) ([]*domain.AuditEntry, error)
+	FindVersionFunc func(ctx context.Context, bookID string, version int) (*domain.AuditEntry, error)
+}
+
+func (m *HistoryRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	if m.AppendFunc != nil {
+		return m.AppendFunc(ctx, entry)
+	}
+	return nil
+}
+
+func (m *HistoryRepository) FindByBook(ctx context.Context, bookID string) ([]*domain.AuditEntry, error) {
+	if m.FindByBookFunc != nil {
+		return m.FindByBookFunc(ctx, bookID)
+	}
+	return nil, domain.ErrBookNotFound
+}
+
+func (m *HistoryRepository) FindVersion(ctx context.Context, bookID string, version int) (*domain.AuditEntry, error) {
+	if m.FindVersionFunc != nil {
+		return m.FindVersionFunc(ctx, bookID, version)
+	}
+	return nil, domain.ErrVersionNotFound
+}
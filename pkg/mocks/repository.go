@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"solid/internal/domain"
+	"time"
 )
 
 type BookRepository struct {
@@ -12,6 +13,14 @@ type BookRepository struct {
 	FindAllFunc   func(ctx context.Context) ([]*domain.Book, error)
 	UpdateFunc    func(ctx context.Context, book *domain.Book) error
 	DeleteFunc    func(ctx context.Context, id string) error
+
+	FindDeletedFunc func(ctx context.Context) ([]*domain.Book, error)
+	RestoreFunc     func(ctx context.Context, id string) error
+	PurgeFunc       func(ctx context.Context, deletedBefore time.Time) (int, error)
+
+	SaveMergeFunc          func(ctx context.Context, merge *domain.Merge) error
+	FindMergeFunc          func(ctx context.Context, sourceID string) (*domain.Merge, error)
+	FindMergesByTargetFunc func(ctx context.Context, targetID string) ([]*domain.Merge, error)
 }
 
 func (m *BookRepository) Create(ctx context.Context, book *domain.Book) error {
@@ -55,3 +64,45 @@ func (m *BookRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (m *BookRepository) SaveMerge(ctx context.Context, merge *domain.Merge) error {
+	if m.SaveMergeFunc != nil {
+		return m.SaveMergeFunc(ctx, merge)
+	}
+	return nil
+}
+
+func (m *BookRepository) FindMerge(ctx context.Context, sourceID string) (*domain.Merge, error) {
+	if m.FindMergeFunc != nil {
+		return m.FindMergeFunc(ctx, sourceID)
+	}
+	return nil, domain.ErrBookNotFound
+}
+
+func (m *BookRepository) FindMergesByTarget(ctx context.Context, targetID string) ([]*domain.Merge, error) {
+	if m.FindMergesByTargetFunc != nil {
+		return m.FindMergesByTargetFunc(ctx, targetID)
+	}
+	return []*domain.Merge{}, nil
+}
+
+func (m *BookRepository) FindDeleted(ctx context.Context) ([]*domain.Book, error) {
+	if m.FindDeletedFunc != nil {
+		return m.FindDeletedFunc(ctx)
+	}
+	return []*domain.Book{}, nil
+}
+
+func (m *BookRepository) Restore(ctx context.Context, id string) error {
+	if m.RestoreFunc != nil {
+		return m.RestoreFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *BookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if m.PurgeFunc != nil {
+		return m.PurgeFunc(ctx, deletedBefore)
+	}
+	return 0, nil
+}
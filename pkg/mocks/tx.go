@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+)
+
+type TransactionalBookRepository struct {
+	BookRepository
+	Commits   int
+	Rollbacks int
+}
+
+func (m *TransactionalBookRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		m.Rollbacks++
+		return err
+	}
+	m.Commits++
+	return nil
+}
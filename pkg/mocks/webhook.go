@@ -0,0 +1,99 @@
+package mocks
+
+import (
+	"context"
+	"solid/internal/domain"
+	"time"
+)
+
+type WebhookRepository struct {
+	CreateSubscriptionFunc func(ctx context.Context, sub *domain.Subscription) error
+	FindSubscriptionFunc   func(ctx context.Context, id string) (*domain.Subscription, error)
+	FindSubscriptionsFunc  func(ctx context.Context) ([]*domain.Subscription, error)
+	UpdateSubscriptionFunc func(ctx context.Context, sub *domain.Subscription) error
+	DeleteSubscriptionFunc func(ctx context.Context, id string) error
+
+	SaveDeliveryFunc       func(ctx context.Context, delivery *domain.Delivery) error
+	FindDeliveryFunc       func(ctx context.Context, id string) (*domain.Delivery, error)
+	FindDeliveriesFunc     func(ctx context.Context, subscriptionID string) ([]*domain.Delivery, error)
+	FindDueDeliveriesFunc  func(ctx context.Context, now time.Time, limit int) ([]*domain.Delivery, error)
+	FindDeadDeliveriesFunc func(ctx context.Context) ([]*domain.Delivery, error)
+	PruneDeliveriesFunc    func(ctx context.Context, finishedBefore time.Time) (int, error)
+}
+
+func (m *WebhookRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	if m.CreateSubscriptionFunc != nil {
+		return m.CreateSubscriptionFunc(ctx, sub)
+	}
+	return nil
+}
+
+func (m *WebhookRepository) FindSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	if m.FindSubscriptionFunc != nil {
+		return m.FindSubscriptionFunc(ctx, id)
+	}
+	return nil, domain.ErrSubscriptionNotFound
+}
+
+func (m *WebhookRepository) FindSubscriptions(ctx context.Context) ([]*domain.Subscription, error) {
+	if m.FindSubscriptionsFunc != nil {
+		return m.FindSubscriptionsFunc(ctx)
+	}
+	return []*domain.Subscription{}, nil
+}
+
+func (m *WebhookRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	if m.UpdateSubscriptionFunc != nil {
+		return m.UpdateSubscriptionFunc(ctx, sub)
+	}
+	return nil
+}
+
+func (m *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	if m.DeleteSubscriptionFunc != nil {
+		return m.DeleteSubscriptionFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *WebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.Delivery) error {
+	if m.SaveDeliveryFunc != nil {
+		return m.SaveDeliveryFunc(ctx, delivery)
+	}
+	return nil
+}
+
+func (m *WebhookRepository) FindDelivery(ctx context.Context, id string) (*domain.Delivery, error) {
+	if m.FindDeliveryFunc != nil {
+		return m.FindDeliveryFunc(ctx, id)
+	}
+	return nil, domain.ErrDeliveryNotFound
+}
+
+func (m *WebhookRepository) FindDeliveries(ctx context.Context, subscriptionID string) ([]*domain.Delivery, error) {
+	if m.FindDeliveriesFunc != nil {
+		return m.FindDeliveriesFunc(ctx, subscriptionID)
+	}
+	return []*domain.Delivery{}, nil
+}
+
+func (m *WebhookRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.Delivery, error) {
+	if m.FindDueDeliveriesFunc != nil {
+		return m.FindDueDeliveriesFunc(ctx, now, limit)
+	}
+	return []*domain.Delivery{}, nil
+}
+
+func (m *WebhookRepository) FindDeadDeliveries(ctx context.Context) ([]*domain.Delivery, error) {
+	if m.FindDeadDeliveriesFunc != nil {
+		return m.FindDeadDeliveriesFunc(ctx)
+	}
+	return []*domain.Delivery{}, nil
+}
+
+func (m *WebhookRepository) PruneDeliveries(ctx context.Context, finishedBefore time.Time) (int, error) {
+	if m.PruneDeliveriesFunc != nil {
+		return m.PruneDeliveriesFunc(ctx, finishedBefore)
+	}
+	return 0, nil
+}